@@ -1,9 +1,11 @@
 package main
 
 import (
-	"biblia-am-pm/internal/database"
+	"biblia-am-pm/internal/config"
 	"biblia-am-pm/internal/models"
 	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/storage"
+	"context"
 	"encoding/json"
 	"flag"
 	"io"
@@ -22,91 +24,111 @@ type OnlineCatechismItem struct {
 	Answer   string `json:"answer"`   // Formato alternativo
 }
 
+// bundledFiles são os arquivos de cada catecismo distribuídos com o comando
+var bundledFiles = map[string]string{
+	models.CatechismShorter: "catechism.json",
+	models.CatechismLarger:  "catechism_maior.json",
+}
+
 func main() {
-	var clearFlag = flag.Bool("clear", false, "Clear existing catechism questions before populating")
-	var urlFlag = flag.String("url", "", "Custom URL to fetch catechism from (optional)")
+	ctx := context.Background()
+
+	var clearFlag = flag.Bool("clear", false, "Clear the existing questions of the catechism before populating")
+	var catechismFlag = flag.String("catechism", "", "Catechism to populate: shorter or larger (default: both bundled catechisms)")
+	var urlFlag = flag.String("url", "", "Custom URL to fetch the catechism from (optional, requires -catechism)")
 	flag.Parse()
 
+	cfg, err := config.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	catechisms := []string{models.CatechismShorter, models.CatechismLarger}
+	if *catechismFlag != "" {
+		if !models.IsValidCatechism(*catechismFlag) {
+			log.Fatalf("Invalid -catechism %q: use shorter or larger", *catechismFlag)
+		}
+		catechisms = []string{*catechismFlag}
+	} else if *urlFlag != "" {
+		log.Fatalf("-url requires -catechism, the catechism of the questions fetched")
+	}
+
 	// Initialize database
-	if err := database.InitDB(); err != nil {
+	store, err := storage.Open(cfg.Database)
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer database.CloseDB()
+	defer store.Close()
 
-	repo := repository.NewCatechismRepository()
+	repo := store.Repos.Catechism
 
-	// Clear existing questions if flag is set
-	if *clearFlag {
-		log.Println("Clearing existing catechism questions...")
-		_, err := database.DB.Exec("DELETE FROM westminster_catechism")
-		if err != nil {
-			log.Fatalf("Failed to clear catechism: %v", err)
+	for _, catechism := range catechisms {
+		var body []byte
+		if *urlFlag != "" {
+			body = fetchCatechism(*urlFlag)
+		} else {
+			body = readBundledFile(bundledFiles[catechism])
 		}
-		log.Println("Existing questions cleared.")
-	}
 
-	// Tentar primeiro arquivo local, depois URL online
-	var body []byte
+		// Clear existing questions if flag is set
+		if *clearFlag {
+			log.Printf("Clearing existing questions of the %s catechism...", catechism)
+			_, err := store.DB.Exec("DELETE FROM westminster_catechism WHERE catechism = $1", catechism)
+			if err != nil {
+				log.Fatalf("Failed to clear catechism: %v", err)
+			}
+		}
 
-	// Tentar múltiplos caminhos possíveis (dependendo de onde o comando é executado)
-	// Prioriza Catecismo Maior, depois Menor
+		populateQuestions(ctx, repo, catechism, body)
+		populateSections(ctx, store.Repos.CatechismSections, catechism)
+	}
+}
+
+// readBundledFile lê um arquivo distribuído com o comando, procurando nos
+// caminhos possíveis conforme o diretório de onde o comando é executado
+func readBundledFile(name string) []byte {
 	possiblePaths := []string{
-		"catechism_maior.json",                             // Catecismo Maior - quando executado de dentro de cmd/populate-catechism
-		"cmd/populate-catechism/catechism_maior.json",      // Catecismo Maior - quando executado da raiz do backend
-		"/app/cmd/populate-catechism/catechism_maior.json", // Catecismo Maior - caminho absoluto no container
-		"catechism.json",                                    // Catecismo Menor (fallback)
-		"cmd/populate-catechism/catechism.json",             // Catecismo Menor (fallback)
-		"/app/cmd/populate-catechism/catechism.json",        // Catecismo Menor (fallback)
+		name,                                  // quando executado de dentro de cmd/populate-catechism
+		"cmd/populate-catechism/" + name,      // quando executado da raiz do backend
+		"/app/cmd/populate-catechism/" + name, // caminho absoluto no container
 	}
 
-	var localFile string
-	var found bool
 	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			localFile = path
-			found = true
-			break
+		body, err := os.ReadFile(path)
+		if err == nil {
+			log.Printf("Reading catechism from local file: %s", path)
+			return body
 		}
 	}
 
-	// Debug: mostrar diretório atual
-	if wd, err := os.Getwd(); err == nil {
-		log.Printf("Current working directory: %s", wd)
-	}
-
-	if found {
-		log.Printf("Reading catechism from local file: %s", localFile)
-		var err error
-		body, err = os.ReadFile(localFile)
-		if err != nil {
-			log.Fatalf("Failed to read local file: %v", err)
-		}
-	} else {
-		// Tentar buscar online
-		catechismURL := *urlFlag
-		if catechismURL == "" {
-			catechismURL = "https://raw.githubusercontent.com/ReformedWiki/westminster-shorter-catechism/master/data/catechism.json"
-		}
+	wd, _ := os.Getwd()
+	log.Fatalf("%s not found (current working directory: %s)", name, wd)
+	return nil
+}
 
-		log.Printf("Fetching catechism from: %s", catechismURL)
-		resp, err := http.Get(catechismURL)
-		if err != nil {
-			log.Fatalf("Failed to fetch catechism: %v", err)
-		}
-		defer resp.Body.Close()
+func fetchCatechism(url string) []byte {
+	log.Printf("Fetching catechism from: %s", url)
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatalf("Failed to fetch catechism: %v", err)
+	}
+	defer resp.Body.Close()
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			log.Fatalf("Failed to fetch catechism (HTTP %d): %s", resp.StatusCode, string(body))
-		}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		log.Fatalf("Failed to fetch catechism (HTTP %d): %s", resp.StatusCode, string(body))
+	}
 
-		body, err = io.ReadAll(resp.Body)
-		if err != nil {
-			log.Fatalf("Failed to read response: %v", err)
-		}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("Failed to read response: %v", err)
 	}
+	return body
+}
 
-	log.Println("Populating Westminster Catechism...")
+// populateQuestions salva as perguntas do JSON no catecismo indicado
+func populateQuestions(ctx context.Context, repo repository.CatechismRepository, catechism string, body []byte) {
+	log.Printf("Populating Westminster Catechism (%s)...", catechism)
 
 	// Parse JSON
 	var items []OnlineCatechismItem
@@ -134,11 +156,12 @@ func main() {
 			if answerText == "" {
 				answerText = item.Answer
 			}
-			
+
 			questions = append(questions, &models.CatechismQuestion{
 				QuestionNumber: item.Number,
 				QuestionText:   strings.TrimSpace(questionText),
 				AnswerText:     strings.TrimSpace(answerText),
+				Catechism:      catechism,
 			})
 			validCount++
 			if item.Number > maxQuestion {
@@ -155,7 +178,7 @@ func main() {
 
 	// Salvar no banco de dados
 	for i, question := range questions {
-		if err := repo.Create(question); err != nil {
+		if err := repo.Create(ctx, question); err != nil {
 			log.Printf("Failed to save question %d: %v", question.QuestionNumber, err)
 			continue
 		}
@@ -168,3 +191,43 @@ func main() {
 	log.Printf("✅ Successfully populated %d questions!", validCount)
 	log.Printf("✅ Questions range from 1 to %d", maxQuestion)
 }
+
+// populateSections salva o índice de seções temáticas do catecismo a partir de sections.json
+func populateSections(ctx context.Context, sectionRepo repository.CatechismSectionRepository, catechism string) {
+	possiblePaths := []string{
+		"sections.json",
+		"cmd/populate-catechism/sections.json",
+		"/app/cmd/populate-catechism/sections.json",
+	}
+
+	var body []byte
+	for _, path := range possiblePaths {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			body = data
+			break
+		}
+	}
+
+	if body == nil {
+		log.Printf("⚠️  sections.json not found, skipping sections index")
+		return
+	}
+
+	var sectionsByCatechism map[string][]*models.CatechismSection
+	if err := json.Unmarshal(body, &sectionsByCatechism); err != nil {
+		log.Fatalf("Failed to parse sections: %v", err)
+	}
+
+	sections := sectionsByCatechism[catechism]
+	if len(sections) == 0 {
+		log.Printf("⚠️  No sections defined for catechism %s", catechism)
+		return
+	}
+
+	if err := sectionRepo.ReplaceForCatechism(ctx, catechism, sections); err != nil {
+		log.Fatalf("Failed to save sections: %v", err)
+	}
+
+	log.Printf("✅ Saved %d sections for catechism %s", len(sections), catechism)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/storage"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// confessionChapterItem estrutura de um capítulo em confession.json
+type confessionChapterItem struct {
+	Chapter  int                     `json:"chapter"`
+	Title    string                  `json:"title"`
+	Sections []confessionSectionItem `json:"sections"`
+}
+
+type confessionSectionItem struct {
+	Section int                   `json:"section"`
+	Text    string                `json:"text"`
+	Proofs  []confessionProofItem `json:"proofs"`
+}
+
+type confessionProofItem struct {
+	Letter     string `json:"letter"`
+	References string `json:"references"`
+}
+
+// findFile retorna o primeiro caminho existente (dependendo de onde o comando é executado)
+func findFile(name string) (string, bool) {
+	possiblePaths := []string{
+		name,
+		"cmd/populate-confession/" + name,
+		"/app/cmd/populate-confession/" + name,
+	}
+	for _, path := range possiblePaths {
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func main() {
+	ctx := context.Background()
+
+	var fileFlag = flag.String("file", "", "Path to the confession JSON file (default: the bundled confession.json)")
+	var urlFlag = flag.String("url", "", "URL to fetch the confession JSON from instead of the local file")
+	var linksFlag = flag.Bool("links-only", false, "Only import the catechism links from catechism_links.json")
+	flag.Parse()
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Initialize database
+	store, err := storage.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer store.Close()
+
+	repo := store.Repos.Confession
+
+	if !*linksFlag {
+		body := readConfession(*fileFlag, *urlFlag)
+
+		chapters, err := parseConfession(body)
+		if err != nil {
+			log.Fatalf("Invalid confession file: %v", err)
+		}
+
+		sectionCount := 0
+		for _, chapter := range chapters {
+			sectionCount += len(chapter.Sections)
+		}
+
+		log.Printf("Found %d chapters and %d sections, saving to database...", len(chapters), sectionCount)
+		if err := repo.ReplaceAll(ctx, chapters); err != nil {
+			log.Fatalf("Failed to save confession: %v", err)
+		}
+		log.Printf("✅ Successfully populated %d chapters!", len(chapters))
+	}
+
+	populateLinks(ctx, repo)
+}
+
+// readConfession busca a URL informada ou, sem ela, lê o arquivo local, por
+// padrão o confession.json distribuído com o comando
+func readConfession(file, url string) []byte {
+	if url == "" {
+		path, found := file, file != ""
+		if !found {
+			path, found = findFile("confession.json")
+		}
+		if !found {
+			log.Fatalf("confession.json not found: run the command from cmd/populate-confession or use -file or -url")
+		}
+
+		log.Printf("Reading confession from local file: %s", path)
+		body, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read local file: %v", err)
+		}
+		return body
+	}
+
+	log.Printf("Fetching confession from: %s", url)
+	resp, err := http.Get(url)
+	if err != nil {
+		log.Fatalf("Failed to fetch confession: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		log.Fatalf("Failed to fetch confession (HTTP %d): %s", resp.StatusCode, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("Failed to read response: %v", err)
+	}
+	return body
+}
+
+// parseConfession converte o JSON para o nosso modelo, validando a numeração
+func parseConfession(body []byte) ([]*models.ConfessionChapter, error) {
+	var items []confessionChapterItem
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no chapters found")
+	}
+
+	chapters := make([]*models.ConfessionChapter, 0, len(items))
+	seenChapters := make(map[int]bool)
+	for _, item := range items {
+		if item.Chapter < 1 || seenChapters[item.Chapter] {
+			return nil, fmt.Errorf("invalid or duplicated chapter number %d", item.Chapter)
+		}
+		seenChapters[item.Chapter] = true
+
+		chapter := &models.ConfessionChapter{
+			ChapterNumber: item.Chapter,
+			Title:         strings.TrimSpace(item.Title),
+		}
+		if chapter.Title == "" {
+			return nil, fmt.Errorf("chapter %d has no title", item.Chapter)
+		}
+
+		seenSections := make(map[int]bool)
+		for _, sectionItem := range item.Sections {
+			if sectionItem.Section < 1 || seenSections[sectionItem.Section] {
+				return nil, fmt.Errorf("chapter %d: invalid or duplicated section number %d", item.Chapter, sectionItem.Section)
+			}
+			seenSections[sectionItem.Section] = true
+
+			section := &models.ConfessionSection{
+				SectionNumber: sectionItem.Section,
+				Text:          strings.TrimSpace(sectionItem.Text),
+			}
+			if section.Text == "" {
+				return nil, fmt.Errorf("chapter %d, section %d has no text", item.Chapter, sectionItem.Section)
+			}
+
+			for _, proof := range sectionItem.Proofs {
+				references := strings.TrimSpace(proof.References)
+				if references == "" {
+					continue
+				}
+				section.Proofs = append(section.Proofs, &models.ConfessionProof{
+					Letter:     strings.TrimSpace(proof.Letter),
+					References: references,
+				})
+			}
+			chapter.Sections = append(chapter.Sections, section)
+		}
+
+		sort.Slice(chapter.Sections, func(i, j int) bool {
+			return chapter.Sections[i].SectionNumber < chapter.Sections[j].SectionNumber
+		})
+		chapters = append(chapters, chapter)
+	}
+
+	sort.Slice(chapters, func(i, j int) bool {
+		return chapters[i].ChapterNumber < chapters[j].ChapterNumber
+	})
+	return chapters, nil
+}
+
+// populateLinks salva as ligações entre as perguntas do catecismo e as seções
+// da Confissão definidas em catechism_links.json, no formato
+// {"shorter": {"2": ["1.1", "1.2"]}}
+func populateLinks(ctx context.Context, repo repository.ConfessionRepository) {
+	path, found := findFile("catechism_links.json")
+	if !found {
+		log.Printf("⚠️  catechism_links.json not found, skipping catechism links")
+		return
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read catechism links: %v", err)
+	}
+
+	var linksByCatechism map[string]map[string][]string
+	if err := json.Unmarshal(body, &linksByCatechism); err != nil {
+		log.Fatalf("Failed to parse catechism links: %v", err)
+	}
+
+	for catechism, questions := range linksByCatechism {
+		if !models.IsValidCatechism(catechism) {
+			log.Fatalf("Invalid catechism %q in catechism links", catechism)
+		}
+
+		var links []*models.CatechismConfessionLink
+		for number, references := range questions {
+			questionNumber, err := strconv.Atoi(number)
+			if err != nil || questionNumber < 1 {
+				log.Fatalf("Invalid question number %q in catechism links", number)
+			}
+
+			for _, reference := range references {
+				chapterNumber, sectionNumber, err := parseSectionReference(reference)
+				if err != nil {
+					log.Fatalf("Question %d: %v", questionNumber, err)
+				}
+				links = append(links, &models.CatechismConfessionLink{
+					Catechism:      catechism,
+					QuestionNumber: questionNumber,
+					ChapterNumber:  chapterNumber,
+					SectionNumber:  sectionNumber,
+				})
+			}
+		}
+
+		if err := repo.ReplaceLinks(ctx, catechism, links); err != nil {
+			log.Fatalf("Failed to save catechism links: %v", err)
+		}
+		log.Printf("✅ Saved %d links for catechism %s", len(links), catechism)
+	}
+}
+
+// parseSectionReference converte "21.3" em capítulo 21, seção 3
+func parseSectionReference(reference string) (int, int, error) {
+	parts := strings.Split(reference, ".")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid section reference %q: use chapter.section", reference)
+	}
+	chapterNumber, err := strconv.Atoi(parts[0])
+	if err != nil || chapterNumber < 1 {
+		return 0, 0, fmt.Errorf("invalid chapter in reference %q", reference)
+	}
+	sectionNumber, err := strconv.Atoi(parts[1])
+	if err != nil || sectionNumber < 1 {
+		return 0, 0, fmt.Errorf("invalid section in reference %q", reference)
+	}
+	return chapterNumber, sectionNumber, nil
+}
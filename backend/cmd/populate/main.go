@@ -1,9 +1,10 @@
 package main
 
 import (
-	"biblia-am-pm/internal/database"
+	"biblia-am-pm/internal/config"
 	"biblia-am-pm/internal/models"
-	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/storage"
+	"context"
 	"flag"
 	"log"
 	"strings"
@@ -131,21 +132,29 @@ func mapRMMToHybrid(rmmDay RMMDay) (oldTestamentRef, psalmsRef, newTestamentRef,
 }
 
 func main() {
+	ctx := context.Background()
+
 	var clearFlag = flag.Bool("clear", false, "Clear existing reading plans before populating")
 	flag.Parse()
 
+	cfg, err := config.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
 	// Initialize database
-	if err := database.InitDB(); err != nil {
+	store, err := storage.Open(cfg.Database)
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
-	defer database.CloseDB()
+	defer store.Close()
 
-	repo := repository.NewReadingPlanRepository()
+	repo := store.Repos.ReadingPlans
 
 	// Clear existing plans if flag is set
 	if *clearFlag {
 		log.Println("Clearing existing reading plans...")
-		_, err := database.DB.Exec("DELETE FROM reading_plans")
+		_, err := store.DB.Exec("DELETE FROM reading_plans")
 		if err != nil {
 			log.Fatalf("Failed to clear reading plans: %v", err)
 		}
@@ -175,7 +184,7 @@ func main() {
 			ProverbsRef:     proverbsRef,
 		}
 
-		if err := repo.Create(plan); err != nil {
+		if err := repo.Create(ctx, plan); err != nil {
 			log.Printf("Failed to create plan for day %d: %v", day, err)
 			// Continue with next day
 		}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/printsheet"
+	"biblia-am-pm/internal/storage"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var quarterPattern = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+
+// parseQuarter converte "2025-Q1" no primeiro e no último dia do trimestre
+func parseQuarter(value string, loc *time.Location) (time.Time, time.Time, error) {
+	match := quarterPattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid quarter %q: use YYYY-QN, e.g. 2025-Q1", value)
+	}
+	year, _ := strconv.Atoi(match[1])
+	quarter, _ := strconv.Atoi(match[2])
+
+	first := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, loc)
+	last := first.AddDate(0, 3, -1)
+	return first, last, nil
+}
+
+func currentQuarter(now time.Time) string {
+	return fmt.Sprintf("%d-Q%d", now.Year(), (int(now.Month())-1)/3+1)
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Mesmo fuso horário da API, para que as semanas coincidam
+	loc := cfg.Location()
+	now := time.Now().In(loc)
+
+	var quarterFlag = flag.String("quarter", currentQuarter(now), "Quarter to print, as YYYY-QN")
+	var startFlag = flag.String("start", "", "Print from the week containing this date (YYYY-MM-DD) instead of a quarter")
+	var weeksFlag = flag.Int("weeks", 13, "Number of weeks to print when -start is set")
+	var outFlag = flag.String("out", "", "Output PDF file (default: semanas-<first week>.pdf)")
+	var catechismFlag = flag.String("catechism", models.CatechismShorter, "Catechism of the questions: shorter or larger")
+	flag.Parse()
+
+	if !models.IsValidCatechism(*catechismFlag) {
+		log.Fatalf("Invalid -catechism %q: use shorter or larger", *catechismFlag)
+	}
+
+	// Uma data de cada semana (domingo a sábado) do período pedido
+	var weekDates []time.Time
+	if *startFlag != "" {
+		start, err := time.ParseInLocation("2006-01-02", *startFlag, loc)
+		if err != nil {
+			log.Fatalf("Invalid -start date: %v", err)
+		}
+		if *weeksFlag < 1 {
+			log.Fatalf("-weeks must be at least 1")
+		}
+		for i := 0; i < *weeksFlag; i++ {
+			weekDates = append(weekDates, start.AddDate(0, 0, 7*i))
+		}
+	} else {
+		first, last, err := parseQuarter(*quarterFlag, loc)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 7) {
+			weekDates = append(weekDates, day)
+		}
+		// A última semana pode começar antes do fim do trimestre sem ter sido incluída
+		if int(last.Weekday()) < int(first.Weekday()) {
+			weekDates = append(weekDates, last)
+		}
+	}
+
+	// Initialize database
+	store, err := storage.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer store.Close()
+
+	catechismRepo := store.Repos.Catechism
+	readingPlanRepo := store.Repos.ReadingPlans
+
+	weeks := make([]*printsheet.Week, 0, len(weekDates))
+	for _, date := range weekDates {
+		week, err := printsheet.LoadWeek(ctx, date, *catechismFlag, catechismRepo, readingPlanRepo)
+		if err != nil {
+			log.Fatalf("Failed to load week of %s: %v", date.Format("2006-01-02"), err)
+		}
+		weeks = append(weeks, week)
+	}
+
+	out := *outFlag
+	if out == "" {
+		out = fmt.Sprintf("semanas-%s.pdf", weeks[0].Start.Format("2006-01-02"))
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", out, err)
+	}
+	defer file.Close()
+
+	if err := printsheet.Render(file, weeks); err != nil {
+		log.Fatalf("Failed to generate PDF: %v", err)
+	}
+
+	log.Printf("✅ Printed %d weeks (%s to %s) to %s", len(weeks),
+		weeks[0].Start.Format("02/01/2006"), weeks[len(weeks)-1].Start.AddDate(0, 0, 6).Format("02/01/2006"), out)
+}
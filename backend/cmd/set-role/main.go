@@ -0,0 +1,50 @@
+package main
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/storage"
+	"context"
+	"flag"
+	"log"
+)
+
+func main() {
+	ctx := context.Background()
+
+	var emailFlag = flag.String("email", "", "Email of the user whose role will be changed")
+	var roleFlag = flag.String("role", models.RoleAdmin, "Role to assign (user or admin)")
+	flag.Parse()
+
+	if *emailFlag == "" {
+		log.Fatalf("The -email flag is required")
+	}
+
+	if *roleFlag != models.RoleUser && *roleFlag != models.RoleAdmin {
+		log.Fatalf("Invalid role %q: use %q or %q", *roleFlag, models.RoleUser, models.RoleAdmin)
+	}
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Initialize database
+	store, err := storage.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer store.Close()
+
+	repo := store.Repos.Users
+	updated, err := repo.SetRole(ctx, *emailFlag, *roleFlag)
+	if err != nil {
+		log.Fatalf("Failed to set role: %v", err)
+	}
+
+	if !updated {
+		log.Fatalf("User %s not found", *emailFlag)
+	}
+
+	log.Printf("✅ User %s now has role %q", *emailFlag, *roleFlag)
+}
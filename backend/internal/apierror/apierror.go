@@ -0,0 +1,153 @@
+// Package apierror defines the errors of the API: each has a stable code the
+// clients can branch on, an HTTP status and a message in Portuguese and in
+// English. They are written as RFC 7807 problem details
+// (application/problem+json), in the locale of the user or, failing that, of
+// the Accept-Language header.
+package apierror
+
+import (
+	"biblia-am-pm/internal/logging"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of the error responses
+const ContentType = "application/problem+json"
+
+// requestIDKey is where middleware.RequestID stores the request ID. It is
+// repeated here because the middleware package writes its errors with this one.
+const requestIDKey = "requestID"
+
+// Error is an API error. Params fill the placeholders of the message of Code.
+type Error struct {
+	Code   Code
+	Params []interface{}
+	// Extensions are extra members of the problem, such as the list of
+	// validation problems
+	Extensions map[string]interface{}
+}
+
+// New returns the error with code, its message filled with params
+func New(code Code, params ...interface{}) *Error {
+	return &Error{Code: code, Params: params}
+}
+
+// With adds the member key to the problem
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = make(map[string]interface{})
+	}
+	e.Extensions[key] = value
+	return e
+}
+
+// Status is the HTTP status of the error
+func (e *Error) Status() int {
+	if entry, ok := catalog[e.Code]; ok {
+		return entry.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Message is the description of the error in locale
+func (e *Error) Message(locale Locale) string {
+	entry, ok := catalog[e.Code]
+	if !ok {
+		entry = catalog[CodeInternal]
+	}
+	message, ok := entry.messages[locale]
+	if !ok {
+		message = entry.messages[DefaultLocale]
+	}
+	if len(e.Params) > 0 {
+		return fmt.Sprintf(message, e.Params...)
+	}
+	return message
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message(English))
+}
+
+// Problem is an RFC 7807 problem details object. Type is always about:blank,
+// so Title is the HTTP status text; Code identifies the error and Detail
+// explains it in the negotiated language.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail"`
+	Code      Code   `json:"code"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+
+	// Extensions are written as members of the problem
+	Extensions map[string]interface{} `json:"-"`
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal((*problem)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions))
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// Problem returns the problem details of the error in locale
+func (e *Error) Problem(locale Locale) *Problem {
+	status := e.Status()
+	return &Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     e.Message(locale),
+		Code:       e.Code,
+		Extensions: e.Extensions,
+	}
+}
+
+// Abort stops the request and answers with the problem details of err
+func Abort(c *gin.Context, err *Error) {
+	locale := RequestLocale(c)
+	problem := err.Problem(locale)
+	problem.Instance = c.Request.URL.Path
+	problem.RequestID = c.GetString(requestIDKey)
+
+	c.Header("Content-Type", ContentType)
+	c.Header("Content-Language", string(locale))
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// Internal logs cause, which is not shown to the client, with the request
+// logger and aborts with INTERNAL_ERROR
+func Internal(c *gin.Context, message string, cause error) {
+	logging.FromContext(c.Request.Context()).Error(message, "error", cause)
+	if cause != nil {
+		_ = c.Error(cause)
+	}
+	Abort(c, New(CodeInternal))
+}
+
+// NoRoute answers the requests that match no route
+func NoRoute(c *gin.Context) {
+	Abort(c, New(CodeRouteNotFound))
+}
+
+// Recovery answers a panic with INTERNAL_ERROR. It is meant for
+// gin.CustomRecovery, which logs the panic.
+func Recovery(c *gin.Context, recovered interface{}) {
+	Abort(c, New(CodeInternal))
+}
@@ -0,0 +1,387 @@
+package apierror
+
+import "net/http"
+
+// Code identifies an error. Codes are part of the API: clients branch on them,
+// so they are never renamed or reused.
+type Code string
+
+// General
+const (
+	CodeInternal           Code = "INTERNAL_ERROR"
+	CodeRouteNotFound      Code = "ROUTE_NOT_FOUND"
+	CodeInvalidRequestBody Code = "INVALID_REQUEST_BODY"
+	CodeInvalidDate        Code = "INVALID_DATE"
+	CodeInvalidLocale      Code = "INVALID_LOCALE"
+)
+
+// Authentication and authorization
+const (
+	CodeUnauthorized        Code = "UNAUTHORIZED"
+	CodeAuthHeaderMissing   Code = "AUTH_HEADER_MISSING"
+	CodeAuthHeaderInvalid   Code = "AUTH_HEADER_INVALID"
+	CodeTokenInvalid        Code = "TOKEN_INVALID"
+	CodeUserNotFound        Code = "USER_NOT_FOUND"
+	CodeForbidden           Code = "FORBIDDEN"
+	CodeCredentialsRequired Code = "CREDENTIALS_REQUIRED"
+	CodeInvalidCredentials  Code = "INVALID_CREDENTIALS"
+	CodeUserAlreadyExists   Code = "USER_ALREADY_EXISTS"
+)
+
+// Idempotency keys
+const (
+	CodeInvalidIdempotencyKey     Code = "INVALID_IDEMPOTENCY_KEY"
+	CodeIdempotencyKeyReused      Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInUse       Code = "IDEMPOTENCY_KEY_IN_USE"
+	CodeIdempotentRequestTooLarge Code = "IDEMPOTENT_REQUEST_TOO_LARGE"
+)
+
+// Readings
+const (
+	CodePlanNotFound  Code = "PLAN_NOT_FOUND"
+	CodeInvalidPeriod Code = "INVALID_PERIOD"
+)
+
+// Catechism
+const (
+	CodeCatechismNotPopulated Code = "CATECHISM_NOT_POPULATED"
+	CodeUnknownCatechism      Code = "UNKNOWN_CATECHISM"
+	CodeInvalidCatechismMode  Code = "INVALID_CATECHISM_MODE"
+	CodeInvalidStep           Code = "INVALID_STEP"
+	CodeInvalidQuestionNumber Code = "INVALID_QUESTION_NUMBER"
+	CodeQuestionNotFound      Code = "QUESTION_NOT_FOUND"
+	CodeSectionNotFound       Code = "SECTION_NOT_FOUND"
+	CodeInvalidPage           Code = "INVALID_PAGE"
+	CodeInvalidPerPage        Code = "INVALID_PER_PAGE"
+	CodeSearchQueryTooShort   Code = "SEARCH_QUERY_TOO_SHORT"
+	CodeInvalidLimit          Code = "INVALID_LIMIT"
+	CodeInvalidQuizCount      Code = "INVALID_QUIZ_COUNT"
+	CodeInvalidRange          Code = "INVALID_RANGE"
+	CodeNoQuestionsInRange    Code = "NO_QUESTIONS_IN_RANGE"
+	CodeInvalidQuizID         Code = "INVALID_QUIZ_ID"
+	CodeQuizNotFound          Code = "QUIZ_NOT_FOUND"
+	CodeQuizAlreadySubmitted  Code = "QUIZ_ALREADY_SUBMITTED"
+	CodeInvalidExercise       Code = "INVALID_EXERCISE"
+	CodeQuestionTextRequired  Code = "QUESTION_TEXT_REQUIRED"
+	CodeInvalidRevision       Code = "INVALID_REVISION"
+	CodeRevisionNotFound      Code = "REVISION_NOT_FOUND"
+	CodeCatechismFileRequired Code = "CATECHISM_FILE_REQUIRED"
+	CodeCatechismFileTooLarge Code = "CATECHISM_FILE_TOO_LARGE"
+	CodeUnknownFileFormat     Code = "UNKNOWN_FILE_FORMAT"
+	CodeFileUnreadable        Code = "FILE_UNREADABLE"
+	CodeCatechismParseFailed  Code = "CATECHISM_PARSE_FAILED"
+	CodeCatechismInvalid      Code = "CATECHISM_INVALID"
+)
+
+// Sync
+const (
+	CodeInvalidSyncCursor      Code = "INVALID_SYNC_CURSOR"
+	CodeTooManySyncEvents      Code = "TOO_MANY_SYNC_EVENTS"
+	CodeInvalidSyncEventType   Code = "INVALID_SYNC_EVENT_TYPE"
+	CodeInvalidSyncEventAction Code = "INVALID_SYNC_EVENT_ACTION"
+	CodeInvalidRecordedAt      Code = "INVALID_RECORDED_AT"
+	CodePlanNotFoundForDate    Code = "PLAN_NOT_FOUND_FOR_DATE"
+)
+
+// Confession of faith
+const (
+	CodeInvalidChapterNumber Code = "INVALID_CHAPTER_NUMBER"
+	CodeChapterNotFound      Code = "CHAPTER_NOT_FOUND"
+)
+
+// Notes
+const (
+	CodeInvalidNoteID         Code = "INVALID_NOTE_ID"
+	CodeNoteNotFound          Code = "NOTE_NOT_FOUND"
+	CodeInvalidNoteBody       Code = "INVALID_NOTE_BODY"
+	CodeInvalidNoteTags       Code = "INVALID_NOTE_TAGS"
+	CodeInvalidBibleReference Code = "INVALID_BIBLE_REFERENCE"
+	CodeUnknownBook           Code = "UNKNOWN_BOOK"
+	CodeChapterRequiresBook   Code = "CHAPTER_REQUIRES_BOOK"
+)
+
+// Households
+const (
+	CodeHouseholdNotFound  Code = "HOUSEHOLD_NOT_FOUND"
+	CodeAlreadyInHousehold Code = "ALREADY_IN_HOUSEHOLD"
+	CodeInviteCodeNotFound Code = "INVITE_CODE_NOT_FOUND"
+)
+
+type entry struct {
+	status   int
+	messages map[Locale]string
+}
+
+// catalog holds the status and the messages of each code. The messages are
+// fmt formats, filled with the Params of the error in the same order in every
+// language.
+var catalog = map[Code]entry{
+	CodeInternal: {http.StatusInternalServerError, map[Locale]string{
+		PortugueseBR: "Erro interno do servidor. Tente novamente mais tarde.",
+		English:      "Internal server error. Please try again later.",
+	}},
+	CodeRouteNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Rota não encontrada",
+		English:      "Route not found",
+	}},
+	CodeInvalidRequestBody: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Corpo da requisição inválido",
+		English:      "Invalid request body",
+	}},
+	CodeInvalidDate: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Data inválida. Use AAAA-MM-DD",
+		English:      "Invalid date format. Use YYYY-MM-DD",
+	}},
+	CodeInvalidLocale: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Idioma inválido. Use \"pt-BR\" ou \"en\"",
+		English:      "Invalid locale. Use \"pt-BR\" or \"en\"",
+	}},
+
+	CodeUnauthorized: {http.StatusUnauthorized, map[Locale]string{
+		PortugueseBR: "Não autenticado",
+		English:      "Unauthorized",
+	}},
+	CodeAuthHeaderMissing: {http.StatusUnauthorized, map[Locale]string{
+		PortugueseBR: "O cabeçalho Authorization é obrigatório",
+		English:      "Authorization header required",
+	}},
+	CodeAuthHeaderInvalid: {http.StatusUnauthorized, map[Locale]string{
+		PortugueseBR: "Formato do cabeçalho Authorization inválido. Use \"Bearer <token>\"",
+		English:      "Invalid authorization header format. Use \"Bearer <token>\"",
+	}},
+	CodeTokenInvalid: {http.StatusUnauthorized, map[Locale]string{
+		PortugueseBR: "Token inválido ou expirado",
+		English:      "Invalid or expired token",
+	}},
+	CodeUserNotFound: {http.StatusUnauthorized, map[Locale]string{
+		PortugueseBR: "Usuário não encontrado",
+		English:      "User not found",
+	}},
+	CodeForbidden: {http.StatusForbidden, map[Locale]string{
+		PortugueseBR: "Permissão insuficiente",
+		English:      "Insufficient permissions",
+	}},
+	CodeCredentialsRequired: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "E-mail e senha são obrigatórios",
+		English:      "Email and password are required",
+	}},
+	CodeInvalidCredentials: {http.StatusUnauthorized, map[Locale]string{
+		PortugueseBR: "E-mail ou senha incorretos",
+		English:      "Invalid credentials",
+	}},
+	CodeUserAlreadyExists: {http.StatusConflict, map[Locale]string{
+		PortugueseBR: "Já existe um usuário com este e-mail",
+		English:      "User already exists",
+	}},
+
+	CodeInvalidIdempotencyKey: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "O cabeçalho Idempotency-Key deve ter de 1 a %d caracteres visíveis",
+		English:      "The Idempotency-Key header must have 1 to %d visible characters",
+	}},
+	CodeIdempotencyKeyReused: {http.StatusUnprocessableEntity, map[Locale]string{
+		PortugueseBR: "Esta Idempotency-Key já foi usada em outra requisição",
+		English:      "This Idempotency-Key was already used for another request",
+	}},
+	CodeIdempotencyKeyInUse: {http.StatusConflict, map[Locale]string{
+		PortugueseBR: "A requisição com esta Idempotency-Key ainda está em andamento. Tente novamente em instantes",
+		English:      "The request with this Idempotency-Key is still in progress. Try again shortly",
+	}},
+	CodeIdempotentRequestTooLarge: {http.StatusRequestEntityTooLarge, map[Locale]string{
+		PortugueseBR: "Requisições com Idempotency-Key podem ter no máximo %d MB",
+		English:      "Requests with an Idempotency-Key can have at most %d MB",
+	}},
+
+	CodePlanNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Não há plano de leitura para hoje",
+		English:      "Reading plan not found for today",
+	}},
+	CodeInvalidPeriod: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "O período deve ser 'morning' ou 'evening'",
+		English:      "Period must be 'morning' or 'evening'",
+	}},
+
+	CodeCatechismNotPopulated: {http.StatusServiceUnavailable, map[Locale]string{
+		PortugueseBR: "O catecismo ainda não foi carregado no banco de dados",
+		English:      "The catechism has not been populated yet",
+	}},
+	CodeUnknownCatechism: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Catecismo inválido. Use %q ou %q",
+		English:      "Invalid catechism. Use %q or %q",
+	}},
+	CodeInvalidCatechismMode: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "O modo deve ser '%s' ou '%s'",
+		English:      "Mode must be '%s' or '%s'",
+	}},
+	CodeInvalidStep: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Etapa %q inválida para o modo %s",
+		English:      "Invalid step %q for %s mode",
+	}},
+	CodeInvalidQuestionNumber: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Número de pergunta inválido",
+		English:      "Invalid question number",
+	}},
+	CodeQuestionNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Pergunta não encontrada",
+		English:      "Question not found",
+	}},
+	CodeSectionNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Seção não encontrada",
+		English:      "Section not found",
+	}},
+	CodeInvalidPage: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "A página deve ser um número positivo",
+		English:      "Page must be a positive number",
+	}},
+	CodeInvalidPerPage: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "per_page deve estar entre 1 e %d",
+		English:      "per_page must be between 1 and %d",
+	}},
+	CodeSearchQueryTooShort: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "O parâmetro 'q' deve ter pelo menos 2 caracteres",
+		English:      "Query parameter 'q' must have at least 2 characters",
+	}},
+	CodeInvalidLimit: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "O limite deve estar entre 1 e %d",
+		English:      "Limit must be between 1 and %d",
+	}},
+	CodeInvalidQuizCount: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "A quantidade deve estar entre 1 e %d",
+		English:      "Count must be between 1 and %d",
+	}},
+	CodeInvalidRange: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Intervalo inválido: %v. Use INÍCIO-FIM",
+		English:      "Invalid range: %v. Use START-END",
+	}},
+	CodeNoQuestionsInRange: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Nenhuma pergunta encontrada no intervalo pedido",
+		English:      "No questions found in the requested range",
+	}},
+	CodeInvalidQuizID: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "ID de quiz inválido",
+		English:      "Invalid quiz ID",
+	}},
+	CodeQuizNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Quiz não encontrado",
+		English:      "Quiz not found",
+	}},
+	CodeQuizAlreadySubmitted: {http.StatusConflict, map[Locale]string{
+		PortugueseBR: "Este quiz já foi enviado",
+		English:      "Quiz already submitted",
+	}},
+	CodeInvalidExercise: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Índice de exercício %d inválido",
+		English:      "Invalid exercise index %d",
+	}},
+	CodeQuestionTextRequired: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "O texto da pergunta e da resposta são obrigatórios",
+		English:      "Question text and answer text are required",
+	}},
+	CodeInvalidRevision: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Revisão %q inválida",
+		English:      "Invalid %q revision",
+	}},
+	CodeRevisionNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Revisão não encontrada",
+		English:      "Revision not found",
+	}},
+	CodeCatechismFileRequired: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Envie o arquivo do catecismo no campo \"file\"",
+		English:      "A catechism file is required in the \"file\" field",
+	}},
+	CodeCatechismFileTooLarge: {http.StatusRequestEntityTooLarge, map[Locale]string{
+		PortugueseBR: "O arquivo do catecismo é grande demais",
+		English:      "Catechism file is too large",
+	}},
+	CodeUnknownFileFormat: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Formato de arquivo desconhecido. Use um arquivo .json, .csv ou .yaml ou informe o campo \"format\"",
+		English:      "Unknown file format. Use a .json, .csv or .yaml file or set the \"format\" field",
+	}},
+	CodeFileUnreadable: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Não foi possível ler o arquivo enviado",
+		English:      "Failed to read uploaded file",
+	}},
+	CodeCatechismParseFailed: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Não foi possível interpretar o catecismo: %v",
+		English:      "Failed to parse catechism: %v",
+	}},
+	CodeCatechismInvalid: {http.StatusUnprocessableEntity, map[Locale]string{
+		PortugueseBR: "Catecismo inválido. Veja a lista de problemas",
+		English:      "Invalid catechism. See the list of problems",
+	}},
+
+	CodeInvalidSyncCursor: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Cursor de sincronização inválido. Envie o cursor da última resposta ou deixe vazio",
+		English:      "Invalid sync cursor. Send the cursor of the last response or leave it empty",
+	}},
+	CodeTooManySyncEvents: {http.StatusRequestEntityTooLarge, map[Locale]string{
+		PortugueseBR: "Envie no máximo %d eventos por sincronização",
+		English:      "Send at most %d events per sync",
+	}},
+	CodeInvalidSyncEventType: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Tipo de evento %q inválido. Use '%s' ou '%s'",
+		English:      "Invalid event type %q. Use '%s' or '%s'",
+	}},
+	CodeInvalidSyncEventAction: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Ação %q inválida. Use '%s' ou '%s'",
+		English:      "Invalid action %q. Use '%s' or '%s'",
+	}},
+	CodeInvalidRecordedAt: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "recorded_at deve ser uma data e hora RFC 3339 que não esteja no futuro",
+		English:      "recorded_at must be an RFC 3339 date and time not in the future",
+	}},
+	CodePlanNotFoundForDate: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Não há plano de leitura para %s",
+		English:      "Reading plan not found for %s",
+	}},
+
+	CodeInvalidChapterNumber: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Número de capítulo inválido",
+		English:      "Invalid chapter number",
+	}},
+	CodeChapterNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Capítulo não encontrado",
+		English:      "Chapter not found",
+	}},
+
+	CodeInvalidNoteID: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "ID de anotação inválido",
+		English:      "Invalid note ID",
+	}},
+	CodeNoteNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Anotação não encontrada",
+		English:      "Note not found",
+	}},
+	CodeInvalidNoteBody: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "O texto da anotação é obrigatório e pode ter até %d caracteres",
+		English:      "The note body is required and can have up to %d characters",
+	}},
+	CodeInvalidNoteTags: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Use até %d marcadores de até %d caracteres",
+		English:      "Use up to %d tags of up to %d characters",
+	}},
+	CodeInvalidBibleReference: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Referência bíblica %q inválida. Use, por exemplo, \"Jo 3:16-18\"",
+		English:      "Invalid Bible reference %q. Use, for example, \"Jo 3:16-18\"",
+	}},
+	CodeUnknownBook: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Livro %q desconhecido",
+		English:      "Unknown book %q",
+	}},
+	CodeChapterRequiresBook: {http.StatusBadRequest, map[Locale]string{
+		PortugueseBR: "Informe o livro junto com o capítulo",
+		English:      "The chapter must be given with a book",
+	}},
+
+	CodeHouseholdNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Você não participa de uma família",
+		English:      "You are not in a household",
+	}},
+	CodeAlreadyInHousehold: {http.StatusConflict, map[Locale]string{
+		PortugueseBR: "Você já participa de uma família. Saia dela antes de criar ou entrar em outra",
+		English:      "You are already in a household. Leave it before creating or joining another",
+	}},
+	CodeInviteCodeNotFound: {http.StatusNotFound, map[Locale]string{
+		PortugueseBR: "Nenhuma família tem este código de convite",
+		English:      "No household has this invite code",
+	}},
+}
@@ -0,0 +1,90 @@
+package apierror
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale is a language the messages are written in
+type Locale string
+
+const (
+	PortugueseBR Locale = "pt-BR"
+	English      Locale = "en"
+)
+
+// DefaultLocale is used when neither the user nor the request asks for a
+// supported language
+const DefaultLocale = PortugueseBR
+
+// LocaleKey is where the authentication middleware stores the user's locale
+const LocaleKey = "userLocale"
+
+// Locales lists the supported locales
+var Locales = []Locale{PortugueseBR, English}
+
+// ParseLocale matches a language tag ("pt", "pt-BR", "en-US"...) to a
+// supported locale by its primary language
+func ParseLocale(tag string) (Locale, bool) {
+	primary := strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(primary, "-_"); i >= 0 {
+		primary = primary[:i]
+	}
+	switch primary {
+	case "pt":
+		return PortugueseBR, true
+	case "en":
+		return English, true
+	}
+	return "", false
+}
+
+// NegotiateLocale picks the supported locale the Accept-Language header
+// prefers, or DefaultLocale
+func NegotiateLocale(acceptLanguage string) Locale {
+	type candidate struct {
+		locale  Locale
+		quality float64
+	}
+	var candidates []candidate
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		locale, ok := ParseLocale(tag)
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			candidates = append(candidates, candidate{locale: locale, quality: quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return DefaultLocale
+	}
+
+	// Stable, so the first of equally weighted languages wins
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].locale
+}
+
+// RequestLocale is the locale of the authenticated user, when they chose one,
+// or else the one negotiated from Accept-Language
+func RequestLocale(c *gin.Context) Locale {
+	if locale, ok := ParseLocale(c.GetString(LocaleKey)); ok {
+		return locale
+	}
+	return NegotiateLocale(c.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,215 @@
+// Package bibleref parses Bible references written in Portuguese, such as
+// "Jo 3:16-18", "Gn 9-10" or "1 Coríntios 13", into the book and the
+// chapters and verses they cover. Books are identified by the abbreviations
+// the reading plan uses ("Gn", "1 Co", "Fl" for Filemom).
+package bibleref
+
+import (
+	"biblia-am-pm/internal/models"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Book is a book of the Bible
+type Book struct {
+	Abbreviation string
+	Name         string
+	Chapters     int
+}
+
+// Books lists the books in canonical order
+var Books = []Book{
+	{"Gn", "Gênesis", 50},
+	{"Êx", "Êxodo", 40},
+	{"Lv", "Levítico", 27},
+	{"Nm", "Números", 36},
+	{"Dt", "Deuteronômio", 34},
+	{"Js", "Josué", 24},
+	{"Jz", "Juízes", 21},
+	{"Rt", "Rute", 4},
+	{"1 Sm", "1 Samuel", 31},
+	{"2 Sm", "2 Samuel", 24},
+	{"1 Rs", "1 Reis", 22},
+	{"2 Rs", "2 Reis", 25},
+	{"1 Cr", "1 Crônicas", 29},
+	{"2 Cr", "2 Crônicas", 36},
+	{"Ed", "Esdras", 10},
+	{"Ne", "Neemias", 13},
+	{"Et", "Ester", 10},
+	{"Jó", "Jó", 42},
+	{"Sl", "Salmos", 150},
+	{"Pv", "Provérbios", 31},
+	{"Ec", "Eclesiastes", 12},
+	{"Ct", "Cantares", 8},
+	{"Is", "Isaías", 66},
+	{"Jr", "Jeremias", 52},
+	{"Lm", "Lamentações", 5},
+	{"Ez", "Ezequiel", 48},
+	{"Dn", "Daniel", 12},
+	{"Os", "Oseias", 14},
+	{"Jl", "Joel", 3},
+	{"Am", "Amós", 9},
+	{"Ob", "Obadias", 1},
+	{"Jn", "Jonas", 4},
+	{"Mq", "Miqueias", 7},
+	{"Na", "Naum", 3},
+	{"Hc", "Habacuque", 3},
+	{"Sf", "Sofonias", 3},
+	{"Ag", "Ageu", 2},
+	{"Zc", "Zacarias", 14},
+	{"Ml", "Malaquias", 4},
+	{"Mt", "Mateus", 28},
+	{"Mc", "Marcos", 16},
+	{"Lc", "Lucas", 24},
+	{"Jo", "João", 21},
+	{"At", "Atos", 28},
+	{"Rm", "Romanos", 16},
+	{"1 Co", "1 Coríntios", 16},
+	{"2 Co", "2 Coríntios", 13},
+	{"Gl", "Gálatas", 6},
+	{"Ef", "Efésios", 6},
+	{"Fp", "Filipenses", 4},
+	{"Cl", "Colossenses", 4},
+	{"1 Ts", "1 Tessalonicenses", 5},
+	{"2 Ts", "2 Tessalonicenses", 3},
+	{"1 Tm", "1 Timóteo", 6},
+	{"2 Tm", "2 Timóteo", 4},
+	{"Tt", "Tito", 3},
+	{"Fl", "Filemom", 1},
+	{"Hb", "Hebreus", 13},
+	{"Tg", "Tiago", 5},
+	{"1 Pe", "1 Pedro", 5},
+	{"2 Pe", "2 Pedro", 3},
+	{"1 Jo", "1 João", 5},
+	{"2 Jo", "2 João", 1},
+	{"3 Jo", "3 João", 1},
+	{"Jd", "Judas", 1},
+	{"Ap", "Apocalipse", 22},
+}
+
+// Books by their compacted abbreviation and by their folded name. "Jo" is
+// João and "Jó" is Jó, so abbreviations only lose their accents when that
+// doesn't make them another book's ("Ex" is Êxodo).
+var (
+	booksByAbbreviation = make(map[string]*Book)
+	booksByName         = make(map[string]*Book)
+)
+
+func init() {
+	for i := range Books {
+		book := &Books[i]
+		booksByAbbreviation[compact(strings.ToLower(book.Abbreviation))] = book
+		booksByName[fold(book.Name)] = book
+	}
+	for i := range Books {
+		if key := fold(Books[i].Abbreviation); booksByAbbreviation[key] == nil {
+			booksByAbbreviation[key] = &Books[i]
+		}
+	}
+}
+
+// compact drops the spaces and dots of a book, so "1Co", "1 Co" and "1 Co."
+// are the same
+func compact(s string) string {
+	return strings.NewReplacer(" ", "", ".", "").Replace(s)
+}
+
+// unaccent drops the accents used in Portuguese
+var unaccent = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
+// fold lowercases s and drops its accents and spaces
+func fold(s string) string {
+	return compact(unaccent.Replace(strings.ToLower(s)))
+}
+
+// LookupBook finds a book by its abbreviation or its name, ignoring case,
+// spaces and, for names, accents
+func LookupBook(s string) *Book {
+	if book, ok := booksByAbbreviation[compact(strings.ToLower(strings.TrimSpace(s)))]; ok {
+		return book
+	}
+	return booksByName[fold(strings.TrimSpace(s))]
+}
+
+// reference matches a book followed by a chapter, optionally with verses
+// separated by ":" or ".", and optionally a range: "3", "3:16", "3:16-18",
+// "9-10" or "1:26-2:3"
+var reference = regexp.MustCompile(`^\s*((?:[123]\s*)?\pL[\pL.\s]*?)\s*(\d+)(?:[:.](\d+))?(?:\s*-\s*(\d+)(?:[:.](\d+))?)?\s*$`)
+
+// Parse parses a reference to a single passage. The chapters must exist in
+// the book; verses are only checked to be in order.
+func Parse(s string) (*models.BibleReference, error) {
+	match := reference.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("invalid reference %q", s)
+	}
+
+	book := LookupBook(match[1])
+	if book == nil {
+		return nil, fmt.Errorf("unknown book %q", strings.TrimSpace(match[1]))
+	}
+
+	ref := &models.BibleReference{Book: book.Abbreviation}
+	ref.Chapter, _ = strconv.Atoi(match[2])
+	ref.EndChapter = ref.Chapter
+	if match[3] != "" {
+		ref.Verse, _ = strconv.Atoi(match[3])
+		ref.EndVerse = ref.Verse
+		if ref.Verse < 1 {
+			return nil, fmt.Errorf("invalid verses in %q", s)
+		}
+	}
+
+	switch {
+	case match[4] == "":
+	case match[5] != "":
+		// 1:26-2:3
+		if ref.Verse == 0 {
+			return nil, fmt.Errorf("invalid reference %q", s)
+		}
+		ref.EndChapter, _ = strconv.Atoi(match[4])
+		ref.EndVerse, _ = strconv.Atoi(match[5])
+	case ref.Verse != 0:
+		// 3:16-18
+		ref.EndVerse, _ = strconv.Atoi(match[4])
+	default:
+		// 9-10
+		ref.EndChapter, _ = strconv.Atoi(match[4])
+	}
+
+	if ref.Chapter < 1 || ref.EndChapter < ref.Chapter || ref.EndChapter > book.Chapters {
+		return nil, fmt.Errorf("%s has no chapters %d to %d", book.Name, ref.Chapter, ref.EndChapter)
+	}
+	if ref.Verse != 0 && (ref.EndVerse < 1 || (ref.EndChapter == ref.Chapter && ref.EndVerse < ref.Verse)) {
+		return nil, fmt.Errorf("invalid verses in %q", s)
+	}
+
+	ref.Text = Format(ref)
+	return ref, nil
+}
+
+// Format writes a reference the way the reading plan does: "Jo 3:16-18"
+func Format(ref *models.BibleReference) string {
+	text := fmt.Sprintf("%s %d", ref.Book, ref.Chapter)
+	switch {
+	case ref.Verse == 0 && ref.EndChapter != ref.Chapter:
+		text += fmt.Sprintf("-%d", ref.EndChapter)
+	case ref.Verse == 0:
+	case ref.EndChapter != ref.Chapter:
+		text += fmt.Sprintf(":%d-%d:%d", ref.Verse, ref.EndChapter, ref.EndVerse)
+	case ref.EndVerse != ref.Verse:
+		text += fmt.Sprintf(":%d-%d", ref.Verse, ref.EndVerse)
+	default:
+		text += fmt.Sprintf(":%d", ref.Verse)
+	}
+	return text
+}
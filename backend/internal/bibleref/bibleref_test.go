@@ -0,0 +1,139 @@
+package bibleref_test
+
+import (
+	"biblia-am-pm/internal/bibleref"
+	"biblia-am-pm/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want models.BibleReference
+	}{
+		{"Jo 3:16", models.BibleReference{Text: "Jo 3:16", Book: "Jo", Chapter: 3, Verse: 16, EndChapter: 3, EndVerse: 16}},
+		{"Jo 3:16-18", models.BibleReference{Text: "Jo 3:16-18", Book: "Jo", Chapter: 3, Verse: 16, EndChapter: 3, EndVerse: 18}},
+		{"joão 3.16-18", models.BibleReference{Text: "Jo 3:16-18", Book: "Jo", Chapter: 3, Verse: 16, EndChapter: 3, EndVerse: 18}},
+		{"  Jo 3 : 16 ", models.BibleReference{}},
+		{"Sl 23", models.BibleReference{Text: "Sl 23", Book: "Sl", Chapter: 23, EndChapter: 23}},
+		{"Gn 9-10", models.BibleReference{Text: "Gn 9-10", Book: "Gn", Chapter: 9, EndChapter: 10}},
+		{"Gn 1:26-2:3", models.BibleReference{Text: "Gn 1:26-2:3", Book: "Gn", Chapter: 1, Verse: 26, EndChapter: 2, EndVerse: 3}},
+		{"1 Coríntios 13", models.BibleReference{Text: "1 Co 13", Book: "1 Co", Chapter: 13, EndChapter: 13}},
+		{"1Co 13:4-7", models.BibleReference{Text: "1 Co 13:4-7", Book: "1 Co", Chapter: 13, Verse: 4, EndChapter: 13, EndVerse: 7}},
+		{"1 co. 13", models.BibleReference{Text: "1 Co 13", Book: "1 Co", Chapter: 13, EndChapter: 13}},
+		{"Jó 1:21", models.BibleReference{Text: "Jó 1:21", Book: "Jó", Chapter: 1, Verse: 21, EndChapter: 1, EndVerse: 21}},
+		{"Ex 20", models.BibleReference{Text: "Êx 20", Book: "Êx", Chapter: 20, EndChapter: 20}},
+		{"exodo 20:1-17", models.BibleReference{Text: "Êx 20:1-17", Book: "Êx", Chapter: 20, Verse: 1, EndChapter: 20, EndVerse: 17}},
+		{"Fl 1", models.BibleReference{Text: "Fl 1", Book: "Fl", Chapter: 1, EndChapter: 1}},
+		{"Ap 22:20", models.BibleReference{Text: "Ap 22:20", Book: "Ap", Chapter: 22, Verse: 20, EndChapter: 22, EndVerse: 20}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := bibleref.Parse(tt.in)
+			if tt.want.Book == "" {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want %+v", err, tt.want)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidReferences(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"no chapter", "Jo"},
+		{"only numbers", "3:16"},
+		{"unknown book", "Hezekias 3:16"},
+		{"chapter zero", "Jo 0"},
+		{"chapter past the end of the book", "Jd 2"},
+		{"range past the end of the book", "Ml 3-5"},
+		{"chapters out of order", "Gn 10-9"},
+		{"verses out of order", "Jo 3:18-16"},
+		{"verse zero", "Jo 3:0"},
+		{"verse range from zero", "Jo 3:0-5"},
+		{"end verse zero", "Gn 1:26-2:0"},
+		{"end verse without a start verse", "Gn 1-2:3"},
+		{"trailing text", "Jo 3:16 e 17"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := bibleref.Parse(tt.in); err == nil {
+				t.Errorf("Parse(%q) = %+v, want an error", tt.in, got)
+			}
+		})
+	}
+}
+
+func TestLookupBook(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Jo", "João"},
+		{"jo", "João"},
+		{"Jó", "Jó"},
+		{"joão", "João"},
+		{"JOAO", "João"},
+		{"1 Jo", "1 João"},
+		{"1jo", "1 João"},
+		{"Ex", "Êxodo"},
+		{"Êx", "Êxodo"},
+		{"Fl", "Filemom"},
+		{"Fp", "Filipenses"},
+		{"cantares", "Cantares"},
+		{"2 Tessalonicenses", "2 Tessalonicenses"},
+		{"Hezekias", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			book := bibleref.LookupBook(tt.in)
+			got := ""
+			if book != nil {
+				got = book.Name
+			}
+			if got != tt.want {
+				t.Errorf("LookupBook(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		ref  models.BibleReference
+		want string
+	}{
+		{models.BibleReference{Book: "Sl", Chapter: 23, EndChapter: 23}, "Sl 23"},
+		{models.BibleReference{Book: "Gn", Chapter: 9, EndChapter: 10}, "Gn 9-10"},
+		{models.BibleReference{Book: "Jo", Chapter: 3, Verse: 16, EndChapter: 3, EndVerse: 16}, "Jo 3:16"},
+		{models.BibleReference{Book: "Jo", Chapter: 3, Verse: 16, EndChapter: 3, EndVerse: 18}, "Jo 3:16-18"},
+		{models.BibleReference{Book: "Gn", Chapter: 1, Verse: 26, EndChapter: 2, EndVerse: 3}, "Gn 1:26-2:3"},
+	}
+
+	for _, tt := range tests {
+		if got := bibleref.Format(&tt.ref); got != tt.want {
+			t.Errorf("Format(%+v) = %q, want %q", tt.ref, got, tt.want)
+		}
+		// Formatted references parse back to themselves
+		parsed, err := bibleref.Parse(tt.want)
+		if err != nil || parsed.Text != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v", tt.want, parsed, err)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package catechismimport parses, validates and diffs catechism files
+// uploaded by administrators.
+package catechismimport
+
+import (
+	"biblia-am-pm/internal/models"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Supported file formats
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatYAML = "yaml"
+)
+
+// item accepts both the "q"/"a" and "question"/"answer" field names used by
+// the catechism files in cmd/populate-catechism
+type item struct {
+	Number   int    `json:"number" yaml:"number"`
+	Q        string `json:"q" yaml:"q"`
+	A        string `json:"a" yaml:"a"`
+	Question string `json:"question" yaml:"question"`
+	Answer   string `json:"answer" yaml:"answer"`
+}
+
+func (i item) toQuestion() *models.CatechismQuestion {
+	questionText := i.Q
+	if questionText == "" {
+		questionText = i.Question
+	}
+	answerText := i.A
+	if answerText == "" {
+		answerText = i.Answer
+	}
+	return &models.CatechismQuestion{
+		QuestionNumber: i.Number,
+		QuestionText:   strings.TrimSpace(questionText),
+		AnswerText:     strings.TrimSpace(answerText),
+	}
+}
+
+// FormatFromFilename detects the file format from its extension
+func FormatFromFilename(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return ""
+}
+
+// Parse reads a catechism file in the given format
+func Parse(data []byte, format string) ([]*models.CatechismQuestion, error) {
+	var items []item
+
+	switch format {
+	case FormatJSON:
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("invalid YAML: %w", err)
+		}
+	case FormatCSV:
+		var err error
+		items, err = parseCSV(data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	questions := make([]*models.CatechismQuestion, 0, len(items))
+	for _, it := range items {
+		questions = append(questions, it.toQuestion())
+	}
+	return questions, nil
+}
+
+// parseCSV reads a CSV file with a header row containing the columns
+// number, question (or q) and answer (or a)
+func parseCSV(data []byte) ([]item, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	numberCol, ok := columns["number"]
+	if !ok {
+		return nil, fmt.Errorf("CSV header must contain a \"number\" column")
+	}
+	questionCol, ok := columns["question"]
+	if !ok {
+		questionCol, ok = columns["q"]
+	}
+	if !ok {
+		return nil, fmt.Errorf("CSV header must contain a \"question\" column")
+	}
+	answerCol, ok := columns["answer"]
+	if !ok {
+		answerCol, ok = columns["a"]
+	}
+	if !ok {
+		return nil, fmt.Errorf("CSV header must contain an \"answer\" column")
+	}
+
+	var items []item
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+
+		number, err := strconv.Atoi(strings.TrimSpace(record[numberCol]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid question number %q", line, record[numberCol])
+		}
+		items = append(items, item{
+			Number:   number,
+			Question: record[questionCol],
+			Answer:   record[answerCol],
+		})
+	}
+
+	return items, nil
+}
+
+// ValidationError lists every problem found in an imported file
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid catechism: %s", strings.Join(e.Problems, "; "))
+}
+
+// Validate checks that questions are numbered contiguously from 1 and that no
+// text is empty. Questions are sorted by number in place.
+func Validate(questions []*models.CatechismQuestion) error {
+	if len(questions) == 0 {
+		return &ValidationError{Problems: []string{"no questions found"}}
+	}
+
+	sort.SliceStable(questions, func(i, j int) bool {
+		return questions[i].QuestionNumber < questions[j].QuestionNumber
+	})
+
+	var problems []string
+	for i, question := range questions {
+		expected := i + 1
+		if question.QuestionNumber != expected {
+			problems = append(problems, fmt.Sprintf("expected question %d, found %d", expected, question.QuestionNumber))
+			break
+		}
+	}
+	for _, question := range questions {
+		if question.QuestionText == "" {
+			problems = append(problems, fmt.Sprintf("question %d has empty question text", question.QuestionNumber))
+		}
+		if question.AnswerText == "" {
+			problems = append(problems, fmt.Sprintf("question %d has empty answer text", question.QuestionNumber))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Change describes a question whose text differs from the stored one
+type Change struct {
+	QuestionNumber int                       `json:"question_number"`
+	Old            *models.CatechismQuestion `json:"old"`
+	New            *models.CatechismQuestion `json:"new"`
+}
+
+// Diff is the difference between the stored catechism and an imported file
+type Diff struct {
+	Added     []*models.CatechismQuestion `json:"added"`
+	Changed   []Change                    `json:"changed"`
+	Removed   []*models.CatechismQuestion `json:"removed"`
+	Unchanged int                         `json:"unchanged"`
+}
+
+// HasChanges reports whether applying the import would modify anything
+func (d *Diff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Changed) > 0 || len(d.Removed) > 0
+}
+
+// Compare diffs the imported questions against the stored ones
+func Compare(stored, imported []*models.CatechismQuestion) *Diff {
+	diff := &Diff{
+		Added:   []*models.CatechismQuestion{},
+		Changed: []Change{},
+		Removed: []*models.CatechismQuestion{},
+	}
+
+	byNumber := make(map[int]*models.CatechismQuestion, len(stored))
+	for _, question := range stored {
+		byNumber[question.QuestionNumber] = question
+	}
+
+	seen := make(map[int]bool, len(imported))
+	for _, question := range imported {
+		seen[question.QuestionNumber] = true
+		existing, ok := byNumber[question.QuestionNumber]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, question)
+		case existing.QuestionText != question.QuestionText || existing.AnswerText != question.AnswerText ||
+			existing.Catechism != question.Catechism:
+			diff.Changed = append(diff.Changed, Change{
+				QuestionNumber: question.QuestionNumber,
+				Old:            existing,
+				New:            question,
+			})
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	for _, question := range stored {
+		if !seen[question.QuestionNumber] {
+			diff.Removed = append(diff.Removed, question)
+		}
+	}
+
+	return diff
+}
+
+// ParseSections reads the thematic sections of a catechism from JSON, either
+// a list of sections or an object with the sections of each catechism, like
+// cmd/populate-catechism/sections.json
+func ParseSections(data []byte, catechism string) ([]*models.CatechismSection, error) {
+	var sections []*models.CatechismSection
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &sections); err != nil {
+			return nil, fmt.Errorf("invalid sections JSON: %w", err)
+		}
+		return sections, nil
+	}
+
+	var byCatechism map[string][]*models.CatechismSection
+	if err := json.Unmarshal(data, &byCatechism); err != nil {
+		return nil, fmt.Errorf("invalid sections JSON: %w", err)
+	}
+	sections, ok := byCatechism[catechism]
+	if !ok {
+		return nil, fmt.Errorf("no sections for catechism %q", catechism)
+	}
+	return sections, nil
+}
+
+// ValidateSections checks that every section has a unique slug and a title
+// and that the sections cover increasing, non-overlapping ranges of the total
+// questions
+func ValidateSections(sections []*models.CatechismSection, total int) error {
+	var problems []string
+	slugs := make(map[string]bool, len(sections))
+	previousEnd := 0
+	for i, section := range sections {
+		name := section.Slug
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			problems = append(problems, fmt.Sprintf("section %s has no slug", name))
+		} else if slugs[name] {
+			problems = append(problems, fmt.Sprintf("section %s is repeated", name))
+		}
+		slugs[name] = true
+
+		if strings.TrimSpace(section.Title) == "" {
+			problems = append(problems, fmt.Sprintf("section %s has no title", name))
+		}
+		switch {
+		case section.StartQuestion < 1 || section.EndQuestion > total || section.StartQuestion > section.EndQuestion:
+			problems = append(problems, fmt.Sprintf("section %s covers questions %d-%d, outside 1-%d", name, section.StartQuestion, section.EndQuestion, total))
+		case section.StartQuestion <= previousEnd:
+			problems = append(problems, fmt.Sprintf("section %s starts at question %d, before the previous one ends", name, section.StartQuestion))
+		default:
+			previousEnd = section.EndQuestion
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package catechismimport_test
+
+import (
+	"biblia-am-pm/internal/catechismimport"
+	"biblia-am-pm/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func question(number int, questionText, answerText string) *models.CatechismQuestion {
+	return &models.CatechismQuestion{QuestionNumber: number, QuestionText: questionText, AnswerText: answerText}
+}
+
+func TestParse(t *testing.T) {
+	want := []*models.CatechismQuestion{
+		question(1, "Qual é o fim principal do homem?", "Glorificar a Deus, e gozá-lo para sempre."),
+		question(2, "Que regra Deus nos deu?", "A palavra de Deus."),
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"JSON with q and a", catechismimport.FormatJSON, `[
+			{"number": 1, "q": "Qual é o fim principal do homem?", "a": "Glorificar a Deus, e gozá-lo para sempre."},
+			{"number": 2, "q": "Que regra Deus nos deu?", "a": "A palavra de Deus."}
+		]`},
+		{"JSON with question and answer", catechismimport.FormatJSON, `[
+			{"number": 1, "question": "  Qual é o fim principal do homem? ", "answer": "Glorificar a Deus, e gozá-lo para sempre.\n"},
+			{"number": 2, "question": "Que regra Deus nos deu?", "answer": "A palavra de Deus."}
+		]`},
+		{"CSV", catechismimport.FormatCSV, "number,question,answer\n" +
+			`1,Qual é o fim principal do homem?,"Glorificar a Deus, e gozá-lo para sempre."` + "\n" +
+			"2,Que regra Deus nos deu?,A palavra de Deus.\n"},
+		{"CSV with q and a in another order", catechismimport.FormatCSV, "A, Q, Number\n" +
+			`"Glorificar a Deus, e gozá-lo para sempre.",Qual é o fim principal do homem?,1` + "\n" +
+			"A palavra de Deus.,Que regra Deus nos deu?, 2\n"},
+		{"YAML", catechismimport.FormatYAML, `
+- number: 1
+  q: Qual é o fim principal do homem?
+  a: Glorificar a Deus, e gozá-lo para sempre.
+- number: 2
+  question: Que regra Deus nos deu?
+  answer: A palavra de Deus.
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := catechismimport.Parse([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"unsupported format", "xml", `<catechism/>`},
+		{"invalid JSON", catechismimport.FormatJSON, `[{"number": 1,`},
+		{"JSON object", catechismimport.FormatJSON, `{"number": 1}`},
+		{"invalid YAML", catechismimport.FormatYAML, "- number: [1\n"},
+		{"empty CSV", catechismimport.FormatCSV, ""},
+		{"CSV without number", catechismimport.FormatCSV, "question,answer\nP,R\n"},
+		{"CSV without question", catechismimport.FormatCSV, "number,answer\n1,R\n"},
+		{"CSV without answer", catechismimport.FormatCSV, "number,question\n1,P\n"},
+		{"CSV with an invalid number", catechismimport.FormatCSV, "number,question,answer\num,P,R\n"},
+		{"CSV with a short row", catechismimport.FormatCSV, "number,question,answer\n1,P\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := catechismimport.Parse([]byte(tt.data), tt.format); err == nil {
+				t.Errorf("got %+v, want an error", got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		questions []*models.CatechismQuestion
+		want      []string
+	}{
+		{"contiguous", []*models.CatechismQuestion{question(1, "P1", "R1"), question(2, "P2", "R2")}, nil},
+		{"out of order", []*models.CatechismQuestion{question(2, "P2", "R2"), question(1, "P1", "R1")}, nil},
+		{"no questions", nil, []string{"no questions found"}},
+		{"not starting at 1", []*models.CatechismQuestion{question(2, "P2", "R2")}, []string{"expected question 1, found 2"}},
+		{"gap", []*models.CatechismQuestion{question(1, "P1", "R1"), question(3, "P3", "R3")}, []string{"expected question 2, found 3"}},
+		{"repeated number", []*models.CatechismQuestion{question(1, "P1", "R1"), question(1, "P1", "R1"), question(2, "P2", "R2")}, []string{"expected question 2, found 1"}},
+		{"empty question text", []*models.CatechismQuestion{question(1, "", "R1")}, []string{"question 1 has empty question text"}},
+		{"empty answer text", []*models.CatechismQuestion{question(1, "P1", "")}, []string{"question 1 has empty answer text"}},
+		{"every problem", []*models.CatechismQuestion{question(1, "", ""), question(3, "P3", "")}, []string{
+			"expected question 2, found 3",
+			"question 1 has empty question text",
+			"question 1 has empty answer text",
+			"question 3 has empty answer text",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := catechismimport.Validate(tt.questions)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				for i, q := range tt.questions {
+					if q.QuestionNumber != i+1 {
+						t.Errorf("question %d is number %d after sorting", i, q.QuestionNumber)
+					}
+				}
+				return
+			}
+			var validationErr *catechismimport.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("got %v, want a ValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Problems, tt.want) {
+				t.Errorf("got problems %q, want %q", validationErr.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	stored := []*models.CatechismQuestion{
+		question(1, "P1", "R1"),
+		question(2, "P2", "R2"),
+		question(3, "P3", "R3"),
+	}
+
+	tests := []struct {
+		name          string
+		imported      []*models.CatechismQuestion
+		wantAdded     []int
+		wantChanged   []int
+		wantRemoved   []int
+		wantUnchanged int
+	}{
+		{"identical", []*models.CatechismQuestion{question(1, "P1", "R1"), question(2, "P2", "R2"), question(3, "P3", "R3")}, nil, nil, nil, 3},
+		{"added", []*models.CatechismQuestion{question(1, "P1", "R1"), question(2, "P2", "R2"), question(3, "P3", "R3"), question(4, "P4", "R4")}, []int{4}, nil, nil, 3},
+		{"question text changed", []*models.CatechismQuestion{question(1, "P1 nova", "R1"), question(2, "P2", "R2"), question(3, "P3", "R3")}, nil, []int{1}, nil, 2},
+		{"answer text changed", []*models.CatechismQuestion{question(1, "P1", "R1"), question(2, "P2", "R2 nova"), question(3, "P3", "R3")}, nil, []int{2}, nil, 2},
+		{"removed", []*models.CatechismQuestion{question(1, "P1", "R1")}, nil, nil, []int{2, 3}, 1},
+		{"everything", []*models.CatechismQuestion{question(1, "P1", "R1"), question(3, "P3", "R3 nova"), question(5, "P5", "R5")}, []int{5}, []int{3}, []int{2}, 1},
+		{"empty file", nil, nil, nil, []int{1, 2, 3}, 0},
+	}
+
+	numbers := func(questions []*models.CatechismQuestion) []int {
+		var result []int
+		for _, q := range questions {
+			result = append(result, q.QuestionNumber)
+		}
+		return result
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := catechismimport.Compare(stored, tt.imported)
+
+			var changed []int
+			for _, change := range diff.Changed {
+				if change.Old.QuestionNumber != change.QuestionNumber || change.New.QuestionNumber != change.QuestionNumber {
+					t.Errorf("change %d pairs questions %d and %d", change.QuestionNumber, change.Old.QuestionNumber, change.New.QuestionNumber)
+				}
+				changed = append(changed, change.QuestionNumber)
+			}
+			if !reflect.DeepEqual(numbers(diff.Added), tt.wantAdded) || !reflect.DeepEqual(changed, tt.wantChanged) ||
+				!reflect.DeepEqual(numbers(diff.Removed), tt.wantRemoved) || diff.Unchanged != tt.wantUnchanged {
+				t.Errorf("got added %v, changed %v, removed %v, %d unchanged; want %v, %v, %v, %d",
+					numbers(diff.Added), changed, numbers(diff.Removed), diff.Unchanged,
+					tt.wantAdded, tt.wantChanged, tt.wantRemoved, tt.wantUnchanged)
+			}
+			if hasChanges := tt.wantAdded != nil || tt.wantChanged != nil || tt.wantRemoved != nil; diff.HasChanges() != hasChanges {
+				t.Errorf("HasChanges = %v, want %v", diff.HasChanges(), hasChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,529 @@
+// Package config loads the application settings into a single validated
+// struct. Values come from, in increasing priority: the defaults of the
+// environment (development or production), an optional YAML or TOML file,
+// environment variables and command line flags.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Environments
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Log formats
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// Tracing exporters
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// Database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// minSecretLength is the shortest JWT secret accepted in production
+const minSecretLength = 32
+
+// redacted replaces secrets when the configuration is printed
+const redacted = "[REDACTED]"
+
+// unsafeSecrets are the placeholder values shipped in the example files
+var unsafeSecrets = []string{
+	"your-secret-key",
+	"your-secret-key-change-in-production",
+	"dev-secret-key-change-in-production",
+	"CHANGE_THIS_SECRET_KEY",
+	"CHANGE_THIS_PASSWORD",
+	"postgres",
+}
+
+type Config struct {
+	Env      string   `yaml:"env" toml:"env"`
+	Timezone string   `yaml:"timezone" toml:"timezone"`
+	Server   Server   `yaml:"server" toml:"server"`
+	Database Database `yaml:"database" toml:"database"`
+	Auth     Auth     `yaml:"auth" toml:"auth"`
+	Metrics  Metrics  `yaml:"metrics" toml:"metrics"`
+	Log      Log      `yaml:"log" toml:"log"`
+	Tracing  Tracing  `yaml:"tracing" toml:"tracing"`
+
+	// PrintConfig is set by --print-config. It is not a setting.
+	PrintConfig bool `yaml:"-" toml:"-"`
+}
+
+type Server struct {
+	Port string `yaml:"port" toml:"port"`
+	// CORSAllowedOrigins lists the allowed origins. "*" allows every origin, without credentials.
+	CORSAllowedOrigins []string `yaml:"cors_allowed_origins" toml:"cors_allowed_origins"`
+
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// ShutdownDelay is how long the server keeps serving after readiness is
+	// turned off, so load balancers stop sending traffic before it drains
+	ShutdownDelay Duration `yaml:"shutdown_delay" toml:"shutdown_delay"`
+	// ShutdownTimeout bounds the time given to in-flight requests and
+	// background workers to finish
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// IdempotencyKeyTTL is how long the response to a request sent with an
+	// Idempotency-Key header is replayed to its retries
+	IdempotencyKeyTTL Duration `yaml:"idempotency_key_ttl" toml:"idempotency_key_ttl"`
+}
+
+type Database struct {
+	// Driver is "postgres" or "sqlite"
+	Driver   string `yaml:"driver" toml:"driver"`
+	Host     string `yaml:"host" toml:"host"`
+	Port     string `yaml:"port" toml:"port"`
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	Name     string `yaml:"name" toml:"name"`
+	SSLMode  string `yaml:"sslmode" toml:"sslmode"`
+	// Path is the database file when using SQLite
+	Path string `yaml:"path" toml:"path"`
+}
+
+type Auth struct {
+	JWTSecret string `yaml:"jwt_secret" toml:"jwt_secret"`
+}
+
+// Metrics says where /metrics is served. With Token it is served on the API
+// port to requests bearing the token; otherwise, with Addr, it gets a listener
+// of its own, which should not be exposed publicly. With neither it is off.
+type Metrics struct {
+	Addr  string `yaml:"addr" toml:"addr"`
+	Token string `yaml:"token" toml:"token"`
+}
+
+type Log struct {
+	// Level is "debug", "info", "warn" or "error"
+	Level string `yaml:"level" toml:"level"`
+	// Format is "json" or "text"
+	Format string `yaml:"format" toml:"format"`
+}
+
+type Tracing struct {
+	// Exporter is "none", "stdout" or "otlp"
+	Exporter string `yaml:"exporter" toml:"exporter"`
+	// Endpoint is the OTLP/HTTP collector URL, e.g. http://jaeger:4318. When
+	// empty the OTEL_EXPORTER_OTLP_* variables apply.
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+	// SampleRatio is the fraction of traces recorded, from 0 to 1. Requests
+	// continuing a trace follow the decision of its caller.
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio"`
+	ServiceName string  `yaml:"service_name" toml:"service_name"`
+}
+
+// Duration is a time.Duration written as "30s" or "1m" in files and
+// environment variables
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Defaults returns the default settings of an environment. Development has
+// working credentials for the local containers; production has none, so they
+// must be configured.
+func Defaults(env string) *Config {
+	cfg := &Config{
+		Env:      env,
+		Timezone: "America/Sao_Paulo",
+		Server: Server{
+			Port: "8080",
+			CORSAllowedOrigins: []string{
+				"http://localhost:3001",
+				"http://hiagoserver.local:3001",
+				"http://hiagoserver.local",
+				"https://bibliampm-api.klapowsko.com",
+				"https://bibliampm.klapowsko.com",
+			},
+			ReadTimeout:       Duration(15 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(30 * time.Second),
+			IdleTimeout:       Duration(60 * time.Second),
+			ShutdownTimeout:   Duration(10 * time.Second),
+			IdempotencyKeyTTL: Duration(24 * time.Hour),
+		},
+		Database: Database{
+			Driver:  DriverPostgres,
+			Host:    "localhost",
+			Port:    "5432",
+			User:    "postgres",
+			Name:    "biblia_db",
+			SSLMode: "disable",
+			Path:    "biblia.db",
+		},
+		Log: Log{
+			Level:  "info",
+			Format: LogFormatJSON,
+		},
+		Tracing: Tracing{
+			Exporter:    TracingExporterNone,
+			SampleRatio: 1,
+			ServiceName: "biblia-am-pm",
+		},
+	}
+
+	if env != EnvProduction {
+		cfg.Database.Password = "postgres"
+		cfg.Auth.JWTSecret = "your-secret-key"
+		cfg.Metrics.Addr = "localhost:9090"
+	}
+
+	return cfg
+}
+
+// Load builds the configuration from the defaults, the file given by --config
+// or CONFIG_FILE, the environment and the flags in args. The result still has
+// to be checked with Validate.
+func Load(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("biblia-am-pm", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML configuration file")
+	env := flags.String("env", "", "Environment: development or production (APP_ENV)")
+	port := flags.String("port", "", "HTTP port (API_PORT)")
+	driver := flags.String("db-driver", "", "Database driver: postgres or sqlite (DB_DRIVER)")
+	path := flags.String("db-path", "", "SQLite database file (DB_PATH)")
+	printConfig := flags.Bool("print-config", false, "Print the effective configuration, with secrets redacted, and exit")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// The environment picks the defaults, so it is resolved first
+	name := firstNonEmpty(*env, os.Getenv("APP_ENV"))
+	if name == "" && *configFile != "" {
+		var err error
+		if name, err = fileEnv(*configFile); err != nil {
+			return nil, err
+		}
+	}
+	name, err := normalizeEnv(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := Defaults(name)
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	cfg.Env = name
+	setIfNotEmpty(&cfg.Server.Port, *port)
+	setIfNotEmpty(&cfg.Database.Driver, *driver)
+	setIfNotEmpty(&cfg.Database.Path, *path)
+	cfg.PrintConfig = *printConfig
+
+	return cfg, nil
+}
+
+// FromEnv loads and validates the configuration without command line flags.
+// It is meant for the commands in cmd/, which have flags of their own.
+func FromEnv() (*Config, error) {
+	cfg, err := Load(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func normalizeEnv(env string) (string, error) {
+	switch strings.ToLower(env) {
+	case "", "dev", EnvDevelopment:
+		return EnvDevelopment, nil
+	case "prod", EnvProduction:
+		return EnvProduction, nil
+	default:
+		return "", fmt.Errorf("unknown environment %q (use %q or %q)", env, EnvDevelopment, EnvProduction)
+	}
+}
+
+// decodeFile reads a YAML or TOML file, by its extension, into out. Unknown
+// keys are rejected so typos don't go unnoticed.
+func decodeFile(path string, out interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(out)
+		if errors.Is(err, io.EOF) {
+			err = nil
+		}
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(out)
+	default:
+		return fmt.Errorf("unsupported config file %s (use .yaml, .yml or .toml)", path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// fileEnv returns the environment set in a config file, if any
+func fileEnv(path string) (string, error) {
+	cfg := &Config{}
+	if err := decodeFile(path, cfg); err != nil {
+		return "", err
+	}
+	return cfg.Env, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	return decodeFile(path, c)
+}
+
+func (c *Config) loadEnv() error {
+	setFromEnv(&c.Timezone, "TZ")
+	setFromEnv(&c.Server.Port, "API_PORT")
+	if origins := strings.TrimSpace(os.Getenv("CORS_ALLOWED_ORIGINS")); origins != "" {
+		c.Server.CORSAllowedOrigins = nil
+		for _, origin := range strings.Split(origins, ",") {
+			if trimmed := strings.TrimSpace(origin); trimmed != "" {
+				c.Server.CORSAllowedOrigins = append(c.Server.CORSAllowedOrigins, trimmed)
+			}
+		}
+	}
+
+	setFromEnv(&c.Database.Driver, "DB_DRIVER")
+	setFromEnv(&c.Database.Host, "DB_HOST")
+	setFromEnv(&c.Database.Port, "DB_PORT")
+	setFromEnv(&c.Database.User, "DB_USER")
+	setFromEnv(&c.Database.Password, "DB_PASSWORD")
+	setFromEnv(&c.Database.Name, "DB_NAME")
+	setFromEnv(&c.Database.SSLMode, "DB_SSLMODE")
+	setFromEnv(&c.Database.Path, "DB_PATH")
+
+	setFromEnv(&c.Auth.JWTSecret, "JWT_SECRET")
+	setFromEnv(&c.Metrics.Addr, "METRICS_ADDR")
+	setFromEnv(&c.Metrics.Token, "METRICS_TOKEN")
+	setFromEnv(&c.Log.Level, "LOG_LEVEL")
+	setFromEnv(&c.Log.Format, "LOG_FORMAT")
+	setFromEnv(&c.Tracing.Exporter, "TRACING_EXPORTER")
+	setFromEnv(&c.Tracing.Endpoint, "TRACING_ENDPOINT")
+	setFromEnv(&c.Tracing.ServiceName, "OTEL_SERVICE_NAME")
+	if value := os.Getenv("TRACING_SAMPLE_RATIO"); value != "" {
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %w", err)
+		}
+		c.Tracing.SampleRatio = ratio
+	}
+
+	durations := []struct {
+		field *Duration
+		key   string
+	}{
+		{&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT"},
+		{&c.Server.ReadHeaderTimeout, "SERVER_READ_HEADER_TIMEOUT"},
+		{&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT"},
+		{&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT"},
+		{&c.Server.ShutdownDelay, "SERVER_SHUTDOWN_DELAY"},
+		{&c.Server.ShutdownTimeout, "SERVER_SHUTDOWN_TIMEOUT"},
+		{&c.Server.IdempotencyKeyTTL, "IDEMPOTENCY_KEY_TTL"},
+	}
+	for _, d := range durations {
+		if value := os.Getenv(d.key); value != "" {
+			if err := d.field.UnmarshalText([]byte(value)); err != nil {
+				return fmt.Errorf("invalid %s: %w", d.key, err)
+			}
+		}
+	}
+	return nil
+}
+
+func setFromEnv(field *string, key string) {
+	setIfNotEmpty(field, os.Getenv(key))
+}
+
+func setIfNotEmpty(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Validate checks the settings and, in production, refuses the unsafe ones:
+// missing or placeholder secrets and the default database password
+func (c *Config) Validate() error {
+	var problems []string
+
+	if _, err := normalizeEnv(c.Env); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid timezone %q", c.Timezone))
+	}
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		problems = append(problems, fmt.Sprintf("invalid server port %q", c.Server.Port))
+	}
+	if len(c.Server.CORSAllowedOrigins) == 0 {
+		problems = append(problems, "at least one CORS allowed origin is required")
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.ReadHeaderTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 || c.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "server timeouts must be positive")
+	}
+	if c.Server.ShutdownDelay < 0 {
+		problems = append(problems, "server shutdown delay can't be negative")
+	}
+	if c.Server.IdempotencyKeyTTL <= 0 {
+		problems = append(problems, "idempotency key TTL must be positive")
+	}
+
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("unknown log level %q (use debug, info, warn or error)", c.Log.Level))
+	}
+	if c.Log.Format != LogFormatJSON && c.Log.Format != LogFormatText {
+		problems = append(problems, fmt.Sprintf("unknown log format %q (use %q or %q)", c.Log.Format, LogFormatJSON, LogFormatText))
+	}
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout, TracingExporterOTLP:
+	default:
+		problems = append(problems, fmt.Sprintf("unknown tracing exporter %q (use %q, %q or %q)", c.Tracing.Exporter, TracingExporterNone, TracingExporterStdout, TracingExporterOTLP))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		problems = append(problems, "tracing sample ratio must be between 0 and 1")
+	}
+	if c.Tracing.ServiceName == "" {
+		problems = append(problems, "tracing service name is required")
+	}
+
+	switch c.Database.Driver {
+	case DriverPostgres:
+		if c.Database.Host == "" || c.Database.Name == "" || c.Database.User == "" {
+			problems = append(problems, "database host, name and user are required")
+		}
+	case DriverSQLite:
+		if c.Database.Path == "" {
+			problems = append(problems, "database path is required for SQLite")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown database driver %q (use %q or %q)", c.Database.Driver, DriverPostgres, DriverSQLite))
+	}
+
+	if c.Auth.JWTSecret == "" {
+		problems = append(problems, "JWT secret is required")
+	}
+
+	if c.Env == EnvProduction {
+		if c.Auth.JWTSecret != "" && (isUnsafeSecret(c.Auth.JWTSecret) || len(c.Auth.JWTSecret) < minSecretLength) {
+			problems = append(problems, fmt.Sprintf("JWT secret must be a random value of at least %d characters in production", minSecretLength))
+		}
+		if c.Database.Driver == DriverPostgres && (c.Database.Password == "" || isUnsafeSecret(c.Database.Password)) {
+			problems = append(problems, "database password must be set to a non-default value in production")
+		}
+		if c.Metrics.Token != "" && (isUnsafeSecret(c.Metrics.Token) || len(c.Metrics.Token) < minSecretLength) {
+			problems = append(problems, fmt.Sprintf("metrics token must be a random value of at least %d characters in production", minSecretLength))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func isUnsafeSecret(value string) bool {
+	for _, unsafe := range unsafeSecrets {
+		if value == unsafe {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the time zone used to decide the day and the period of the
+// readings. Validate makes sure it exists; UTC is only a fallback.
+func (c *Config) Location() *time.Location {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Redacted returns a copy of the configuration with the secrets hidden
+func (c *Config) Redacted() *Config {
+	copied := *c
+	copied.Server.CORSAllowedOrigins = append([]string(nil), c.Server.CORSAllowedOrigins...)
+	if copied.Database.Password != "" {
+		copied.Database.Password = redacted
+	}
+	if copied.Auth.JWTSecret != "" {
+		copied.Auth.JWTSecret = redacted
+	}
+	if copied.Metrics.Token != "" {
+		copied.Metrics.Token = redacted
+	}
+	return &copied
+}
+
+// Print writes the effective configuration as YAML, with the secrets redacted
+func (c *Config) Print(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
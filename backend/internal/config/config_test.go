@@ -0,0 +1,167 @@
+package config_test
+
+import (
+	"biblia-am-pm/internal/config"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const (
+	strongSecret   = "k8Jq2vN5xR7tW1zB4mC6pL9sD3fG0hYe"
+	strongPassword = "Vt9rQ2mX7wK4pZ8n"
+)
+
+// production returns production settings that pass validation
+func production() *config.Config {
+	cfg := config.Defaults(config.EnvProduction)
+	cfg.Auth.JWTSecret = strongSecret
+	cfg.Database.Password = strongPassword
+	return cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  func() *config.Config
+		// want is part of the error, or empty when the settings are valid
+		want string
+	}{
+		{
+			name: "development defaults",
+			cfg:  func() *config.Config { return config.Defaults(config.EnvDevelopment) },
+		},
+		{
+			name: "development accepts a short JWT secret",
+			cfg: func() *config.Config {
+				cfg := config.Defaults(config.EnvDevelopment)
+				cfg.Auth.JWTSecret = "short"
+				return cfg
+			},
+		},
+		{
+			name: "development requires a JWT secret",
+			cfg: func() *config.Config {
+				cfg := config.Defaults(config.EnvDevelopment)
+				cfg.Auth.JWTSecret = ""
+				return cfg
+			},
+			want: "JWT secret is required",
+		},
+		{
+			name: "production defaults have no secrets",
+			cfg:  func() *config.Config { return config.Defaults(config.EnvProduction) },
+			want: "JWT secret is required",
+		},
+		{
+			name: "production with strong secrets",
+			cfg:  production,
+		},
+		{
+			name: "production placeholder JWT secret",
+			cfg: func() *config.Config {
+				cfg := production()
+				cfg.Auth.JWTSecret = "your-secret-key-change-in-production"
+				return cfg
+			},
+			want: "JWT secret must be a random value",
+		},
+		{
+			name: "production short JWT secret",
+			cfg: func() *config.Config {
+				cfg := production()
+				cfg.Auth.JWTSecret = strongSecret[:31]
+				return cfg
+			},
+			want: "JWT secret must be a random value of at least 32 characters",
+		},
+		{
+			name: "production default database password",
+			cfg: func() *config.Config {
+				cfg := production()
+				cfg.Database.Password = "postgres"
+				return cfg
+			},
+			want: "database password must be set to a non-default value",
+		},
+		{
+			name: "production placeholder database password",
+			cfg: func() *config.Config {
+				cfg := production()
+				cfg.Database.Password = "CHANGE_THIS_PASSWORD"
+				return cfg
+			},
+			want: "database password must be set to a non-default value",
+		},
+		{
+			name: "production missing database password",
+			cfg: func() *config.Config {
+				cfg := production()
+				cfg.Database.Password = ""
+				return cfg
+			},
+			want: "database password must be set to a non-default value",
+		},
+		{
+			name: "production SQLite needs no database password",
+			cfg: func() *config.Config {
+				cfg := production()
+				cfg.Database.Driver = config.DriverSQLite
+				cfg.Database.Password = ""
+				return cfg
+			},
+		},
+		{
+			name: "production placeholder metrics token",
+			cfg: func() *config.Config {
+				cfg := production()
+				cfg.Metrics.Token = "CHANGE_THIS_SECRET_KEY"
+				return cfg
+			},
+			want: "metrics token must be a random value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg().Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case tt.want != "" && err == nil:
+				t.Errorf("got no error, want %q", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := production()
+	cfg.Metrics.Token = "m3Tr1cS-t0k3n-Qw8eR5tY2uI6oP9aSd"
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	printed := out.String()
+	for name, secret := range map[string]string{
+		"database password": cfg.Database.Password,
+		"JWT secret":        cfg.Auth.JWTSecret,
+		"metrics token":     cfg.Metrics.Token,
+	} {
+		if strings.Contains(printed, secret) {
+			t.Errorf("printed configuration contains the %s:\n%s", name, printed)
+		}
+	}
+	if count := strings.Count(printed, "[REDACTED]"); count != 3 {
+		t.Errorf("got %d redacted values, want 3:\n%s", count, printed)
+	}
+
+	// Printing doesn't change the configuration itself
+	if cfg.Auth.JWTSecret != strongSecret || cfg.Database.Password != strongPassword {
+		t.Error("Print changed the secrets of the configuration")
+	}
+}
@@ -1,61 +1,68 @@
 package database
 
 import (
+	"biblia-am-pm/internal/config"
 	"database/sql"
 	"fmt"
-	"os"
+	"net/url"
 
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
-var DB *sql.DB
-
-func InitDB() error {
-	host := os.Getenv("DB_HOST")
-	if host == "" {
-		host = "localhost"
+// Open opens and checks the database described by cfg
+func Open(cfg config.Database) (*sql.DB, error) {
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		return openPostgres(cfg)
+	case config.DriverSQLite:
+		return OpenSQLite(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
 	}
+}
 
-	port := os.Getenv("DB_PORT")
-	if port == "" {
-		port = "5432"
-	}
+// PostgresDSN returns the connection string of the PostgreSQL database in cfg,
+// for the connections opened outside the pool, like the ones that LISTEN
+func PostgresDSN(cfg config.Database) string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode)
+}
 
-	user := os.Getenv("DB_USER")
-	if user == "" {
-		user = "postgres"
+func openPostgres(cfg config.Database) (*sql.DB, error) {
+	db, err := sql.Open("postgres", PostgresDSN(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	password := os.Getenv("DB_PASSWORD")
-	if password == "" {
-		password = "postgres"
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	dbname := os.Getenv("DB_NAME")
-	if dbname == "" {
-		dbname = "biblia_db"
-	}
+	return db, nil
+}
 
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
+// OpenSQLite opens the SQLite database file at path, creating it if needed.
+// Foreign keys are enforced and times are stored in a format SQLite can
+// compare. SQLite allows a single writer, so the pool keeps one connection.
+func OpenSQLite(path string) (*sql.DB, error) {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Set("_time_format", "sqlite")
 
-	var err error
-	DB, err = sql.Open("postgres", psqlInfo)
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	db.SetMaxOpenConns(1)
 
-	if err = DB.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return nil
+	return db, nil
 }
-
-func CloseDB() error {
-	if DB != nil {
-		return DB.Close()
-	}
-	return nil
-}
-
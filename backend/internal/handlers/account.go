@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler serves the data of the user's own account
+type AccountHandler struct {
+	userRepo              repository.UserRepository
+	userProgressRepo      repository.UserProgressRepository
+	catechismProgressRepo repository.CatechismProgressRepository
+	progressEventRepo     repository.ProgressEventRepository
+	noteRepo              repository.NoteRepository
+}
+
+func NewAccountHandler(
+	userRepo repository.UserRepository,
+	userProgressRepo repository.UserProgressRepository,
+	catechismProgressRepo repository.CatechismProgressRepository,
+	progressEventRepo repository.ProgressEventRepository,
+	noteRepo repository.NoteRepository,
+) *AccountHandler {
+	return &AccountHandler{
+		userRepo:              userRepo,
+		userProgressRepo:      userProgressRepo,
+		catechismProgressRepo: catechismProgressRepo,
+		progressEventRepo:     progressEventRepo,
+		noteRepo:              noteRepo,
+	}
+}
+
+// AccountExport is everything the user recorded in the account
+type AccountExport struct {
+	ExportedAt        time.Time                   `json:"exported_at"`
+	User              *models.User                `json:"user"`
+	ReadingProgress   []*models.UserProgress      `json:"reading_progress"`
+	CatechismProgress []*models.CatechismProgress `json:"catechism_progress"`
+	ProgressEvents    []*models.ProgressEvent     `json:"progress_events"`
+	Notes             []*models.Note              `json:"notes"`
+}
+
+// Export returns the user's account with its reading and catechism progress,
+// the log of marks and unmarks and the notes, as a JSON attachment
+func (h *AccountHandler) Export(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get user", err)
+		return
+	}
+	if user == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUserNotFound))
+		return
+	}
+
+	export := &AccountExport{ExportedAt: time.Now().UTC(), User: user}
+	if export.ReadingProgress, err = h.userProgressRepo.GetUserProgress(ctx, userID); err != nil {
+		apierror.Internal(c, "Failed to get progress", err)
+		return
+	}
+	if export.CatechismProgress, err = h.catechismProgressRepo.GetUserProgress(ctx, userID); err != nil {
+		apierror.Internal(c, "Failed to get catechism progress", err)
+		return
+	}
+	if export.ProgressEvents, err = h.progressEventRepo.GetByUser(ctx, userID); err != nil {
+		apierror.Internal(c, "Failed to get progress events", err)
+		return
+	}
+	if export.Notes, err = h.noteRepo.GetAll(ctx, userID); err != nil {
+		apierror.Internal(c, "Failed to get notes", err)
+		return
+	}
+
+	if export.ReadingProgress == nil {
+		export.ReadingProgress = []*models.UserProgress{}
+	}
+	if export.CatechismProgress == nil {
+		export.CatechismProgress = []*models.CatechismProgress{}
+	}
+	if export.ProgressEvents == nil {
+		export.ProgressEvents = []*models.ProgressEvent{}
+	}
+
+	filename := fmt.Sprintf("biblia-am-pm-%s.json", export.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.JSON(http.StatusOK, export)
+}
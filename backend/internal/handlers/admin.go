@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/catechismimport"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/textdiff"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportFileSize limits uploaded catechism files to 5 MB
+const maxImportFileSize = 5 << 20
+
+type AdminHandler struct {
+	catechismRepo        repository.CatechismRepository
+	catechismSectionRepo repository.CatechismSectionRepository
+}
+
+func NewAdminHandler(catechismRepo repository.CatechismRepository, catechismSectionRepo repository.CatechismSectionRepository) *AdminHandler {
+	return &AdminHandler{
+		catechismRepo:        catechismRepo,
+		catechismSectionRepo: catechismSectionRepo,
+	}
+}
+
+type CatechismImportResponse struct {
+	DryRun   bool                  `json:"dry_run"`
+	Applied  bool                  `json:"applied"`
+	Pruned   bool                  `json:"pruned"`
+	Total    int                   `json:"total"`
+	Diff     *catechismimport.Diff `json:"diff"`
+	Sections int                   `json:"sections"`
+}
+
+// ImportCatechism replaces the stored questions of a catechism (the required
+// catechism field) with an uploaded JSON, CSV or YAML file. With dry_run=true
+// it only reports the diff. Questions missing from the file are only retired
+// with prune=true: they leave the catechism but keep their revisions, progress
+// and notes until a later import restores them. An optional sections file
+// replaces the catechism's thematic sections.
+func (h *AdminHandler) ImportCatechism(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismFileRequired))
+		return
+	}
+
+	format := strings.ToLower(c.PostForm("format"))
+	if format == "" {
+		format = catechismimport.FormatFromFilename(fileHeader.Filename)
+	}
+	if format == "" {
+		apierror.Abort(c, apierror.New(apierror.CodeUnknownFileFormat))
+		return
+	}
+
+	data, ok := readImportFile(c, fileHeader)
+	if !ok {
+		return
+	}
+
+	// The catechism is always explicit, so a file is never imported into the wrong one
+	catechism := c.PostForm("catechism")
+	if !models.IsValidCatechism(catechism) {
+		apierror.Abort(c, apierror.New(apierror.CodeUnknownCatechism, models.CatechismShorter, models.CatechismLarger))
+		return
+	}
+
+	questions, err := catechismimport.Parse(data, format)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismParseFailed, err))
+		return
+	}
+	for _, question := range questions {
+		question.Catechism = catechism
+	}
+
+	if err := catechismimport.Validate(questions); err != nil {
+		abortInvalidImport(c, err)
+		return
+	}
+
+	var sections []*models.CatechismSection
+	if sectionsHeader, err := c.FormFile("sections"); err == nil {
+		data, ok := readImportFile(c, sectionsHeader)
+		if !ok {
+			return
+		}
+		sections, err = catechismimport.ParseSections(data, catechism)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeCatechismParseFailed, err))
+			return
+		}
+		if err := catechismimport.ValidateSections(sections, len(questions)); err != nil {
+			abortInvalidImport(c, err)
+			return
+		}
+	}
+
+	stored, err := h.catechismRepo.GetAll(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get stored catechism", err)
+		return
+	}
+
+	diff := catechismimport.Compare(stored, questions)
+	dryRun := c.Query("dry_run") == "true" || c.PostForm("dry_run") == "true"
+	prune := c.Query("prune") == "true" || c.PostForm("prune") == "true"
+
+	response := CatechismImportResponse{
+		DryRun:   dryRun,
+		Pruned:   prune,
+		Total:    len(questions),
+		Diff:     diff,
+		Sections: len(sections),
+	}
+
+	if dryRun || (!diff.HasChanges() && sections == nil) {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	upserts := make([]*models.CatechismQuestion, 0, len(diff.Added)+len(diff.Changed))
+	upserts = append(upserts, diff.Added...)
+	for _, change := range diff.Changed {
+		upserts = append(upserts, change.New)
+	}
+
+	var removeNumbers []int
+	if prune {
+		for _, question := range diff.Removed {
+			removeNumbers = append(removeNumbers, question.QuestionNumber)
+		}
+	}
+
+	if err := h.catechismRepo.ApplyImport(c.Request.Context(), catechism, upserts, removeNumbers, &userID); err != nil {
+		apierror.Internal(c, "Failed to apply catechism import", err)
+		return
+	}
+
+	if sections != nil {
+		if err := h.catechismSectionRepo.ReplaceForCatechism(c.Request.Context(), catechism, sections); err != nil {
+			apierror.Internal(c, "Failed to save sections", err)
+			return
+		}
+	}
+
+	response.Applied = true
+	c.JSON(http.StatusOK, response)
+}
+
+// readImportFile reads an uploaded file, writing the error response when it
+// is too large or can't be read
+func readImportFile(c *gin.Context, fileHeader *multipart.FileHeader) ([]byte, bool) {
+	if fileHeader.Size > maxImportFileSize {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismFileTooLarge))
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeFileUnreadable))
+		return nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeFileUnreadable))
+		return nil, false
+	}
+	return data, true
+}
+
+// abortInvalidImport answers with the problems found validating an import
+func abortInvalidImport(c *gin.Context, err error) {
+	var validationErr *catechismimport.ValidationError
+	if errors.As(err, &validationErr) {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismInvalid).With("problems", validationErr.Problems))
+		return
+	}
+	apierror.Abort(c, apierror.New(apierror.CodeCatechismInvalid).With("problems", []string{err.Error()}))
+}
+
+type UpdateCatechismQuestionRequest struct {
+	QuestionText string `json:"question_text"`
+	AnswerText   string `json:"answer_text"`
+}
+
+type CatechismRevisionDiffResponse struct {
+	QuestionNumber int                       `json:"question_number"`
+	From           *models.CatechismRevision `json:"from"`
+	To             *models.CatechismRevision `json:"to"`
+	QuestionDiff   []textdiff.Op             `json:"question_diff"`
+	AnswerDiff     []textdiff.Op             `json:"answer_diff"`
+}
+
+// getQuestionFromParam loads the question referenced by the :number route
+// parameter in the catechism of the catechism query parameter (the Shorter by
+// default), writing the error response when it can't
+func (h *AdminHandler) getQuestionFromParam(c *gin.Context) *models.CatechismQuestion {
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidQuestionNumber))
+		return nil
+	}
+
+	catechism := c.Query("catechism")
+	if catechism == "" {
+		catechism = models.CatechismShorter
+	}
+	if !models.IsValidCatechism(catechism) {
+		apierror.Abort(c, apierror.New(apierror.CodeUnknownCatechism, models.CatechismShorter, models.CatechismLarger))
+		return nil
+	}
+
+	question, err := h.catechismRepo.GetByQuestionNumber(c.Request.Context(), catechism, number)
+	if err != nil {
+		apierror.Internal(c, "Failed to get question", err)
+		return nil
+	}
+	if question == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeQuestionNotFound))
+		return nil
+	}
+
+	return question
+}
+
+// UpdateCatechismQuestion edits the text of a question, recording a new revision
+func (h *AdminHandler) UpdateCatechismQuestion(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	question := h.getQuestionFromParam(c)
+	if question == nil {
+		return
+	}
+
+	var req UpdateCatechismQuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+		return
+	}
+
+	req.QuestionText = strings.TrimSpace(req.QuestionText)
+	req.AnswerText = strings.TrimSpace(req.AnswerText)
+	if req.QuestionText == "" || req.AnswerText == "" {
+		apierror.Abort(c, apierror.New(apierror.CodeQuestionTextRequired))
+		return
+	}
+
+	question.QuestionText = req.QuestionText
+	question.AnswerText = req.AnswerText
+	if err := h.catechismRepo.Save(c.Request.Context(), question, &userID); err != nil {
+		apierror.Internal(c, "Failed to save question", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
+
+func (h *AdminHandler) GetCatechismRevisions(c *gin.Context) {
+	question := h.getQuestionFromParam(c)
+	if question == nil {
+		return
+	}
+
+	revisions, err := h.catechismRepo.GetRevisions(c.Request.Context(), question.ID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get revisions", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// DiffCatechismRevisions compares two revisions of a question word by word.
+// "to" defaults to the current revision and "from" to the one before it.
+func (h *AdminHandler) DiffCatechismRevisions(c *gin.Context) {
+	question := h.getQuestionFromParam(c)
+	if question == nil {
+		return
+	}
+
+	to := question.Revision
+	if value := c.Query("to"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidRevision, "to"))
+			return
+		}
+		to = parsed
+	}
+
+	from := to - 1
+	if value := c.Query("from"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidRevision, "from"))
+			return
+		}
+		from = parsed
+	}
+
+	fromRevision, err := h.catechismRepo.GetRevision(c.Request.Context(), question.ID, from)
+	if err != nil {
+		apierror.Internal(c, "Failed to get revision", err)
+		return
+	}
+	toRevision, err := h.catechismRepo.GetRevision(c.Request.Context(), question.ID, to)
+	if err != nil {
+		apierror.Internal(c, "Failed to get revision", err)
+		return
+	}
+	if fromRevision == nil || toRevision == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeRevisionNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, CatechismRevisionDiffResponse{
+		QuestionNumber: question.QuestionNumber,
+		From:           fromRevision,
+		To:             toRevision,
+		QuestionDiff:   textdiff.Words(fromRevision.QuestionText, toRevision.QuestionText),
+		AnswerDiff:     textdiff.Words(fromRevision.AnswerText, toRevision.AnswerText),
+	})
+}
+
+// RevertCatechismQuestion restores the text of an earlier revision. The revert
+// itself is recorded as a new revision, so history is never rewritten.
+func (h *AdminHandler) RevertCatechismQuestion(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	question := h.getQuestionFromParam(c)
+	if question == nil {
+		return
+	}
+
+	revisionNumber, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRevision, "revision"))
+		return
+	}
+
+	revision, err := h.catechismRepo.GetRevision(c.Request.Context(), question.ID, revisionNumber)
+	if err != nil {
+		apierror.Internal(c, "Failed to get revision", err)
+		return
+	}
+	if revision == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeRevisionNotFound))
+		return
+	}
+
+	question.QuestionText = revision.QuestionText
+	question.AnswerText = revision.AnswerText
+	if err := h.catechismRepo.Save(c.Request.Context(), question, &userID); err != nil {
+		apierror.Internal(c, "Failed to revert question", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, question)
+}
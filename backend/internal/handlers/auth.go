@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/metrics"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
 	"biblia-am-pm/internal/repository"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,12 +15,14 @@ import (
 )
 
 type AuthHandler struct {
-	userRepo *repository.UserRepository
+	userRepo  repository.UserRepository
+	jwtSecret string
 }
 
-func NewAuthHandler() *AuthHandler {
+func NewAuthHandler(userRepo repository.UserRepository, jwtSecret string) *AuthHandler {
 	return &AuthHandler{
-		userRepo: repository.NewUserRepository(),
+		userRepo:  userRepo,
+		jwtSecret: jwtSecret,
 	}
 }
 
@@ -31,53 +36,57 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type SetLocaleRequest struct {
+	Locale string `json:"locale"` // "pt-BR", "en" or "" to follow Accept-Language
+}
+
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  interface{} `json:"user"`
+	Token string       `json:"token"`
+	User  *models.User `json:"user"`
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
 		return
 	}
 
 	if req.Email == "" || req.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email and password are required"})
+		apierror.Abort(c, apierror.New(apierror.CodeCredentialsRequired))
 		return
 	}
 
 	// Check if user already exists
-	existingUser, err := h.userRepo.GetUserByEmail(req.Email)
+	existingUser, err := h.userRepo.GetUserByEmail(c.Request.Context(), req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check user"})
+		apierror.Internal(c, "Failed to check user", err)
 		return
 	}
 
 	if existingUser != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "User already exists"})
+		apierror.Abort(c, apierror.New(apierror.CodeUserAlreadyExists))
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierror.Internal(c, "Failed to hash password", err)
 		return
 	}
 
 	// Create user
-	user, err := h.userRepo.CreateUser(req.Email, string(hashedPassword))
+	user, err := h.userRepo.CreateUser(c.Request.Context(), req.Email, string(hashedPassword))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		apierror.Internal(c, "Failed to create user", err)
 		return
 	}
 
 	// Generate JWT token
-	token, err := generateToken(user.ID)
+	token, err := h.generateToken(user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Internal(c, "Failed to generate token", err)
 		return
 	}
 
@@ -90,41 +99,45 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
 		return
 	}
 
 	if req.Email == "" || req.Password == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email and password are required"})
+		apierror.Abort(c, apierror.New(apierror.CodeCredentialsRequired))
 		return
 	}
 
 	// Get user by email
-	user, err := h.userRepo.GetUserByEmail(req.Email)
+	user, err := h.userRepo.GetUserByEmail(c.Request.Context(), req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user"})
+		apierror.Internal(c, "Failed to get user", err)
 		return
 	}
 
 	if user == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		metrics.RecordLogin(false)
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidCredentials))
 		return
 	}
 
 	// Check password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		metrics.RecordLogin(false)
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidCredentials))
 		return
 	}
 
 	// Generate JWT token
-	token, err := generateToken(user.ID)
+	token, err := h.generateToken(user.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Internal(c, "Failed to generate token", err)
 		return
 	}
 
+	metrics.RecordLogin(true)
+
 	// Remove password from response
 	user.Password = ""
 
@@ -134,18 +147,44 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
-func generateToken(userID int) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-secret-key"
+// SetLocale saves the language of the user's API messages
+func (h *AuthHandler) SetLocale(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	var req SetLocaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+		return
+	}
+
+	var locale apierror.Locale
+	if req.Locale != "" {
+		var ok bool
+		if locale, ok = apierror.ParseLocale(req.Locale); !ok {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidLocale))
+			return
+		}
+	}
+
+	if err := h.userRepo.SetLocale(c.Request.Context(), userID, string(locale)); err != nil {
+		apierror.Internal(c, "Failed to save locale", err)
+		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{"locale": locale})
+}
+
+func (h *AuthHandler) generateToken(userID int) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
 		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 days
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return token.SignedString([]byte(h.jwtSecret))
 }
 
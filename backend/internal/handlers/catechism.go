@@ -1,15 +1,16 @@
 package handlers
 
 import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/metrics"
 	"biblia-am-pm/internal/middleware"
 	"biblia-am-pm/internal/models"
 	"biblia-am-pm/internal/repository"
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
+	"biblia-am-pm/internal/schedule"
+	"context"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,129 +18,211 @@ import (
 )
 
 type CatechismHandler struct {
-	catechismRepo        *repository.CatechismRepository
-	catechismProgressRepo *repository.CatechismProgressRepository
+	catechismRepo        repository.CatechismRepository
+	catechismProgressRepo repository.CatechismProgressRepository
+	progressEventRepo     repository.ProgressEventRepository
+	catechismQuizRepo     repository.CatechismQuizRepository
+	catechismSectionRepo  repository.CatechismSectionRepository
+	confessionRepo        repository.ConfessionRepository
+	userRepo              repository.UserRepository
+	location              *time.Location
 }
 
-func NewCatechismHandler() *CatechismHandler {
+func NewCatechismHandler(
+	catechismRepo repository.CatechismRepository,
+	catechismProgressRepo repository.CatechismProgressRepository,
+	progressEventRepo repository.ProgressEventRepository,
+	catechismQuizRepo repository.CatechismQuizRepository,
+	catechismSectionRepo repository.CatechismSectionRepository,
+	confessionRepo repository.ConfessionRepository,
+	userRepo repository.UserRepository,
+	location *time.Location,
+) *CatechismHandler {
 	return &CatechismHandler{
-		catechismRepo:         repository.NewCatechismRepository(),
-		catechismProgressRepo: repository.NewCatechismProgressRepository(),
+		catechismRepo:         catechismRepo,
+		catechismProgressRepo: catechismProgressRepo,
+		progressEventRepo:     progressEventRepo,
+		catechismQuizRepo:     catechismQuizRepo,
+		catechismSectionRepo:  catechismSectionRepo,
+		confessionRepo:        confessionRepo,
+		userRepo:              userRepo,
+		location:              location,
 	}
 }
 
-// getLocalTime returns the current time in the configured timezone
-func getLocalTimeForCatechism() time.Time {
-	tz := os.Getenv("TZ")
-	if tz == "" {
-		tz = "America/Sao_Paulo" // Default timezone
+// CatechismDayStep is the step expected on a day of the week and whether it was done
+type CatechismDayStep struct {
+	Date        string     `json:"date"`
+	Weekday     string     `json:"weekday"`
+	Step        string     `json:"step"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// scheduledStep returns the step expected on the given date for a mode
+func scheduledStep(mode string, date time.Time) string {
+	if mode == models.CatechismModeDaily {
+		return models.DailyCatechismSchedule[date.Weekday()]
 	}
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
-		loc = time.UTC
+	return models.CatechismStepReview
+}
+
+// buildWeekSchedule lists the seven days of the week with their step and
+// whether the user completed it
+func buildWeekSchedule(mode string, weekStart time.Time, weekProgress []*models.CatechismProgress) []*CatechismDayStep {
+	schedule := make([]*CatechismDayStep, 0, 7)
+	for i := 0; i < 7; i++ {
+		date := weekStart.AddDate(0, 0, i)
+		day := &CatechismDayStep{
+			Date:    date.Format("2006-01-02"),
+			Weekday: date.Weekday().String(),
+			Step:    scheduledStep(mode, date),
+		}
+		for _, progress := range weekProgress {
+			if progress.Date.Format("2006-01-02") == day.Date && progress.Step == day.Step && progress.Completed {
+				day.Completed = true
+				day.CompletedAt = progress.CompletedAt
+			}
+		}
+		schedule = append(schedule, day)
 	}
-	now := time.Now().In(loc)
-	return now
+	return schedule
 }
 
-// getWeekStart returns the Sunday of the current week
-func getWeekStart(date time.Time) time.Time {
-	weekday := int(date.Weekday())
-	// Sunday is 0, so we need to handle it
-	if weekday == 0 {
-		// It's already Sunday
-		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	}
-	// Calculate days to subtract to get to Sunday
-	daysToSubtract := weekday
-	return date.AddDate(0, 0, -daysToSubtract)
+// answerFirstHalf returns roughly the first half of an answer, cut at the
+// punctuation mark closest to the middle when there is one
+func answerFirstHalf(answer string) string {
+	words := strings.Fields(answer)
+	if len(words) < 2 {
+		return answer
+	}
+
+	middle := len(words) / 2
+	cut := middle
+	for distance := 0; distance <= middle/2; distance++ {
+		if i := middle - 1 - distance; i >= 0 && strings.ContainsAny(words[i], ",;:.") {
+			cut = i + 1
+			break
+		}
+		if i := middle - 1 + distance; i < len(words)-1 && strings.ContainsAny(words[i], ",;:.") {
+			cut = i + 1
+			break
+		}
+	}
+
+	return strings.TrimRight(strings.Join(words[:cut], " "), ",;:")
 }
 
-// getCurrentQuestionNumber calculates which question should be active this week
-// Based on the number of weeks since a reference date (first Sunday of 2024)
-func getCurrentQuestionNumber(now time.Time, totalQuestions int) int {
-	// Reference date: First Sunday of 2024 (January 7, 2024)
-	referenceDate := time.Date(2024, 1, 7, 0, 0, 0, 0, now.Location())
-	
-	// Get the Sunday of the current week
-	currentWeekStart := getWeekStart(now)
-	
-	// Calculate weeks since reference
-	weeksSinceReference := int(currentWeekStart.Sub(referenceDate).Hours() / 24 / 7)
-	
-	// Calculate question number (1-totalQuestions, cycling)
-	questionNumber := (weeksSinceReference % totalQuestions) + 1
-	
-	// Ensure it's between 1 and totalQuestions
-	if questionNumber < 1 {
-		questionNumber = totalQuestions
+// getUserCatechism returns the catechism the user follows, defaulting to the
+// Shorter Catechism
+func getUserCatechism(ctx context.Context, userRepo repository.UserRepository, userID int) (string, error) {
+	user, err := userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
 	}
-	if questionNumber > totalQuestions {
-		questionNumber = 1
+	if user == nil || user.Catechism == "" {
+		return models.CatechismShorter, nil
 	}
-	
-	return questionNumber
+	return user.Catechism, nil
+}
+
+// getUserCatechismMode returns the user's catechism mode, defaulting to weekly
+func getUserCatechismMode(ctx context.Context, userRepo repository.UserRepository, userID int) (string, error) {
+	user, err := userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil || user.CatechismMode == "" {
+		return models.CatechismModeWeekly, nil
+	}
+	return user.CatechismMode, nil
 }
 
 type CurrentQuestionResponse struct {
 	Question        *models.CatechismQuestion `json:"question"`
+	Mode            string                     `json:"mode"`
+	AnswerFirstHalf string                     `json:"answer_first_half"`
+	Schedule        []*CatechismDayStep        `json:"schedule"`
 	WeekProgress    []*models.CatechismProgress `json:"week_progress"`
 	WeekStart       string                     `json:"week_start"`
 	WeekEnd         string                     `json:"week_end"`
 	NextQuestionDate string                    `json:"next_question_date"`
 	QuestionNumber  int                        `json:"question_number"`
 	TotalQuestions  int                        `json:"total_questions"`
+	Confession      []*ConfessionLink          `json:"confession"`
 }
 
 func (h *CatechismHandler) GetCurrentQuestion(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	catechism, err := getUserCatechism(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism", err)
 		return
 	}
 
 	// Get total number of questions from database
-	totalQuestions, err := h.catechismRepo.GetMaxQuestionNumber()
-	if err != nil || totalQuestions == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total questions. Please populate the catechism first."})
+	totalQuestions, err := h.catechismRepo.GetMaxQuestionNumber(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get total questions", err)
+		return
+	}
+	if totalQuestions == 0 {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismNotPopulated))
 		return
 	}
 
-	now := getLocalTimeForCatechism()
-	questionNumber := getCurrentQuestionNumber(now, totalQuestions)
+	now := localTime(h.location)
+	questionNumber := schedule.QuestionNumber(now, totalQuestions)
 	
 	// Get the question
-	question, err := h.catechismRepo.GetByQuestionNumber(questionNumber)
+	question, err := h.catechismRepo.GetByQuestionNumber(c.Request.Context(), catechism, questionNumber)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get question"})
+		apierror.Internal(c, "Failed to get question", err)
 		return
 	}
 	
 	if question == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found. Please populate the catechism first."})
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismNotPopulated))
 		return
 	}
 	
 	// Get week start (Sunday)
-	weekStart := getWeekStart(now)
+	weekStart := schedule.WeekStart(now)
 	weekEnd := weekStart.AddDate(0, 0, 6)
 	nextQuestionDate := weekStart.AddDate(0, 0, 7)
 	
 	// Get progress for this week
-	weekProgress, err := h.catechismProgressRepo.GetByUserAndQuestionForWeek(userID, question.ID, weekStart)
+	weekProgress, err := h.catechismProgressRepo.GetByUserAndQuestionForWeek(c.Request.Context(), userID, question.ID, weekStart)
 	if err != nil {
-		log.Printf("Error getting week progress: %v", err)
+		logging.FromContext(c.Request.Context()).Warn("failed to get week progress", "error", err)
+	}
+	if weekProgress == nil {
 		weekProgress = []*models.CatechismProgress{}
 	}
+
+	mode, err := getUserCatechismMode(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism mode", err)
+		return
+	}
 	
 	response := CurrentQuestionResponse{
 		Question:         question,
+		Mode:             mode,
+		AnswerFirstHalf:  answerFirstHalf(question.AnswerText),
+		Schedule:         buildWeekSchedule(mode, weekStart, weekProgress),
 		WeekProgress:     weekProgress,
 		WeekStart:        weekStart.Format("2006-01-02"),
 		WeekEnd:          weekEnd.Format("2006-01-02"),
 		NextQuestionDate: nextQuestionDate.Format("2006-01-02"),
 		QuestionNumber:   questionNumber,
 		TotalQuestions:   totalQuestions,
+		Confession:       h.confessionLinks(c, question),
 	}
 	
 	c.JSON(http.StatusOK, response)
@@ -147,35 +230,73 @@ func (h *CatechismHandler) GetCurrentQuestion(c *gin.Context) {
 
 type MarkCatechismCompletedRequest struct {
 	Date string `json:"date"` // Optional, defaults to today
+	Step string `json:"step"` // Optional, defaults to the step scheduled for the date
+}
+
+type SetCatechismModeRequest struct {
+	Mode string `json:"mode"` // "weekly" or "daily"
+}
+
+type SetCatechismRequest struct {
+	Catechism string `json:"catechism"` // "shorter" or "larger"
 }
 
 func (h *CatechismHandler) MarkAsCompleted(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
 
 	var req MarkCatechismCompletedRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+		return
+	}
+
+	h.recordCompletion(c, userID, models.ProgressActionMark, req.Date, req.Step)
+}
+
+// UnmarkCompleted undoes the completion of a step, by default the one
+// scheduled for today
+func (h *CatechismHandler) UnmarkCompleted(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	h.recordCompletion(c, userID, models.ProgressActionUnmark, c.Query("date"), c.Query("step"))
+}
+
+// recordCompletion marks or unmarks the step of the question scheduled for
+// date, today when empty, and answers with the step's progress. Without a
+// step, it is the one scheduled for the date in the user's mode.
+func (h *CatechismHandler) recordCompletion(c *gin.Context, userID int, action string, date string, step string) {
+	catechism, err := getUserCatechism(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism", err)
 		return
 	}
 
 	// Get total number of questions from database
-	totalQuestions, err := h.catechismRepo.GetMaxQuestionNumber()
-	if err != nil || totalQuestions == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total questions. Please populate the catechism first."})
+	totalQuestions, err := h.catechismRepo.GetMaxQuestionNumber(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get total questions", err)
+		return
+	}
+	if totalQuestions == 0 {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismNotPopulated))
 		return
 	}
 
-	now := getLocalTimeForCatechism()
+	now := localTime(h.location)
 	var targetDate time.Time
 	
-	if req.Date != "" {
-		parsedDate, err := time.Parse("2006-01-02", req.Date)
+	if date != "" {
+		parsedDate, err := time.Parse("2006-01-02", date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidDate))
 			return
 		}
 		targetDate = parsedDate
@@ -183,132 +304,168 @@ func (h *CatechismHandler) MarkAsCompleted(c *gin.Context) {
 		targetDate = now
 	}
 	
-	questionNumber := getCurrentQuestionNumber(targetDate, totalQuestions)
+	questionNumber := schedule.QuestionNumber(targetDate, totalQuestions)
 	
 	// Get the question
-	question, err := h.catechismRepo.GetByQuestionNumber(questionNumber)
+	question, err := h.catechismRepo.GetByQuestionNumber(c.Request.Context(), catechism, questionNumber)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get question"})
+		apierror.Internal(c, "Failed to get question", err)
 		return
 	}
 	
 	if question == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		apierror.Abort(c, apierror.New(apierror.CodeQuestionNotFound))
 		return
 	}
 	
-	// Get or create progress
-	progress, err := h.catechismProgressRepo.GetByUserAndDate(userID, question.ID, targetDate)
+	mode, err := getUserCatechismMode(c.Request.Context(), h.userRepo, userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get progress"})
+		apierror.Internal(c, "Failed to get catechism mode", err)
 		return
 	}
-	
-	if progress == nil {
-		progress = &models.CatechismProgress{
-			UserID:    userID,
-			QuestionID: question.ID,
-			Date:      targetDate,
-			Completed: false,
-		}
+
+	if step == "" {
+		step = scheduledStep(mode, targetDate)
 	}
-	
-	// Mark as completed
-	progress.Completed = true
-	
-	// Save progress
-	err = h.catechismProgressRepo.CreateOrUpdate(progress)
+	if !models.IsValidCatechismStep(mode, step) {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidStep, step, mode))
+		return
+	}
+
+	// Marking a step already completed keeps its earlier time
+	event := &models.ProgressEvent{
+		Type:       models.ProgressTypeCatechism,
+		Date:       targetDate,
+		QuestionID: question.ID,
+		Step:       step,
+		Action:     action,
+		OccurredAt: time.Now(),
+		Source:     progressSource(c),
+	}
+	if err := h.progressEventRepo.Record(c.Request.Context(), userID, []*models.ProgressEvent{event}); err != nil {
+		apierror.Internal(c, "Failed to save progress", err)
+		return
+	}
+	if event.Applied && action == models.ProgressActionMark {
+		metrics.RecordCatechismStepCompleted(step)
+	}
+
+	progress, err := h.catechismProgressRepo.GetByUserAndDate(c.Request.Context(), userID, question.ID, targetDate, step)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress"})
+		apierror.Internal(c, "Failed to get progress", err)
 		return
 	}
+	if progress == nil {
+		progress = &models.CatechismProgress{UserID: userID, QuestionID: question.ID, Date: targetDate, Step: step}
+	}
 	
 	c.JSON(http.StatusOK, progress)
 }
 
-func (h *CatechismHandler) GetProgress(c *gin.Context) {
+// SetMode switches the user between the weekly and daily catechism schedules
+func (h *CatechismHandler) SetMode(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
 
-	progresses, err := h.catechismProgressRepo.GetUserProgress(userID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get progress"})
+	var req SetCatechismModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
 		return
 	}
 
-	c.JSON(http.StatusOK, progresses)
-}
+	if !models.IsValidCatechismMode(req.Mode) {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidCatechismMode, models.CatechismModeWeekly, models.CatechismModeDaily))
+		return
+	}
+
+	if err := h.userRepo.SetCatechismMode(c.Request.Context(), userID, req.Mode); err != nil {
+		apierror.Internal(c, "Failed to save catechism mode", err)
+		return
+	}
 
-// Structure for parsing online catechism data
-type OnlineCatechismItem struct {
-	Number int    `json:"number"`
-	Q      string `json:"q"` // Question
-	A      string `json:"a"` // Answer
+	c.JSON(http.StatusOK, gin.H{"mode": req.Mode})
 }
 
-func (h *CatechismHandler) PopulateCatechism(c *gin.Context) {
-	// Try to fetch from a public API or source
-	// For now, we'll use a known source or allow manual population
-	// You can use https://www.westminsterconfession.org/resources/westminster-shorter-catechism.php
-	// or similar sources
-	
-	url := "https://raw.githubusercontent.com/ReformedWiki/westminster-shorter-catechism/master/data/catechism.json"
-	
-	resp, err := http.Get(url)
+// SetCatechism switches the catechism the user follows in the schedule
+func (h *CatechismHandler) SetCatechism(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch catechism: %v", err)})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to fetch catechism: %s", string(body))})
+
+	var req SetCatechismRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
 		return
 	}
-	
-	body, err := io.ReadAll(resp.Body)
+
+	if !models.IsValidCatechism(req.Catechism) {
+		apierror.Abort(c, apierror.New(apierror.CodeUnknownCatechism, models.CatechismShorter, models.CatechismLarger))
+		return
+	}
+
+	if err := h.userRepo.SetCatechism(c.Request.Context(), userID, req.Catechism); err != nil {
+		apierror.Internal(c, "Failed to save catechism", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"catechism": req.Catechism})
+}
+
+func (h *CatechismHandler) GetProgress(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read response: %v", err)})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
-	
-	var items []OnlineCatechismItem
-	if err := json.Unmarshal(body, &items); err != nil {
-		// Try alternative format or manual parsing
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to parse JSON: %v. You may need to populate manually.", err)})
+
+	progresses, err := h.catechismProgressRepo.GetUserProgress(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get progress", err)
 		return
 	}
-	
-	// Convert to our model
-	questions := make([]*models.CatechismQuestion, 0, len(items))
-	for _, item := range items {
-		if item.Number >= 1 && item.Number <= 107 {
-			questions = append(questions, &models.CatechismQuestion{
-				QuestionNumber: item.Number,
-				QuestionText:   strings.TrimSpace(item.Q),
-				AnswerText:     strings.TrimSpace(item.A),
-			})
-		}
+
+	c.JSON(http.StatusOK, progresses)
+}
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search runs a full-text search over question and answer texts
+func (h *CatechismHandler) Search(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	if len([]rune(text)) < 2 {
+		apierror.Abort(c, apierror.New(apierror.CodeSearchQueryTooShort))
+		return
 	}
-	
-	if len(questions) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "No valid questions found in the response"})
+
+	catechism := c.Query("catechism")
+	if catechism != "" && !models.IsValidCatechism(catechism) {
+		apierror.Abort(c, apierror.New(apierror.CodeUnknownCatechism, models.CatechismShorter, models.CatechismLarger))
 		return
 	}
-	
-	// Save to database
-	if err := h.catechismRepo.CreateBatch(questions); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save questions: %v", err)})
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidLimit, maxSearchLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.catechismRepo.Search(c.Request.Context(), text, catechism, limit)
+	if err != nil {
+		apierror.Internal(c, "Failed to search catechism", err)
 		return
 	}
-	
-	c.JSON(http.StatusOK, gin.H{
-		"message":   fmt.Sprintf("Successfully populated %d questions", len(questions)),
-		"count":     len(questions),
-	})
-}
 
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/schedule"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultQuestionsPerPage = 20
+	maxQuestionsPerPage     = 100
+	// reviewInterval is how long after its last completion a question is due for review
+	reviewInterval = 28 * 24 * time.Hour
+)
+
+type CatechismQuestionLink struct {
+	QuestionNumber int    `json:"question_number"`
+	Href           string `json:"href"`
+}
+
+type CatechismQuestionItem struct {
+	*models.CatechismQuestion
+	Section   *models.CatechismSection        `json:"section,omitempty"`
+	Progress  *models.CatechismQuestionStatus `json:"progress"`
+	IsCurrent bool                            `json:"is_current"`
+}
+
+type CatechismQuestionsResponse struct {
+	Questions  []*CatechismQuestionItem   `json:"questions"`
+	Page       int                        `json:"page"`
+	PerPage    int                        `json:"per_page"`
+	Total      int                        `json:"total"`
+	TotalPages int                        `json:"total_pages"`
+	Sections   []*models.CatechismSection `json:"sections"`
+}
+
+type CatechismQuestionResponse struct {
+	*CatechismQuestionItem
+	Confession []*ConfessionLink      `json:"confession"`
+	Previous   *CatechismQuestionLink `json:"previous"`
+	Next       *CatechismQuestionLink `json:"next"`
+}
+
+func newQuestionLink(questionNumber int) *CatechismQuestionLink {
+	if questionNumber == 0 {
+		return nil
+	}
+	return &CatechismQuestionLink{
+		QuestionNumber: questionNumber,
+		Href:           fmt.Sprintf("%s/catechism/questions/%d", V1Prefix, questionNumber),
+	}
+}
+
+// questionStatus fills in the status of a summary returned by the repository
+func questionStatus(status *models.CatechismQuestionStatus, now time.Time) *models.CatechismQuestionStatus {
+	if status == nil || status.TimesCompleted == 0 {
+		return &models.CatechismQuestionStatus{Status: models.QuestionStatusNotStarted}
+	}
+
+	status.Status = models.QuestionStatusCompleted
+	if status.LastCompletedAt != nil && now.Sub(*status.LastCompletedAt) > reviewInterval {
+		status.Status = models.QuestionStatusReviewDue
+	}
+	return status
+}
+
+func findSection(sections []*models.CatechismSection, questionNumber int) *models.CatechismSection {
+	for _, section := range sections {
+		if section.Contains(questionNumber) {
+			return section
+		}
+	}
+	return nil
+}
+
+// ListQuestions returns a page of questions merged with the user's progress.
+// It can be narrowed to a thematic section with ?section=slug.
+func (h *CatechismHandler) ListQuestions(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	page := 1
+	if value := c.Query("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidPage))
+			return
+		}
+	}
+
+	perPage := defaultQuestionsPerPage
+	if value := c.Query("per_page"); value != "" {
+		perPage, err = strconv.Atoi(value)
+		if err != nil || perPage < 1 || perPage > maxQuestionsPerPage {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidPerPage, maxQuestionsPerPage))
+			return
+		}
+	}
+
+	catechism, err := getUserCatechism(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism", err)
+		return
+	}
+
+	totalQuestions, err := h.catechismRepo.GetMaxQuestionNumber(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get total questions", err)
+		return
+	}
+	if totalQuestions == 0 {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismNotPopulated))
+		return
+	}
+
+	sections, err := h.catechismSectionRepo.GetByCatechism(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get sections", err)
+		return
+	}
+
+	start, end := 1, totalQuestions
+	if slug := c.Query("section"); slug != "" {
+		var section *models.CatechismSection
+		for _, s := range sections {
+			if s.Slug == slug {
+				section = s
+				break
+			}
+		}
+		if section == nil {
+			apierror.Abort(c, apierror.New(apierror.CodeSectionNotFound))
+			return
+		}
+		start, end = section.StartQuestion, section.EndQuestion
+	}
+
+	questions, total, err := h.catechismRepo.GetPage(c.Request.Context(), catechism, start, end, perPage, (page-1)*perPage)
+	if err != nil {
+		apierror.Internal(c, "Failed to get questions", err)
+		return
+	}
+
+	statuses, err := h.catechismProgressRepo.GetQuestionStatuses(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get progress", err)
+		return
+	}
+
+	now := localTime(h.location)
+	currentNumber := schedule.QuestionNumber(now, totalQuestions)
+
+	items := make([]*CatechismQuestionItem, 0, len(questions))
+	for _, question := range questions {
+		items = append(items, &CatechismQuestionItem{
+			CatechismQuestion: question,
+			Section:           findSection(sections, question.QuestionNumber),
+			Progress:          questionStatus(statuses[question.ID], now),
+			IsCurrent:         question.QuestionNumber == currentNumber,
+		})
+	}
+
+	c.JSON(http.StatusOK, CatechismQuestionsResponse{
+		Questions:  items,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+		Sections:   sections,
+	})
+}
+
+// GetQuestion returns a single question with links to its neighbours
+func (h *CatechismHandler) GetQuestion(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidQuestionNumber))
+		return
+	}
+
+	catechism, err := getUserCatechism(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism", err)
+		return
+	}
+
+	question, err := h.catechismRepo.GetByQuestionNumber(c.Request.Context(), catechism, number)
+	if err != nil {
+		apierror.Internal(c, "Failed to get question", err)
+		return
+	}
+	if question == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeQuestionNotFound))
+		return
+	}
+
+	totalQuestions, err := h.catechismRepo.GetMaxQuestionNumber(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get total questions", err)
+		return
+	}
+
+	previous, next, err := h.catechismRepo.GetAdjacentNumbers(c.Request.Context(), catechism, number)
+	if err != nil {
+		apierror.Internal(c, "Failed to get adjacent questions", err)
+		return
+	}
+
+	sections, err := h.catechismSectionRepo.GetByCatechism(c.Request.Context(), question.Catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get sections", err)
+		return
+	}
+
+	statuses, err := h.catechismProgressRepo.GetQuestionStatuses(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get progress", err)
+		return
+	}
+
+	now := localTime(h.location)
+	c.JSON(http.StatusOK, CatechismQuestionResponse{
+		CatechismQuestionItem: &CatechismQuestionItem{
+			CatechismQuestion: question,
+			Section:           findSection(sections, question.QuestionNumber),
+			Progress:          questionStatus(statuses[question.ID], now),
+			IsCurrent:         question.QuestionNumber == schedule.QuestionNumber(now, totalQuestions),
+		},
+		Confession: h.confessionLinks(c, question),
+		Previous:   newQuestionLink(previous),
+		Next:       newQuestionLink(next),
+	})
+}
+
+// GetSections returns the thematic sections index of a catechism. It defaults
+// to the catechism the user follows.
+func (h *CatechismHandler) GetSections(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	catechism := c.Query("catechism")
+	if catechism == "" {
+		catechism, err = getUserCatechism(c.Request.Context(), h.userRepo, userID)
+		if err != nil {
+			apierror.Internal(c, "Failed to get catechism", err)
+			return
+		}
+	}
+
+	if !models.IsValidCatechism(catechism) {
+		apierror.Abort(c, apierror.New(apierror.CodeUnknownCatechism, models.CatechismShorter, models.CatechismLarger))
+		return
+	}
+
+	sections, err := h.catechismSectionRepo.GetByCatechism(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get sections", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sections)
+}
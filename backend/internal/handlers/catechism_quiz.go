@@ -0,0 +1,542 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultQuizCount = 10
+	maxQuizCount     = 50
+	// recallPassScore is the fraction of key words a recall answer must contain
+	recallPassScore = 0.7
+	quizBlank       = "____"
+)
+
+var quizExerciseTypes = []string{
+	models.QuizExerciseRecall,
+	models.QuizExerciseMultipleChoice,
+	models.QuizExerciseFillBlank,
+	models.QuizExerciseQuestionNumber,
+}
+
+// quizStopWords are common Portuguese words that are never used as key words
+var quizStopWords = map[string]bool{
+	"aquele": true, "aquela": true, "aqueles": true, "aquelas": true, "como": true,
+	"deve": true, "entre": true, "essa": true, "esse": true, "esta": true, "este": true,
+	"isto": true, "mais": true, "nossa": true, "nosso": true, "onde": true, "para": true,
+	"pela": true, "pelas": true, "pelo": true, "pelos": true, "pois": true, "porque": true,
+	"qual": true, "quais": true, "quando": true, "sendo": true, "seus": true, "sobre": true,
+	"suas": true, "tambem": true, "toda": true, "todas": true, "todo": true, "todos": true,
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// QuizExerciseView is an exercise as sent to the client, without its solution
+type QuizExerciseView struct {
+	Index          int      `json:"index"`
+	Type           string   `json:"type"`
+	QuestionNumber int      `json:"question_number,omitempty"`
+	Prompt         string   `json:"prompt"`
+	Text           string   `json:"text,omitempty"`
+	Options        []string `json:"options,omitempty"`
+	BlankCount     int      `json:"blank_count,omitempty"`
+}
+
+type QuizResponse struct {
+	ID         int                `json:"id"`
+	RangeStart int                `json:"range_start"`
+	RangeEnd   int                `json:"range_end"`
+	Exercises  []QuizExerciseView `json:"exercises"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+type SubmitQuizRequest struct {
+	Answers []models.QuizAnswer `json:"answers"`
+}
+
+type QuizResultResponse struct {
+	ID           int                         `json:"id"`
+	Score        float64                     `json:"score"`
+	CorrectCount int                         `json:"correct_count"`
+	Total        int                         `json:"total"`
+	Results      []models.QuizExerciseResult `json:"results"`
+	SubmittedAt  *time.Time                  `json:"submitted_at"`
+}
+
+// parseQuizRange parses a "start-end" question range. An empty value covers
+// the whole catechism.
+func parseQuizRange(value string, totalQuestions int) (int, int, error) {
+	if value == "" {
+		return 1, totalQuestions, nil
+	}
+
+	parts := strings.Split(value, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid range")
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range start")
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range end")
+	}
+
+	if start < 1 || end < start || end > totalQuestions {
+		return 0, 0, fmt.Errorf("range must be within 1-%d", totalQuestions)
+	}
+
+	return start, end, nil
+}
+
+// normalizeQuizText lowercases, removes accents and punctuation so answers can
+// be compared regardless of how they were typed
+func normalizeQuizText(text string) string {
+	text = accentReplacer.Replace(strings.ToLower(text))
+	return strings.Join(strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// isQuizKeyWord reports whether a normalized word carries meaning on its own
+func isQuizKeyWord(word string) bool {
+	if len([]rune(word)) < 4 || quizStopWords[word] {
+		return false
+	}
+	for _, r := range word {
+		if unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// quizKeyWords returns the distinct key words of a text, in order
+func quizKeyWords(text string) []string {
+	seen := map[string]bool{}
+	var words []string
+	for _, word := range strings.Fields(normalizeQuizText(text)) {
+		if isQuizKeyWord(word) && !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// buildFillBlank removes a few key words from the answer. It returns the text
+// with blanks and the removed words in order.
+func buildFillBlank(answer string, rng *rand.Rand) (string, []string) {
+	tokens := strings.Fields(answer)
+
+	var candidates []int
+	for i, token := range tokens {
+		if isQuizKeyWord(normalizeQuizText(token)) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", nil
+	}
+
+	// Roughly one blank every eight words, between one and five blanks
+	blankCount := len(tokens) / 8
+	if blankCount < 1 {
+		blankCount = 1
+	}
+	if blankCount > 5 {
+		blankCount = 5
+	}
+	if blankCount > len(candidates) {
+		blankCount = len(candidates)
+	}
+
+	rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	selected := map[int]bool{}
+	for _, idx := range candidates[:blankCount] {
+		selected[idx] = true
+	}
+
+	var blanks []string
+	for i, token := range tokens {
+		if !selected[i] {
+			continue
+		}
+		word := strings.TrimFunc(token, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		blanks = append(blanks, word)
+		tokens[i] = strings.Replace(token, word, quizBlank, 1)
+	}
+
+	return strings.Join(tokens, " "), blanks
+}
+
+// buildMultipleChoice picks up to three distractor answers from other questions
+func buildMultipleChoice(question *models.CatechismQuestion, pool []*models.CatechismQuestion, rng *rand.Rand) ([]string, int) {
+	var distractors []string
+	seen := map[string]bool{normalizeQuizText(question.AnswerText): true}
+	for _, idx := range rng.Perm(len(pool)) {
+		candidate := pool[idx]
+		key := normalizeQuizText(candidate.AnswerText)
+		if candidate.QuestionNumber == question.QuestionNumber || seen[key] {
+			continue
+		}
+		seen[key] = true
+		distractors = append(distractors, candidate.AnswerText)
+		if len(distractors) == 3 {
+			break
+		}
+	}
+	if len(distractors) == 0 {
+		return nil, 0
+	}
+
+	options := append(distractors, question.AnswerText)
+	rng.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+	for i, option := range options {
+		if option == question.AnswerText {
+			return options, i
+		}
+	}
+	return options, 0
+}
+
+// generateQuizExercises builds count exercises of mixed types from the given
+// questions, each about a different question, so a range with fewer questions
+// gives fewer exercises. Distractors for multiple choice are drawn from pool.
+func generateQuizExercises(questions, pool []*models.CatechismQuestion, count int, rng *rand.Rand) []models.QuizExercise {
+	if count > len(questions) {
+		count = len(questions)
+	}
+	order := rng.Perm(len(questions))
+	exercises := make([]models.QuizExercise, 0, count)
+
+	for i := 0; i < count; i++ {
+		question := questions[order[i]]
+		exercise := models.QuizExercise{
+			Type:           quizExerciseTypes[rng.Intn(len(quizExerciseTypes))],
+			QuestionNumber: question.QuestionNumber,
+			Prompt:         question.QuestionText,
+			Answer:         question.AnswerText,
+		}
+
+		switch exercise.Type {
+		case models.QuizExerciseMultipleChoice:
+			exercise.Options, exercise.CorrectOption = buildMultipleChoice(question, pool, rng)
+			if exercise.Options == nil {
+				exercise.Type = models.QuizExerciseRecall
+			}
+		case models.QuizExerciseFillBlank:
+			exercise.Text, exercise.Blanks = buildFillBlank(question.AnswerText, rng)
+			if exercise.Blanks == nil {
+				exercise.Type = models.QuizExerciseRecall
+			}
+		}
+
+		exercises = append(exercises, exercise)
+	}
+
+	return exercises
+}
+
+// gradeQuizExercise grades a single answer and returns a score between 0 and 1
+func gradeQuizExercise(exercise models.QuizExercise, answer *models.QuizAnswer) float64 {
+	if answer == nil {
+		return 0
+	}
+
+	switch exercise.Type {
+	case models.QuizExerciseMultipleChoice:
+		if answer.Option != nil && *answer.Option == exercise.CorrectOption {
+			return 1
+		}
+		return 0
+	case models.QuizExerciseQuestionNumber:
+		if answer.Number != nil && *answer.Number == exercise.QuestionNumber {
+			return 1
+		}
+		return 0
+	case models.QuizExerciseFillBlank:
+		if len(exercise.Blanks) == 0 {
+			return 0
+		}
+		correct := 0
+		for i, blank := range exercise.Blanks {
+			if i < len(answer.Blanks) && normalizeQuizText(answer.Blanks[i]) == normalizeQuizText(blank) {
+				correct++
+			}
+		}
+		return float64(correct) / float64(len(exercise.Blanks))
+	default:
+		expected := quizKeyWords(exercise.Answer)
+		if len(expected) == 0 {
+			if normalizeQuizText(answer.Text) == normalizeQuizText(exercise.Answer) {
+				return 1
+			}
+			return 0
+		}
+		given := map[string]bool{}
+		for _, word := range quizKeyWords(answer.Text) {
+			given[word] = true
+		}
+		matched := 0
+		for _, word := range expected {
+			if given[word] {
+				matched++
+			}
+		}
+		return float64(matched) / float64(len(expected))
+	}
+}
+
+func quizCorrectAnswer(exercise models.QuizExercise) string {
+	switch exercise.Type {
+	case models.QuizExerciseQuestionNumber:
+		return strconv.Itoa(exercise.QuestionNumber)
+	case models.QuizExerciseFillBlank:
+		return strings.Join(exercise.Blanks, ", ")
+	default:
+		return exercise.Answer
+	}
+}
+
+func isQuizExerciseCorrect(exercise models.QuizExercise, score float64) bool {
+	if exercise.Type == models.QuizExerciseRecall {
+		return score >= recallPassScore
+	}
+	return score == 1
+}
+
+func newQuizResponse(quiz *models.CatechismQuiz) QuizResponse {
+	views := make([]QuizExerciseView, 0, len(quiz.Exercises))
+	for i, exercise := range quiz.Exercises {
+		view := QuizExerciseView{
+			Index:          i,
+			Type:           exercise.Type,
+			QuestionNumber: exercise.QuestionNumber,
+			Prompt:         exercise.Prompt,
+			Text:           exercise.Text,
+			Options:        exercise.Options,
+			BlankCount:     len(exercise.Blanks),
+		}
+		// The number is the answer for this exercise type
+		if exercise.Type == models.QuizExerciseQuestionNumber {
+			view.QuestionNumber = 0
+		}
+		views = append(views, view)
+	}
+
+	return QuizResponse{
+		ID:         quiz.ID,
+		RangeStart: quiz.RangeStart,
+		RangeEnd:   quiz.RangeEnd,
+		Exercises:  views,
+		CreatedAt:  quiz.CreatedAt,
+	}
+}
+
+func newQuizResultResponse(quiz *models.CatechismQuiz) QuizResultResponse {
+	return QuizResultResponse{
+		ID:           quiz.ID,
+		Score:        quiz.Score,
+		CorrectCount: quiz.CorrectCount,
+		Total:        len(quiz.Exercises),
+		Results:      quiz.Results,
+		SubmittedAt:  quiz.SubmittedAt,
+	}
+}
+
+func (h *CatechismHandler) GetQuiz(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	count := defaultQuizCount
+	if value := c.Query("count"); value != "" {
+		count, err = strconv.Atoi(value)
+		if err != nil || count < 1 || count > maxQuizCount {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidQuizCount, maxQuizCount))
+			return
+		}
+	}
+
+	catechism, err := getUserCatechism(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism", err)
+		return
+	}
+
+	totalQuestions, err := h.catechismRepo.GetMaxQuestionNumber(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get total questions", err)
+		return
+	}
+	if totalQuestions == 0 {
+		apierror.Abort(c, apierror.New(apierror.CodeCatechismNotPopulated))
+		return
+	}
+
+	rangeStart, rangeEnd, err := parseQuizRange(c.Query("range"), totalQuestions)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRange, err))
+		return
+	}
+
+	questions, err := h.catechismRepo.GetByQuestionRange(c.Request.Context(), catechism, rangeStart, rangeEnd)
+	if err != nil {
+		apierror.Internal(c, "Failed to get questions", err)
+		return
+	}
+	if len(questions) == 0 {
+		apierror.Abort(c, apierror.New(apierror.CodeNoQuestionsInRange))
+		return
+	}
+
+	pool, err := h.catechismRepo.GetAll(c.Request.Context(), catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get questions", err)
+		return
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	quiz := &models.CatechismQuiz{
+		UserID:     userID,
+		RangeStart: rangeStart,
+		RangeEnd:   rangeEnd,
+		Exercises:  generateQuizExercises(questions, pool, count, rng),
+	}
+
+	if err := h.catechismQuizRepo.Create(c.Request.Context(), quiz); err != nil {
+		apierror.Internal(c, "Failed to save quiz", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newQuizResponse(quiz))
+}
+
+func (h *CatechismHandler) SubmitQuiz(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	quizID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidQuizID))
+		return
+	}
+
+	var req SubmitQuizRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+		return
+	}
+
+	quiz, err := h.catechismQuizRepo.GetByIDAndUser(c.Request.Context(), quizID, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get quiz", err)
+		return
+	}
+	if quiz == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeQuizNotFound))
+		return
+	}
+	if quiz.SubmittedAt != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeQuizAlreadySubmitted))
+		return
+	}
+
+	answers := map[int]*models.QuizAnswer{}
+	for i := range req.Answers {
+		answer := &req.Answers[i]
+		if answer.Exercise < 0 || answer.Exercise >= len(quiz.Exercises) {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidExercise, answer.Exercise))
+			return
+		}
+		answers[answer.Exercise] = answer
+	}
+
+	results := make([]models.QuizExerciseResult, 0, len(quiz.Exercises))
+	totalScore := 0.0
+	correctCount := 0
+	for i, exercise := range quiz.Exercises {
+		score := gradeQuizExercise(exercise, answers[i])
+		correct := isQuizExerciseCorrect(exercise, score)
+		if correct {
+			correctCount++
+		}
+		totalScore += score
+		results = append(results, models.QuizExerciseResult{
+			Exercise:      i,
+			Correct:       correct,
+			Score:         score,
+			CorrectAnswer: quizCorrectAnswer(exercise),
+		})
+	}
+
+	quiz.Answers = req.Answers
+	quiz.Results = results
+	quiz.CorrectCount = correctCount
+	quiz.Score = totalScore / float64(len(quiz.Exercises))
+
+	saved, err := h.catechismQuizRepo.SaveResult(c.Request.Context(), quiz)
+	if err != nil {
+		apierror.Internal(c, "Failed to save quiz result", err)
+		return
+	}
+	if !saved {
+		apierror.Abort(c, apierror.New(apierror.CodeQuizAlreadySubmitted))
+		return
+	}
+
+	c.JSON(http.StatusOK, newQuizResultResponse(quiz))
+}
+
+func (h *CatechismHandler) GetQuizResults(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	quizzes, err := h.catechismQuizRepo.GetSubmittedByUser(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get quiz results", err)
+		return
+	}
+
+	results := make([]QuizResultResponse, 0, len(quizzes))
+	for _, quiz := range quizzes {
+		results = append(results, newQuizResultResponse(quiz))
+	}
+
+	c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,235 @@
+package handlers_test
+
+import (
+	"biblia-am-pm/internal/handlers"
+	"biblia-am-pm/internal/models"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParseQuizRange(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{"", 1, 107, false},
+		{"1-10", 1, 10, false},
+		{" 3 - 5 ", 3, 5, false},
+		{"7-7", 7, 7, false},
+		{"100-107", 100, 107, false},
+		{"10-1", 0, 0, true},
+		{"0-5", 0, 0, true},
+		{"1-108", 0, 0, true},
+		{"5", 0, 0, true},
+		{"1-2-3", 0, 0, true},
+		{"-5", 0, 0, true},
+		{"1-", 0, 0, true},
+		{"a-b", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, err := handlers.ParseQuizRange(tt.value, 107)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %d-%d, want an error", start, end)
+				}
+				return
+			}
+			if err != nil || start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("got %d-%d, %v, want %d-%d", start, end, err, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestGradeRecall(t *testing.T) {
+	// Ten key words, so each one is worth a tenth of the score
+	exercise := models.QuizExercise{
+		Type:   models.QuizExerciseRecall,
+		Answer: "Deus criou céus, terra, mares, plantas, animais, homem e mulher, e fez o descanso.",
+	}
+
+	tests := []struct {
+		name        string
+		answer      *models.QuizAnswer
+		wantScore   float64
+		wantCorrect bool
+	}{
+		{"no answer", nil, 0, false},
+		{"empty", &models.QuizAnswer{}, 0, false},
+		{"every key word", &models.QuizAnswer{Text: exercise.Answer}, 1, true},
+		{"without accents or punctuation", &models.QuizAnswer{Text: "deus criou ceus terra mares plantas animais homem mulher descanso"}, 1, true},
+		{"seven key words", &models.QuizAnswer{Text: "Deus criou os céus, a terra, os mares, as plantas e os animais"}, 0.7, true},
+		{"seven key words in another order", &models.QuizAnswer{Text: "animais plantas mares terra céus criou Deus"}, 0.7, true},
+		{"six key words", &models.QuizAnswer{Text: "Deus criou os céus, a terra, os mares e as plantas"}, 0.6, false},
+		{"repeated key words count once", &models.QuizAnswer{Text: "Deus Deus Deus Deus Deus Deus Deus criou"}, 0.2, false},
+		{"only short and common words", &models.QuizAnswer{Text: "e o a para todos"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := handlers.GradeQuizExercise(exercise, tt.answer)
+			if score != tt.wantScore {
+				t.Errorf("score = %v, want %v", score, tt.wantScore)
+			}
+			if correct := handlers.IsQuizExerciseCorrect(exercise, score); correct != tt.wantCorrect {
+				t.Errorf("correct = %v, want %v", correct, tt.wantCorrect)
+			}
+		})
+	}
+}
+
+func TestGradeRecallWithoutKeyWords(t *testing.T) {
+	exercise := models.QuizExercise{Type: models.QuizExerciseRecall, Answer: "Sim."}
+
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"Sim.", 1},
+		{"sim", 1},
+		{"Não.", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := handlers.GradeQuizExercise(exercise, &models.QuizAnswer{Text: tt.text}); got != tt.want {
+			t.Errorf("grading %q = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBuildFillBlank(t *testing.T) {
+	tests := []struct {
+		name       string
+		answer     string
+		wantBlanks int
+	}{
+		{"no key words", "Sim.", 0},
+		{"only short and common words", "Para todos.", 0},
+		{"a single word", "Deus.", 1},
+		{"a single key word", "Há um só Deus.", 1},
+		{"short answer", "Pela palavra de Deus.", 1},
+		{"two blanks from sixteen words", "Deus executa os seus decretos nas obras da criação e da providência, conforme o seu conselho.", 2},
+		{"at most five blanks", strings.Repeat("Deus governa todas as criaturas e todas as suas ações. ", 6), 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for seed := int64(1); seed <= 20; seed++ {
+				text, blanks := handlers.BuildFillBlank(tt.answer, rand.New(rand.NewSource(seed)))
+				if len(blanks) != tt.wantBlanks {
+					t.Fatalf("seed %d: got blanks %q, want %d", seed, blanks, tt.wantBlanks)
+				}
+				if tt.wantBlanks == 0 {
+					if text != "" {
+						t.Errorf("seed %d: got text %q, want none", seed, text)
+					}
+					continue
+				}
+				if got := strings.Count(text, handlers.QuizBlank); got != len(blanks) {
+					t.Errorf("seed %d: %q has %d blanks, want %d", seed, text, got, len(blanks))
+				}
+
+				// Filling the blanks in order gives back the answer
+				filled := text
+				for _, blank := range blanks {
+					filled = strings.Replace(filled, handlers.QuizBlank, blank, 1)
+				}
+				if filled != strings.Join(strings.Fields(tt.answer), " ") {
+					t.Errorf("seed %d: filling %q with %q gives %q", seed, text, blanks, filled)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildMultipleChoice(t *testing.T) {
+	question := &models.CatechismQuestion{QuestionNumber: 4, AnswerText: "Deus é espírito."}
+
+	tests := []struct {
+		name        string
+		others      []string
+		wantOptions int
+	}{
+		{"three distinct answers", []string{"A palavra de Deus.", "Há um só Deus.", "Três pessoas."}, 4},
+		{"more answers than needed", []string{"A palavra de Deus.", "Há um só Deus.", "Três pessoas.", "Seus decretos."}, 4},
+		{"answers differing only in case, accents and punctuation", []string{"A palavra de Deus.", "a palavra de deus", "A PALAVRA DE DEUS!", "Há um só Deus."}, 3},
+		{"another question with the same answer", []string{"Deus e espirito", "Há um só Deus."}, 2},
+		{"no other answer", []string{"Deus é espírito!"}, 0},
+		{"empty pool", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := []*models.CatechismQuestion{question}
+			for i, answer := range tt.others {
+				pool = append(pool, &models.CatechismQuestion{QuestionNumber: 10 + i, AnswerText: answer})
+			}
+
+			for seed := int64(1); seed <= 20; seed++ {
+				options, correct := handlers.BuildMultipleChoice(question, pool, rand.New(rand.NewSource(seed)))
+				if len(options) != tt.wantOptions {
+					t.Fatalf("seed %d: got options %q, want %d", seed, options, tt.wantOptions)
+				}
+				if tt.wantOptions == 0 {
+					continue
+				}
+				if options[correct] != question.AnswerText {
+					t.Errorf("seed %d: option %d is %q, want the answer", seed, correct, options[correct])
+				}
+				seen := map[string]bool{}
+				for _, option := range options {
+					key := strings.ToLower(strings.Trim(option, ".!"))
+					if seen[key] {
+						t.Errorf("seed %d: option %q repeated in %q", seed, option, options)
+					}
+					seen[key] = true
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateQuizExercises(t *testing.T) {
+	tests := []struct {
+		questions int
+		count     int
+		want      int
+	}{
+		{10, 4, 4},
+		{5, 5, 5},
+		{3, 10, 3},
+		{1, 50, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d questions, count %d", tt.questions, tt.count), func(t *testing.T) {
+			var questions []*models.CatechismQuestion
+			for i := 1; i <= tt.questions; i++ {
+				questions = append(questions, &models.CatechismQuestion{
+					QuestionNumber: i,
+					QuestionText:   fmt.Sprintf("Pergunta %d?", i),
+					AnswerText:     fmt.Sprintf("Resposta número %d sobre Deus.", i),
+				})
+			}
+
+			exercises := handlers.GenerateQuizExercises(questions, questions, tt.count, rand.New(rand.NewSource(1)))
+			if len(exercises) != tt.want {
+				t.Fatalf("got %d exercises, want %d", len(exercises), tt.want)
+			}
+			seen := map[int]bool{}
+			for _, exercise := range exercises {
+				if seen[exercise.QuestionNumber] {
+					t.Errorf("question %d repeated", exercise.QuestionNumber)
+				}
+				seen[exercise.QuestionNumber] = true
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConfessionHandler struct {
+	confessionRepo repository.ConfessionRepository
+	userRepo       repository.UserRepository
+}
+
+func NewConfessionHandler(confessionRepo repository.ConfessionRepository, userRepo repository.UserRepository) *ConfessionHandler {
+	return &ConfessionHandler{
+		confessionRepo: confessionRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// ConfessionLink points from a catechism question to a Confession section
+type ConfessionLink struct {
+	*models.ConfessionReference
+	Href string `json:"href"`
+}
+
+type ConfessionSectionItem struct {
+	*models.ConfessionSection
+	CatechismQuestions []*CatechismQuestionLink `json:"catechism_questions"`
+}
+
+type ConfessionChapterResponse struct {
+	ChapterNumber int                      `json:"chapter_number"`
+	Title         string                   `json:"title"`
+	Catechism     string                   `json:"catechism"`
+	Sections      []*ConfessionSectionItem `json:"sections"`
+	Previous      *ConfessionChapterLink   `json:"previous"`
+	Next          *ConfessionChapterLink   `json:"next"`
+}
+
+type ConfessionChapterLink struct {
+	ChapterNumber int    `json:"chapter_number"`
+	Title         string `json:"title"`
+	Href          string `json:"href"`
+}
+
+func confessionChapterHref(chapterNumber int) string {
+	return fmt.Sprintf("%s/confession/chapters/%d", V1Prefix, chapterNumber)
+}
+
+// confessionLinks returns the Confession sections related to a question. The
+// links are only a complement to the question, so a failure is logged and an
+// empty list is returned.
+func (h *CatechismHandler) confessionLinks(c *gin.Context, question *models.CatechismQuestion) []*ConfessionLink {
+	links := []*ConfessionLink{}
+
+	references, err := h.confessionRepo.GetReferencesForQuestion(c.Request.Context(), question.Catechism, question.QuestionNumber)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Warn("failed to get confession references",
+			"question_number", question.QuestionNumber, "error", err)
+		return links
+	}
+
+	for _, reference := range references {
+		links = append(links, &ConfessionLink{
+			ConfessionReference: reference,
+			Href:                confessionChapterHref(reference.ChapterNumber),
+		})
+	}
+	return links
+}
+
+// GetChapters returns the chapters index of the Confession
+func (h *ConfessionHandler) GetChapters(c *gin.Context) {
+	chapters, err := h.confessionRepo.GetChapters(c.Request.Context())
+	if err != nil {
+		apierror.Internal(c, "Failed to get chapters", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, chapters)
+}
+
+// GetChapter returns a chapter with its sections, proof texts and the related
+// questions of the catechism the user follows
+func (h *ConfessionHandler) GetChapter(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidChapterNumber))
+		return
+	}
+
+	chapter, err := h.confessionRepo.GetChapter(c.Request.Context(), number)
+	if err != nil {
+		apierror.Internal(c, "Failed to get chapter", err)
+		return
+	}
+	if chapter == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeChapterNotFound))
+		return
+	}
+
+	catechism, err := getUserCatechism(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism", err)
+		return
+	}
+
+	linkedQuestions, err := h.confessionRepo.GetLinkedQuestions(c.Request.Context(), number, catechism)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism links", err)
+		return
+	}
+
+	chapters, err := h.confessionRepo.GetChapters(c.Request.Context())
+	if err != nil {
+		apierror.Internal(c, "Failed to get chapters", err)
+		return
+	}
+
+	response := ConfessionChapterResponse{
+		ChapterNumber: chapter.ChapterNumber,
+		Title:         chapter.Title,
+		Catechism:     catechism,
+		Sections:      make([]*ConfessionSectionItem, 0, len(chapter.Sections)),
+	}
+
+	for _, section := range chapter.Sections {
+		item := &ConfessionSectionItem{
+			ConfessionSection:  section,
+			CatechismQuestions: []*CatechismQuestionLink{},
+		}
+		for _, questionNumber := range linkedQuestions[section.SectionNumber] {
+			item.CatechismQuestions = append(item.CatechismQuestions, newQuestionLink(questionNumber))
+		}
+		response.Sections = append(response.Sections, item)
+	}
+
+	for i, other := range chapters {
+		if other.ChapterNumber != number {
+			continue
+		}
+		if i > 0 {
+			response.Previous = newChapterLink(chapters[i-1])
+		}
+		if i < len(chapters)-1 {
+			response.Next = newChapterLink(chapters[i+1])
+		}
+		break
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func newChapterLink(chapter *models.ConfessionChapter) *ConfessionChapterLink {
+	return &ConfessionChapterLink{
+		ChapterNumber: chapter.ChapterNumber,
+		Title:         chapter.Title,
+		Href:          confessionChapterHref(chapter.ChapterNumber),
+	}
+}
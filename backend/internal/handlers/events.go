@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/pubsub"
+	"biblia-am-pm/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventsHeartbeat is how often an idle stream sends a comment, so that
+// proxies don't close it and clients notice when it is gone
+const eventsHeartbeat = 25 * time.Second
+
+// EventsHandler streams the progress changes of a user and of their
+// household to their devices
+type EventsHandler struct {
+	broker        pubsub.Broker
+	householdRepo repository.HouseholdRepository
+}
+
+func NewEventsHandler(broker pubsub.Broker, householdRepo repository.HouseholdRepository) *EventsHandler {
+	return &EventsHandler{broker: broker, householdRepo: householdRepo}
+}
+
+// Stream sends the marks and unmarks that change the progress of the user or
+// of the members of their household, from any device, as Server-Sent Events
+// named after their type, "reading" or "catechism", with the progress event
+// as data and its ID as the event ID. The event's user_id tells whose
+// progress changed.
+//
+// The stream follows the members the household has when it opens; the
+// events of a member who leaves stop right away, and those of a new member
+// start when the client reconnects. Changes made while the client is
+// disconnected aren't sent again: a client reloads the progress when it
+// reconnects.
+func (h *EventsHandler) Stream(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	ctx := c.Request.Context()
+	memberIDs, err := h.householdRepo.GetMemberIDs(ctx, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get household members", err)
+		return
+	}
+	events, cancel := h.broker.Subscribe(memberIDs...)
+	defer cancel()
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		logging.FromContext(ctx).Warn("failed to clear the write deadline of an event stream", "error", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	// Keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Sends the headers right away, so the client knows it is subscribed
+	io.WriteString(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(eventsHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !h.follows(ctx, userID, event.UserID) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				logging.FromContext(ctx).Error("failed to encode progress event", "error", err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case <-ticker.C:
+			io.WriteString(c.Writer, ": ping\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+// follows checks that the user whose progress changed is still the user or a
+// member of their household
+func (h *EventsHandler) follows(ctx context.Context, userID, memberID int) bool {
+	if memberID == userID {
+		return true
+	}
+	memberIDs, err := h.householdRepo.GetMemberIDs(ctx, userID)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to get household members", "error", err)
+		return false
+	}
+	for _, id := range memberIDs {
+		if id == memberID {
+			return true
+		}
+	}
+	return false
+}
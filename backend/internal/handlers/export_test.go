@@ -0,0 +1,12 @@
+package handlers
+
+// The quiz helpers are exported to handlers_test for their table tests
+var (
+	ParseQuizRange        = parseQuizRange
+	BuildFillBlank        = buildFillBlank
+	BuildMultipleChoice   = buildMultipleChoice
+	GenerateQuizExercises = generateQuizExercises
+	GradeQuizExercise     = gradeQuizExercise
+	IsQuizExerciseCorrect = isQuizExerciseCorrect
+	QuizBlank             = quizBlank
+)
@@ -0,0 +1,1327 @@
+package handlers_test
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/handlers"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/pubsub"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/repository/memory"
+	"biblia-am-pm/internal/schedule"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// The legacy /api alias is mounted as in main.go
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacySunset       = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+type testServer struct {
+	t      *testing.T
+	router *gin.Engine
+	repos  *repository.Repositories
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	repos := memory.New()
+	router := gin.New()
+	cfg := config.Defaults(config.EnvDevelopment)
+	broker := pubsub.NewLocal()
+	t.Cleanup(broker.Close)
+	handlers.RegisterV1(router.Group(handlers.V1Prefix), repos, broker, cfg)
+	handlers.RegisterV1(router.Group("/api", middleware.Deprecated(legacyDeprecatedAt, legacySunset, "/api", handlers.V1Prefix)), repos, broker, cfg)
+	return &testServer{t: t, router: router, repos: repos}
+}
+
+// do sends a JSON request and decodes the JSON response into out, when given
+func (s *testServer) do(method, path, token string, body interface{}, out interface{}) int {
+	s.t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("marshal request: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			s.t.Fatalf("%s %s: decode response %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+// register creates a user and returns its token
+func (s *testServer) register(email string) string {
+	s.t.Helper()
+
+	var resp handlers.AuthResponse
+	code := s.do(http.MethodPost, "/api/v1/auth/register", "", handlers.RegisterRequest{Email: email, Password: "secret123"}, &resp)
+	if code != http.StatusCreated {
+		s.t.Fatalf("register %s: status %d", email, code)
+	}
+	return resp.Token
+}
+
+func (s *testServer) seedCatechism(total int) {
+	s.t.Helper()
+	for i := 1; i <= total; i++ {
+		err := s.repos.Catechism.Create(context.Background(), &models.CatechismQuestion{
+			QuestionNumber: i,
+			QuestionText:   fmt.Sprintf("Qual é a pergunta %d?", i),
+			AnswerText:     fmt.Sprintf("A resposta da pergunta %d fala sobre Deus e sua glória.", i),
+		})
+		if err != nil {
+			s.t.Fatalf("seed catechism: %v", err)
+		}
+	}
+}
+
+func (s *testServer) seedReadingPlans() {
+	s.t.Helper()
+	for day := 1; day <= 366; day++ {
+		err := s.repos.ReadingPlans.Create(context.Background(), &models.ReadingPlan{
+			DayOfYear:       day,
+			OldTestamentRef: fmt.Sprintf("Gn %d", day),
+			NewTestamentRef: fmt.Sprintf("Mt %d", day),
+			PsalmsRef:       "Sl 1",
+			ProverbsRef:     "Pv 1",
+		})
+		if err != nil {
+			s.t.Fatalf("seed reading plan: %v", err)
+		}
+	}
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	s := newTestServer(t)
+	s.register("ana@example.com")
+
+	if code := s.do(http.MethodPost, "/api/v1/auth/register", "", handlers.RegisterRequest{Email: "ana@example.com", Password: "other"}, nil); code != http.StatusConflict {
+		t.Errorf("duplicate register: got %d, want %d", code, http.StatusConflict)
+	}
+
+	if code := s.do(http.MethodPost, "/api/v1/auth/register", "", handlers.RegisterRequest{Email: "sem-senha@example.com"}, nil); code != http.StatusBadRequest {
+		t.Errorf("register without password: got %d, want %d", code, http.StatusBadRequest)
+	}
+
+	if code := s.do(http.MethodPost, "/api/v1/auth/login", "", handlers.LoginRequest{Email: "ana@example.com", Password: "wrong"}, nil); code != http.StatusUnauthorized {
+		t.Errorf("login with wrong password: got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	var resp struct {
+		Token string                 `json:"token"`
+		User  map[string]interface{} `json:"user"`
+	}
+	if code := s.do(http.MethodPost, "/api/v1/auth/login", "", handlers.LoginRequest{Email: "ana@example.com", Password: "secret123"}, &resp); code != http.StatusOK {
+		t.Fatalf("login: got %d, want %d", code, http.StatusOK)
+	}
+	if resp.Token == "" {
+		t.Error("login returned an empty token")
+	}
+	if _, ok := resp.User["password"]; ok {
+		t.Error("login response exposes the password")
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing token", ""},
+		{"invalid token", "not-a-jwt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := s.do(http.MethodGet, "/api/v1/progress", tt.token, nil, nil); code != http.StatusUnauthorized {
+				t.Errorf("got %d, want %d", code, http.StatusUnauthorized)
+			}
+		})
+	}
+
+	token := s.register("ana@example.com")
+	if code := s.do(http.MethodGet, "/api/v1/progress", token, nil, nil); code != http.StatusOK {
+		t.Errorf("valid token: got %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestAdminRoutesRequireAdminRole(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(3)
+	token := s.register("ana@example.com")
+
+	path := "/api/v1/admin/catechism/questions/1/revisions"
+	if code := s.do(http.MethodGet, path, token, nil, nil); code != http.StatusForbidden {
+		t.Fatalf("regular user: got %d, want %d", code, http.StatusForbidden)
+	}
+
+	if _, err := s.repos.Users.SetRole(context.Background(), "ana@example.com", models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	var revisions []*models.CatechismRevision
+	if code := s.do(http.MethodGet, path, token, nil, &revisions); code != http.StatusOK {
+		t.Fatalf("admin: got %d, want %d", code, http.StatusOK)
+	}
+	if len(revisions) != 1 || revisions[0].Revision != 1 {
+		t.Errorf("got %d revisions, want the initial revision", len(revisions))
+	}
+}
+
+func TestTodayReadingsAndMarkCompleted(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("ana@example.com")
+
+	if code := s.do(http.MethodGet, "/api/v1/readings/today", token, nil, nil); code != http.StatusNotFound {
+		t.Errorf("without reading plan: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	s.seedReadingPlans()
+
+	var today handlers.TodayReadingsResponse
+	if code := s.do(http.MethodGet, "/api/v1/readings/today", token, nil, &today); code != http.StatusOK {
+		t.Fatalf("today: got %d, want %d", code, http.StatusOK)
+	}
+	if today.Readings == nil || today.Readings.DayOfYear != today.DayOfYear {
+		t.Fatalf("today returned readings %+v for day %d", today.Readings, today.DayOfYear)
+	}
+	if today.Progress.MorningCompleted || today.Progress.EveningCompleted {
+		t.Error("new user already has completed readings")
+	}
+
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, handlers.MarkCompletedRequest{Period: "night"}, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid period: got %d, want %d", code, http.StatusBadRequest)
+	}
+
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, handlers.MarkCompletedRequest{Period: "morning"}, nil); code != http.StatusOK {
+		t.Fatalf("mark morning: got %d, want %d", code, http.StatusOK)
+	}
+
+	var progress []*models.UserProgress
+	if code := s.do(http.MethodGet, "/api/v1/progress", token, nil, &progress); code != http.StatusOK {
+		t.Fatalf("progress: got %d, want %d", code, http.StatusOK)
+	}
+	if len(progress) != 1 || !progress[0].MorningCompleted || progress[0].EveningCompleted {
+		t.Errorf("progress after marking the morning: %+v", progress)
+	}
+}
+
+func TestLegacyAPIAlias(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(3)
+	token := s.register("ana@example.com")
+
+	v1, legacy := map[string]bool{}, map[string]bool{}
+	for _, route := range s.router.Routes() {
+		if rest, ok := strings.CutPrefix(route.Path, handlers.V1Prefix); ok {
+			v1[route.Method+" "+rest] = true
+		} else if rest, ok := strings.CutPrefix(route.Path, "/api"); ok {
+			legacy[route.Method+" "+rest] = true
+		}
+	}
+	if !reflect.DeepEqual(v1, legacy) {
+		t.Errorf("legacy routes differ from v1:\nv1:     %v\nlegacy: %v", v1, legacy)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: got %d, want %d", path, w.Code, http.StatusOK)
+		}
+		return w
+	}
+
+	w := get("/api/catechism/questions/1")
+	if got, want := w.Header().Get(middleware.DeprecationHeader), fmt.Sprintf("@%d", legacyDeprecatedAt.Unix()); got != want {
+		t.Errorf("Deprecation: got %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get(middleware.SunsetHeader), "Fri, 30 Apr 2027 00:00:00 GMT"; got != want {
+		t.Errorf("Sunset: got %q, want %q", got, want)
+	}
+	if got, want := w.Header().Get(middleware.LinkHeader), `</api/v1/catechism/questions/1>; rel="successor-version"`; got != want {
+		t.Errorf("Link: got %q, want %q", got, want)
+	}
+
+	var question handlers.CatechismQuestionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &question); err != nil {
+		t.Fatal(err)
+	}
+	if question.Next == nil || question.Next.Href != "/api/v1/catechism/questions/2" {
+		t.Errorf("next link through the alias: %+v", question.Next)
+	}
+
+	w = get("/api/v1/catechism/questions/1")
+	if w.Header().Get(middleware.DeprecationHeader) != "" || w.Header().Get(middleware.SunsetHeader) != "" {
+		t.Errorf("v1 response is marked deprecated: %v", w.Header())
+	}
+}
+
+func TestErrorProblems(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("ana@example.com")
+
+	login := func(acceptLanguage string) (*httptest.ResponseRecorder, apierror.Problem) {
+		t.Helper()
+		body := `{"email":"ana@example.com","password":"wrong"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, req)
+
+		var problem apierror.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("decode problem %q: %v", w.Body.String(), err)
+		}
+		return w, problem
+	}
+
+	w, problem := login("")
+	if got := w.Header().Get("Content-Type"); got != apierror.ContentType {
+		t.Errorf("content type: got %q, want %q", got, apierror.ContentType)
+	}
+	if problem.Status != http.StatusUnauthorized || problem.Code != apierror.CodeInvalidCredentials || problem.Instance != "/api/v1/auth/login" {
+		t.Errorf("problem: %+v", problem)
+	}
+	if problem.Detail != "E-mail ou senha incorretos" {
+		t.Errorf("default detail: got %q, want Portuguese", problem.Detail)
+	}
+
+	if _, problem := login("fr-FR, en-US;q=0.8, pt;q=0.5"); problem.Detail != "Invalid credentials" {
+		t.Errorf("detail for Accept-Language en: got %q, want English", problem.Detail)
+	}
+
+	// The user's locale wins over Accept-Language
+	if code := s.do(http.MethodPut, "/api/v1/user/locale", token, handlers.SetLocaleRequest{Locale: "en-GB"}, nil); code != http.StatusOK {
+		t.Fatalf("set locale: got %d, want %d", code, http.StatusOK)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/catechism/questions/abc", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept-Language", "pt-BR")
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decode problem %q: %v", w.Body.String(), err)
+	}
+	if problem.Code != apierror.CodeInvalidQuestionNumber || problem.Detail != "Invalid question number" {
+		t.Errorf("problem with user locale en: %+v", problem)
+	}
+
+	if code := s.do(http.MethodPut, "/api/v1/user/locale", token, handlers.SetLocaleRequest{Locale: "klingon"}, &problem); code != http.StatusBadRequest || problem.Code != apierror.CodeInvalidLocale {
+		t.Errorf("invalid locale: got %d %s", code, problem.Code)
+	}
+}
+
+func TestCurrentCatechismQuestion(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("ana@example.com")
+
+	var problem apierror.Problem
+	if code := s.do(http.MethodGet, "/api/v1/catechism/current", token, nil, &problem); code != http.StatusServiceUnavailable || problem.Code != apierror.CodeCatechismNotPopulated {
+		t.Errorf("empty catechism: got %d %s, want %d %s", code, problem.Code, http.StatusServiceUnavailable, apierror.CodeCatechismNotPopulated)
+	}
+
+	s.seedCatechism(10)
+
+	var current handlers.CurrentQuestionResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/current", token, nil, &current); code != http.StatusOK {
+		t.Fatalf("current: got %d, want %d", code, http.StatusOK)
+	}
+	if current.TotalQuestions != 10 {
+		t.Errorf("total questions: got %d, want 10", current.TotalQuestions)
+	}
+	if current.Question == nil || current.Question.QuestionNumber != current.QuestionNumber {
+		t.Errorf("question %+v doesn't match question number %d", current.Question, current.QuestionNumber)
+	}
+	if current.Mode != models.CatechismModeWeekly || len(current.Schedule) != 7 {
+		t.Errorf("got mode %q with %d days, want weekly with 7 days", current.Mode, len(current.Schedule))
+	}
+}
+
+func TestMarkCatechismCompletedValidatesStep(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(10)
+	token := s.register("ana@example.com")
+
+	body := handlers.MarkCatechismCompletedRequest{Step: models.CatechismStepRead}
+	if code := s.do(http.MethodPost, "/api/v1/catechism/mark-completed", token, body, nil); code != http.StatusBadRequest {
+		t.Errorf("daily step in weekly mode: got %d, want %d", code, http.StatusBadRequest)
+	}
+
+	var progress models.CatechismProgress
+	if code := s.do(http.MethodPost, "/api/v1/catechism/mark-completed", token, handlers.MarkCatechismCompletedRequest{}, &progress); code != http.StatusOK {
+		t.Fatalf("weekly: got %d, want %d", code, http.StatusOK)
+	}
+	if progress.Step != models.CatechismStepReview || !progress.Completed {
+		t.Errorf("weekly progress: %+v", progress)
+	}
+
+	if code := s.do(http.MethodPut, "/api/v1/catechism/mode", token, handlers.SetCatechismModeRequest{Mode: "monthly"}, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid mode: got %d, want %d", code, http.StatusBadRequest)
+	}
+	if code := s.do(http.MethodPut, "/api/v1/catechism/mode", token, handlers.SetCatechismModeRequest{Mode: models.CatechismModeDaily}, nil); code != http.StatusOK {
+		t.Fatalf("set daily mode: got %d, want %d", code, http.StatusOK)
+	}
+
+	body = handlers.MarkCatechismCompletedRequest{Step: models.CatechismStepReciteFull}
+	if code := s.do(http.MethodPost, "/api/v1/catechism/mark-completed", token, body, &progress); code != http.StatusOK {
+		t.Fatalf("daily: got %d, want %d", code, http.StatusOK)
+	}
+	if progress.Step != models.CatechismStepReciteFull {
+		t.Errorf("daily step: got %q, want %q", progress.Step, models.CatechismStepReciteFull)
+	}
+}
+
+func TestBrowseCatechismQuestions(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(25)
+	token := s.register("ana@example.com")
+
+	var page handlers.CatechismQuestionsResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions?page=2&per_page=10", token, nil, &page); code != http.StatusOK {
+		t.Fatalf("list: got %d, want %d", code, http.StatusOK)
+	}
+	if page.Total != 25 || page.TotalPages != 3 || len(page.Questions) != 10 {
+		t.Errorf("got total %d, %d pages, %d questions", page.Total, page.TotalPages, len(page.Questions))
+	}
+	if first := page.Questions[0]; first.QuestionNumber != 11 || first.Progress.Status != models.QuestionStatusNotStarted {
+		t.Errorf("first question of page 2: number %d, status %q", first.QuestionNumber, first.Progress.Status)
+	}
+
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions?per_page=1000", token, nil, nil); code != http.StatusBadRequest {
+		t.Errorf("per_page too large: got %d, want %d", code, http.StatusBadRequest)
+	}
+
+	var question handlers.CatechismQuestionResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions/1", token, nil, &question); code != http.StatusOK {
+		t.Fatalf("question 1: got %d, want %d", code, http.StatusOK)
+	}
+	if question.Previous != nil || question.Next == nil || question.Next.QuestionNumber != 2 {
+		t.Errorf("question 1 links: previous %+v, next %+v", question.Previous, question.Next)
+	}
+
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions/99", token, nil, nil); code != http.StatusNotFound {
+		t.Errorf("missing question: got %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestCatechismSearch(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(5)
+	token := s.register("ana@example.com")
+
+	if code := s.do(http.MethodGet, "/api/v1/catechism/search?q=a", token, nil, nil); code != http.StatusBadRequest {
+		t.Errorf("short query: got %d, want %d", code, http.StatusBadRequest)
+	}
+	if code := s.do(http.MethodGet, "/api/v1/catechism/search?q=deus&catechism=medium", token, nil, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid catechism: got %d, want %d", code, http.StatusBadRequest)
+	}
+
+	var results []*models.CatechismSearchResult
+	if code := s.do(http.MethodGet, "/api/v1/catechism/search?q=pergunta%203&limit=5", token, nil, &results); code != http.StatusOK {
+		t.Fatalf("search: got %d, want %d", code, http.StatusOK)
+	}
+	if len(results) != 1 || results[0].Question.QuestionNumber != 3 {
+		t.Errorf("got %d results, want question 3", len(results))
+	}
+}
+
+func TestQuizCanOnlyBeSubmittedOnce(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(10)
+	token := s.register("ana@example.com")
+	other := s.register("bia@example.com")
+
+	var quiz handlers.QuizResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/quiz?range=1-10&count=4", token, nil, &quiz); code != http.StatusOK {
+		t.Fatalf("get quiz: got %d, want %d", code, http.StatusOK)
+	}
+	if len(quiz.Exercises) != 4 {
+		t.Fatalf("got %d exercises, want 4", len(quiz.Exercises))
+	}
+
+	path := fmt.Sprintf("/api/v1/catechism/quiz/%d", quiz.ID)
+	body := handlers.SubmitQuizRequest{Answers: []models.QuizAnswer{{Exercise: 0, Text: "resposta"}}}
+
+	if code := s.do(http.MethodPost, path, other, body, nil); code != http.StatusNotFound {
+		t.Errorf("another user's quiz: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	invalid := handlers.SubmitQuizRequest{Answers: []models.QuizAnswer{{Exercise: 4}}}
+	if code := s.do(http.MethodPost, path, token, invalid, nil); code != http.StatusBadRequest {
+		t.Errorf("invalid exercise index: got %d, want %d", code, http.StatusBadRequest)
+	}
+
+	var result handlers.QuizResultResponse
+	if code := s.do(http.MethodPost, path, token, body, &result); code != http.StatusOK {
+		t.Fatalf("submit: got %d, want %d", code, http.StatusOK)
+	}
+	if result.Total != 4 || len(result.Results) != 4 {
+		t.Errorf("got %d results for %d exercises", len(result.Results), result.Total)
+	}
+
+	if code := s.do(http.MethodPost, path, token, body, nil); code != http.StatusConflict {
+		t.Errorf("second submit: got %d, want %d", code, http.StatusConflict)
+	}
+
+	var results []handlers.QuizResultResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/quiz/results", token, nil, &results); code != http.StatusOK || len(results) != 1 {
+		t.Errorf("results: got %d with %d quizzes, want 1 quiz", code, len(results))
+	}
+}
+
+func TestConfessionChapterLinksCatechism(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(10)
+	token := s.register("ana@example.com")
+
+	chapters := []*models.ConfessionChapter{{
+		ChapterNumber: 1,
+		Title:         "Da Escritura Sagrada",
+		Sections: []*models.ConfessionSection{
+			{SectionNumber: 1, Text: "Ainda que a luz da natureza...", Proofs: []*models.ConfessionProof{{Letter: "a", References: "Rm 2.14-15"}}},
+			{SectionNumber: 2, Text: "Sob o nome de Escritura Sagrada..."},
+		},
+	}}
+	if err := s.repos.Confession.ReplaceAll(context.Background(), chapters); err != nil {
+		t.Fatal(err)
+	}
+	links := []*models.CatechismConfessionLink{{QuestionNumber: 2, ChapterNumber: 1, SectionNumber: 2}}
+	if err := s.repos.Confession.ReplaceLinks(context.Background(), models.CatechismShorter, links); err != nil {
+		t.Fatal(err)
+	}
+
+	var chapter handlers.ConfessionChapterResponse
+	if code := s.do(http.MethodGet, "/api/v1/confession/chapters/1", token, nil, &chapter); code != http.StatusOK {
+		t.Fatalf("chapter: got %d, want %d", code, http.StatusOK)
+	}
+	if len(chapter.Sections) != 2 || len(chapter.Sections[0].Proofs) != 1 {
+		t.Fatalf("chapter sections: %+v", chapter.Sections)
+	}
+	if linked := chapter.Sections[1].CatechismQuestions; len(linked) != 1 || linked[0].QuestionNumber != 2 {
+		t.Errorf("section 2 catechism links: %+v", linked)
+	}
+
+	var question handlers.CatechismQuestionResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions/2", token, nil, &question); code != http.StatusOK {
+		t.Fatalf("question 2: got %d, want %d", code, http.StatusOK)
+	}
+	if len(question.Confession) != 1 || question.Confession[0].ChapterTitle != "Da Escritura Sagrada" {
+		t.Errorf("question 2 confession links: %+v", question.Confession)
+	}
+
+	if code := s.do(http.MethodGet, "/api/v1/confession/chapters/2", token, nil, nil); code != http.StatusNotFound {
+		t.Errorf("missing chapter: got %d, want %d", code, http.StatusNotFound)
+	}
+}
+
+func TestCurrentQuestionFollowsSchedule(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(10)
+	token := s.register("ana@example.com")
+
+	var current handlers.CurrentQuestionResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/current", token, nil, &current); code != http.StatusOK {
+		t.Fatalf("current: got %d, want %d", code, http.StatusOK)
+	}
+
+	weekStart, err := time.Parse("2006-01-02", current.WeekStart)
+	if err != nil {
+		t.Fatalf("invalid week start %q: %v", current.WeekStart, err)
+	}
+	if weekStart.Weekday() != time.Sunday {
+		t.Errorf("week starts on %s, want Sunday", weekStart.Weekday())
+	}
+	if want := schedule.QuestionNumber(weekStart, 10); current.QuestionNumber != want {
+		t.Errorf("question number: got %d, want %d", current.QuestionNumber, want)
+	}
+}
+
+func TestFollowLargerCatechism(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(10)
+	for i := 1; i <= 20; i++ {
+		err := s.repos.Catechism.Create(context.Background(), &models.CatechismQuestion{
+			Catechism:      models.CatechismLarger,
+			QuestionNumber: i,
+			QuestionText:   fmt.Sprintf("Qual é a pergunta %d do Maior?", i),
+			AnswerText:     fmt.Sprintf("A resposta da pergunta %d do Maior.", i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	token := s.register("ana@example.com")
+
+	var current handlers.CurrentQuestionResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/current", token, nil, &current); code != http.StatusOK || current.TotalQuestions != 10 {
+		t.Fatalf("shorter: got %d with %d questions, want %d with 10", code, current.TotalQuestions, http.StatusOK)
+	}
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions/15", token, nil, nil); code != http.StatusNotFound {
+		t.Errorf("shorter question 15: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	var problem apierror.Problem
+	if code := s.do(http.MethodPut, "/api/v1/user/catechism", token, handlers.SetCatechismRequest{Catechism: "heidelberg"}, &problem); code != http.StatusBadRequest || problem.Code != apierror.CodeUnknownCatechism {
+		t.Errorf("unknown catechism: got %d %s", code, problem.Code)
+	}
+	if code := s.do(http.MethodPut, "/api/v1/user/catechism", token, handlers.SetCatechismRequest{Catechism: models.CatechismLarger}, nil); code != http.StatusOK {
+		t.Fatalf("set catechism: got %d, want %d", code, http.StatusOK)
+	}
+
+	current = handlers.CurrentQuestionResponse{}
+	if code := s.do(http.MethodGet, "/api/v1/catechism/current", token, nil, &current); code != http.StatusOK || current.TotalQuestions != 20 {
+		t.Fatalf("larger: got %d with %d questions, want %d with 20", code, current.TotalQuestions, http.StatusOK)
+	}
+	if current.Question == nil || current.Question.Catechism != models.CatechismLarger {
+		t.Errorf("current question %+v isn't from the Larger Catechism", current.Question)
+	}
+
+	var question handlers.CatechismQuestionResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions/15", token, nil, &question); code != http.StatusOK {
+		t.Fatalf("larger question 15: got %d, want %d", code, http.StatusOK)
+	}
+	if question.Catechism != models.CatechismLarger || question.Next == nil || question.Next.QuestionNumber != 16 {
+		t.Errorf("larger question 15 = %+v, next %+v", question.CatechismQuestion, question.Next)
+	}
+}
+
+// upload posts a multipart form with the given fields and files, keyed by
+// field name, and decodes the JSON response into out, when given
+func (s *testServer) upload(path, token string, fields map[string]string, files map[string]string, out interface{}) int {
+	s.t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		part, err := writer.CreateFormFile(name, name+".json")
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		if _, err := part.Write([]byte(content)); err != nil {
+			s.t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		s.t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+
+	if out != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), out); err != nil {
+			s.t.Fatalf("POST %s: decode response %q: %v", path, w.Body.String(), err)
+		}
+	}
+	return w.Code
+}
+
+func TestAdminImportIsScopedByCatechism(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(2)
+	token := s.register("ana@example.com")
+	if _, err := s.repos.Users.SetRole(context.Background(), "ana@example.com", models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	file := map[string]string{"file": `[{"number": 1, "q": "Qual é o fim principal do homem?", "a": "O fim principal do homem é glorificar a Deus e gozá-lo para sempre."}]`}
+
+	var problem apierror.Problem
+	if code := s.upload("/api/v1/admin/catechism/import", token, nil, file, &problem); code != http.StatusBadRequest || problem.Code != apierror.CodeUnknownCatechism {
+		t.Errorf("import without catechism: got %d %s", code, problem.Code)
+	}
+
+	var response handlers.CatechismImportResponse
+	fields := map[string]string{"catechism": models.CatechismLarger, "prune": "true"}
+	if code := s.upload("/api/v1/admin/catechism/import", token, fields, file, &response); code != http.StatusOK || !response.Applied {
+		t.Fatalf("import larger: got %d %+v", code, response)
+	}
+	if len(response.Diff.Added) != 1 || len(response.Diff.Removed) != 0 {
+		t.Errorf("larger diff: %d added and %d removed, want 1 added", len(response.Diff.Added), len(response.Diff.Removed))
+	}
+
+	for _, catechism := range []string{models.CatechismShorter, models.CatechismLarger} {
+		questions, err := s.repos.Catechism.GetAll(context.Background(), catechism)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]int{models.CatechismShorter: 2, models.CatechismLarger: 1}[catechism]
+		if len(questions) != want {
+			t.Errorf("%s: got %d questions, want %d", catechism, len(questions), want)
+		}
+	}
+}
+
+func TestAdminImportPruneRetiresQuestions(t *testing.T) {
+	s := newTestServer(t)
+	s.seedCatechism(2)
+	token := s.register("ana@example.com")
+	if _, err := s.repos.Users.SetRole(context.Background(), "ana@example.com", models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	pruned, err := s.repos.Catechism.GetByQuestionNumber(context.Background(), models.CatechismShorter, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := `{"number": 1, "q": "Qual é a pergunta 1?", "a": "A resposta da pergunta 1 fala sobre Deus e sua glória."}`
+	second := `{"number": 2, "q": "Qual é a pergunta 2?", "a": "A resposta da pergunta 2 fala sobre Deus e sua glória."}`
+	fields := map[string]string{"catechism": models.CatechismShorter, "prune": "true"}
+
+	var response handlers.CatechismImportResponse
+	if code := s.upload("/api/v1/admin/catechism/import", token, fields, map[string]string{"file": "[" + first + "]"}, &response); code != http.StatusOK || !response.Applied || len(response.Diff.Removed) != 1 {
+		t.Fatalf("prune: got %d %+v", code, response)
+	}
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions/2", token, nil, nil); code != http.StatusNotFound {
+		t.Errorf("pruned question: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	// Importing the question again brings back the same question, so the
+	// progress recorded against it still applies
+	response = handlers.CatechismImportResponse{}
+	if code := s.upload("/api/v1/admin/catechism/import", token, fields, map[string]string{"file": "[" + first + ", " + second + "]"}, &response); code != http.StatusOK || len(response.Diff.Added) != 1 {
+		t.Fatalf("restore: got %d %+v", code, response)
+	}
+	var question handlers.CatechismQuestionResponse
+	if code := s.do(http.MethodGet, "/api/v1/catechism/questions/2", token, nil, &question); code != http.StatusOK || question.ID != pruned.ID || question.Revision != 1 {
+		t.Errorf("restored question: got %d %+v, want ID %d at revision 1", code, question.CatechismQuestion, pruned.ID)
+	}
+}
+
+func TestSyncMergesCompletions(t *testing.T) {
+	s := newTestServer(t)
+	s.seedReadingPlans()
+	s.seedCatechism(10)
+	token := s.register("ana@example.com")
+
+	at := func(value string) string { return "2025-01-02T" + value + ":00Z" }
+	sync := func(req handlers.SyncRequest) *handlers.SyncResponse {
+		t.Helper()
+		var resp handlers.SyncResponse
+		if code := s.do(http.MethodPost, "/api/v1/sync", token, req, &resp); code != http.StatusOK {
+			t.Fatalf("sync: got %d, want %d", code, http.StatusOK)
+		}
+		return &resp
+	}
+	statuses := func(resp *handlers.SyncResponse) []string {
+		var statuses []string
+		for _, result := range resp.Results {
+			status := result.Status
+			if result.Problem != nil {
+				status += " " + string(result.Problem.Code)
+			}
+			statuses = append(statuses, status)
+		}
+		return statuses
+	}
+
+	// The phone was offline all day
+	phone := sync(handlers.SyncRequest{Events: []*handlers.SyncEvent{
+		{ID: "p1", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodMorning, RecordedAt: at("07:00")},
+		{ID: "p2", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodEvening, RecordedAt: at("21:00")},
+		{ID: "p3", Type: models.ProgressTypeCatechism, Date: "2025-01-02", RecordedAt: at("07:10")},
+		{ID: "p4", Type: "prayer", Date: "2025-01-02", RecordedAt: at("07:20")},
+		{ID: "p5", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodMorning, RecordedAt: time.Now().Add(time.Hour).Format(time.RFC3339)},
+		{ID: "p6", Type: models.ProgressTypeCatechism, Date: "2025-01-02", Step: models.CatechismStepRead, RecordedAt: at("07:30")},
+	}})
+	want := []string{"applied", "applied", "applied", "rejected INVALID_SYNC_EVENT_TYPE", "rejected INVALID_RECORDED_AT", "rejected INVALID_STEP"}
+	if got := statuses(phone); !reflect.DeepEqual(got, want) {
+		t.Errorf("phone results = %v, want %v", got, want)
+	}
+	if len(phone.Readings) != 1 || len(phone.Catechism) != 1 || phone.Cursor == "" {
+		t.Fatalf("phone changes = %+v", phone)
+	}
+	if day := phone.Readings[0]; day.CompletedAt == nil || day.CompletedAt.Format("15:04") != "21:00" {
+		t.Errorf("day completed at %v, want 21:00", day.CompletedAt)
+	}
+
+	// The tablet recorded the morning earlier and the evening later, and
+	// gets everything since it never synced
+	tablet := sync(handlers.SyncRequest{Events: []*handlers.SyncEvent{
+		{ID: "t1", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodMorning, RecordedAt: at("06:30")},
+		{ID: "t2", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodEvening, RecordedAt: at("22:00")},
+	}})
+	if got, want := statuses(tablet), []string{"applied", "unchanged"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tablet results = %v, want %v", got, want)
+	}
+	if len(tablet.Readings) != 1 || len(tablet.Catechism) != 1 {
+		t.Fatalf("tablet changes = %+v", tablet)
+	}
+	day := tablet.Readings[0]
+	if day.MorningCompletedAt.Format("15:04") != "06:30" || day.EveningCompletedAt.Format("15:04") != "21:00" || day.CompletedAt.Format("15:04") != "21:00" {
+		t.Errorf("merged day = %+v", day)
+	}
+
+	// Replaying the phone's events changes nothing
+	replay := sync(handlers.SyncRequest{Cursor: tablet.Cursor, Events: []*handlers.SyncEvent{
+		{ID: "p1", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodMorning, RecordedAt: at("07:00")},
+		{ID: "p3", Type: models.ProgressTypeCatechism, Date: "2025-01-02", RecordedAt: at("07:10")},
+	}})
+	if got, want := statuses(replay), []string{"unchanged", "unchanged"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replay results = %v, want %v", got, want)
+	}
+	if len(replay.Readings) != 0 || len(replay.Catechism) != 0 {
+		t.Errorf("replay changes = %+v", replay)
+	}
+
+	// Marks on the web are sent to the next sync
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, handlers.MarkCompletedRequest{Period: models.PeriodMorning}, nil); code != http.StatusOK {
+		t.Fatalf("mark morning: got %d, want %d", code, http.StatusOK)
+	}
+	next := sync(handlers.SyncRequest{Cursor: replay.Cursor})
+	if len(next.Readings) != 1 || !next.Readings[0].MorningCompleted || next.Cursor == replay.Cursor {
+		t.Errorf("changes after the web mark = %+v", next)
+	}
+
+	var problem apierror.Problem
+	if code := s.do(http.MethodPost, "/api/v1/sync", token, handlers.SyncRequest{Cursor: "-1"}, &problem); code != http.StatusBadRequest || problem.Code != apierror.CodeInvalidSyncCursor {
+		t.Errorf("invalid cursor: got %d %s", code, problem.Code)
+	}
+}
+
+func TestIdempotencyKeyReplaysResponse(t *testing.T) {
+	s := newTestServer(t)
+	s.seedReadingPlans()
+	ana := s.register("ana@example.com")
+	bia := s.register("bia@example.com")
+
+	send := func(token, key string, req handlers.SyncRequest) *httptest.ResponseRecorder {
+		t.Helper()
+		data, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/sync", bytes.NewReader(data))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Authorization", "Bearer "+token)
+		if key != "" {
+			r.Header.Set(middleware.IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, r)
+		return w
+	}
+	status := func(w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var resp handlers.SyncResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || len(resp.Results) != 1 {
+			t.Fatalf("sync response %q: %v", w.Body.String(), err)
+		}
+		return resp.Results[0].Status
+	}
+
+	morning := handlers.SyncRequest{Events: []*handlers.SyncEvent{
+		{ID: "e1", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodMorning, RecordedAt: "2025-01-02T07:00:00Z"},
+	}}
+	key := "0b6f4c1e-9d1a-4f55-8a5e-3c2d7f1b9e40"
+
+	first := send(ana, key, morning)
+	if first.Code != http.StatusOK || status(first) != handlers.SyncStatusApplied {
+		t.Fatalf("first request: got %d %q", first.Code, first.Body.String())
+	}
+	if first.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+		t.Error("first response is marked as replayed")
+	}
+
+	retry := send(ana, key, morning)
+	if retry.Code != http.StatusOK || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry: got %d %q, want the first response", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Errorf("retry is not marked as replayed: %v", retry.Header())
+	}
+
+	// Without the key the request runs again and finds the completion
+	if w := send(ana, "", morning); status(w) != handlers.SyncStatusUnchanged {
+		t.Errorf("request without key: got %q", w.Body.String())
+	}
+
+	// Keys belong to the user
+	if w := send(bia, key, morning); status(w) != handlers.SyncStatusApplied || w.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+		t.Errorf("another user's request with the key: got %q", w.Body.String())
+	}
+
+	problem := func(w *httptest.ResponseRecorder) apierror.Code {
+		t.Helper()
+		var p apierror.Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatalf("problem %q: %v", w.Body.String(), err)
+		}
+		return p.Code
+	}
+
+	evening := handlers.SyncRequest{Events: []*handlers.SyncEvent{
+		{ID: "e2", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodEvening, RecordedAt: "2025-01-02T21:00:00Z"},
+	}}
+	if w := send(ana, key, evening); w.Code != http.StatusUnprocessableEntity || problem(w) != apierror.CodeIdempotencyKeyReused {
+		t.Errorf("key reused for another body: got %d %q", w.Code, w.Body.String())
+	}
+
+	if w := send(ana, "not a key", evening); w.Code != http.StatusBadRequest || problem(w) != apierror.CodeInvalidIdempotencyKey {
+		t.Errorf("invalid key: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestUnmarkCompletions(t *testing.T) {
+	s := newTestServer(t)
+	s.seedReadingPlans()
+	s.seedCatechism(10)
+	token := s.register("ana@example.com")
+
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, handlers.MarkCompletedRequest{Period: models.PeriodMorning, Date: "2025-01-02"}, nil); code != http.StatusOK {
+		t.Fatalf("mark morning: got %d, want %d", code, http.StatusOK)
+	}
+
+	// The unmark comes from the phone
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/readings/completions?date=2025-01-02&period=morning", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(handlers.ClientHeader, models.ProgressSourceMobile)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	var progress models.UserProgress
+	if err := json.Unmarshal(w.Body.Bytes(), &progress); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("unmark morning: got %d %q", w.Code, w.Body.String())
+	}
+	if progress.MorningCompleted || progress.MorningCompletedAt != nil || progress.ID == 0 {
+		t.Errorf("progress after the unmark = %+v", progress)
+	}
+
+	// A sync bringing an offline mark from before the unmark doesn't undo it
+	var synced handlers.SyncResponse
+	sync := handlers.SyncRequest{Events: []*handlers.SyncEvent{
+		{ID: "m1", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodMorning, RecordedAt: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+	}}
+	if code := s.do(http.MethodPost, "/api/v1/sync", token, sync, &synced); code != http.StatusOK || synced.Results[0].Status != handlers.SyncStatusUnchanged {
+		t.Errorf("sync of an older mark: got %d %+v", code, synced.Results)
+	}
+
+	var events []*models.ProgressEvent
+	if code := s.do(http.MethodGet, "/api/v1/progress/events?date=2025-01-02", token, nil, &events); code != http.StatusOK {
+		t.Fatalf("progress events: got %d, want %d", code, http.StatusOK)
+	}
+	var log []string
+	for _, event := range events {
+		log = append(log, event.Action+" "+event.Period+" "+event.Source)
+	}
+	if want := []string{"mark morning web", "unmark morning mobile", "mark morning sync"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("progress events = %v, want %v", log, want)
+	}
+
+	var step models.CatechismProgress
+	if code := s.do(http.MethodPost, "/api/v1/catechism/mark-completed", token, handlers.MarkCatechismCompletedRequest{Date: "2025-01-02"}, &step); code != http.StatusOK || !step.Completed {
+		t.Fatalf("mark catechism: got %d %+v", code, step)
+	}
+	var unmarked models.CatechismProgress
+	if code := s.do(http.MethodDelete, "/api/v1/catechism/completions?date=2025-01-02", token, nil, &unmarked); code != http.StatusOK || unmarked.ID != step.ID || unmarked.Completed || unmarked.CompletedAt != nil {
+		t.Errorf("unmark catechism: got %d %+v", code, unmarked)
+	}
+
+	var problem apierror.Problem
+	if code := s.do(http.MethodDelete, "/api/v1/readings/completions?date=2025-01-02", token, nil, &problem); code != http.StatusBadRequest || problem.Code != apierror.CodeInvalidPeriod {
+		t.Errorf("unmark without period: got %d %s", code, problem.Code)
+	}
+	if code := s.do(http.MethodDelete, "/api/v1/catechism/completions?step=read", token, nil, &problem); code != http.StatusBadRequest || problem.Code != apierror.CodeInvalidStep {
+		t.Errorf("unmark a step of another mode: got %d %s", code, problem.Code)
+	}
+}
+
+// openEventStream opens the event stream of the user with token and returns
+// a function that reads the name and data of the next event, skipping
+// comments. The stream is closed with ctx.
+func openEventStream(ctx context.Context, t *testing.T, serverURL, token string) func() (string, models.ProgressEvent) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL+"/api/v1/events", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("open stream: got %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	stream := bufio.NewReader(resp.Body)
+
+	return func() (string, models.ProgressEvent) {
+		t.Helper()
+		var name string
+		var event models.ProgressEvent
+		for {
+			line, err := stream.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+					t.Fatalf("decode event %q: %v", line, err)
+				}
+			case line == "" && name != "":
+				return name, event
+			}
+		}
+	}
+}
+
+func TestEventsStreamProgressChanges(t *testing.T) {
+	s := newTestServer(t)
+	s.seedReadingPlans()
+	s.seedCatechism(10)
+	token := s.register("ana@example.com")
+	other := s.register("bia@example.com")
+
+	server := httptest.NewServer(s.router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	next := openEventStream(ctx, t, server.URL, token)
+
+	mark := handlers.MarkCompletedRequest{Period: models.PeriodEvening, Date: "2025-01-02"}
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, mark, nil); code != http.StatusOK {
+		t.Fatalf("mark evening: got %d, want %d", code, http.StatusOK)
+	}
+	if name, event := next(); name != models.ProgressTypeReading || event.Action != models.ProgressActionMark || event.Period != models.PeriodEvening || event.ID == 0 {
+		t.Errorf("event of the mark = %s %+v", name, event)
+	}
+
+	// Neither a mark that changes nothing nor another user's mark is sent
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, mark, nil); code != http.StatusOK {
+		t.Fatalf("mark evening again: got %d, want %d", code, http.StatusOK)
+	}
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", other, mark, nil); code != http.StatusOK {
+		t.Fatalf("mark evening of another user: got %d, want %d", code, http.StatusOK)
+	}
+	if code := s.do(http.MethodDelete, "/api/v1/catechism/completions?date=2025-01-02", token, nil, nil); code != http.StatusOK {
+		t.Fatalf("unmark catechism: got %d, want %d", code, http.StatusOK)
+	}
+	if code := s.do(http.MethodPost, "/api/v1/catechism/mark-completed", token, handlers.MarkCatechismCompletedRequest{Date: "2025-01-02"}, nil); code != http.StatusOK {
+		t.Fatalf("mark catechism: got %d, want %d", code, http.StatusOK)
+	}
+	if name, event := next(); name != models.ProgressTypeCatechism || event.Action != models.ProgressActionMark || event.UserID == 0 {
+		t.Errorf("event after the unchanged ones = %s %+v", name, event)
+	}
+}
+
+func TestEventsStreamFollowsHousehold(t *testing.T) {
+	s := newTestServer(t)
+	s.seedReadingPlans()
+	ana := s.register("ana@example.com")
+	bia := s.register("bia@example.com")
+	caio := s.register("caio@example.com")
+
+	var household models.Household
+	if code := s.do(http.MethodPost, "/api/v1/household", ana, nil, &household); code != http.StatusCreated {
+		t.Fatalf("create household: got %d, want %d", code, http.StatusCreated)
+	}
+	join := handlers.JoinHouseholdRequest{InviteCode: strings.ToLower(household.InviteCode)}
+	if code := s.do(http.MethodPost, "/api/v1/household/join", bia, join, &household); code != http.StatusOK {
+		t.Fatalf("join household: got %d, want %d", code, http.StatusOK)
+	}
+	if len(household.Members) != 2 {
+		t.Fatalf("members after joining = %+v", household.Members)
+	}
+	biaID := household.Members[1].UserID
+	if code := s.do(http.MethodPost, "/api/v1/household/join", bia, join, nil); code != http.StatusConflict {
+		t.Errorf("join household twice: got %d, want %d", code, http.StatusConflict)
+	}
+
+	server := httptest.NewServer(s.router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	next := openEventStream(ctx, t, server.URL, ana)
+
+	mark := func(token, date string) {
+		t.Helper()
+		request := handlers.MarkCompletedRequest{Period: models.PeriodEvening, Date: date}
+		if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, request, nil); code != http.StatusOK {
+			t.Fatalf("mark evening: got %d, want %d", code, http.StatusOK)
+		}
+	}
+
+	// A member's mark reaches the others, an outsider's doesn't
+	mark(caio, "2025-01-02")
+	mark(bia, "2025-01-02")
+	if name, event := next(); name != models.ProgressTypeReading || event.UserID != biaID {
+		t.Errorf("event of the member's mark = %s %+v, want from user %d", name, event, biaID)
+	}
+
+	// Once a member leaves, the open stream stops sending their marks
+	if code := s.do(http.MethodDelete, "/api/v1/household", bia, nil, nil); code != http.StatusNoContent {
+		t.Fatalf("leave household: got %d, want %d", code, http.StatusNoContent)
+	}
+	mark(bia, "2025-01-03")
+	mark(ana, "2025-01-03")
+	if name, event := next(); name != models.ProgressTypeReading || event.UserID == biaID {
+		t.Errorf("event after the member left = %s %+v, want the user's own", name, event)
+	}
+}
+
+func TestNotes(t *testing.T) {
+	s := newTestServer(t)
+	s.seedReadingPlans()
+	s.seedCatechism(3)
+	token := s.register("ana@example.com")
+	other := s.register("bia@example.com")
+
+	var created models.Note
+	request := handlers.NoteRequest{
+		Date:           "2025-01-02",
+		Period:         models.PeriodEvening,
+		Reference:      "joão 3.16-18",
+		QuestionNumber: 2,
+		Body:           "  Deus amou o mundo de tal maneira.  ",
+		Tags:           []string{"Graça", "amor", "graça "},
+	}
+	if code := s.do(http.MethodPost, "/api/v1/notes", token, request, &created); code != http.StatusCreated {
+		t.Fatalf("create: got %d, want %d", code, http.StatusCreated)
+	}
+	if created.Reference == nil || created.Reference.Text != "Jo 3:16-18" || created.QuestionNumber != 2 || created.ReadingPlanID == 0 {
+		t.Errorf("created note = %+v", created)
+	}
+	if created.Body != "Deus amou o mundo de tal maneira." || !reflect.DeepEqual(created.Tags, []string{"amor", "graça"}) {
+		t.Errorf("created body %q and tags %v", created.Body, created.Tags)
+	}
+
+	if code := s.do(http.MethodPost, "/api/v1/notes", token, handlers.NoteRequest{Date: "2025-01-03", Period: models.PeriodMorning, Reference: "Gn 1:26-2:3", Body: "A criação do homem."}, nil); code != http.StatusCreated {
+		t.Fatalf("create another: got %d, want %d", code, http.StatusCreated)
+	}
+
+	path := fmt.Sprintf("/api/v1/notes/%d", created.ID)
+	if code := s.do(http.MethodGet, path, other, nil, nil); code != http.StatusNotFound {
+		t.Errorf("note of another user: got %d, want %d", code, http.StatusNotFound)
+	}
+	if code := s.do(http.MethodPut, path, other, request, nil); code != http.StatusNotFound {
+		t.Errorf("update a note of another user: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	for query, want := range map[string]int{
+		"":                      2,
+		"?date=2025-01-02":      1,
+		"?book=Jo&chapter=3":    1,
+		"?book=joao&chapter=4":  0,
+		"?book=G%C3%AAnesis":    1,
+		"?book=Gn&chapter=2":    1,
+		"?tag=GRA%C3%87A":       1,
+		"?per_page=1&page=2":    2,
+		"?book=Jo&tag=natureza": 0,
+	} {
+		var page handlers.NotesResponse
+		if code := s.do(http.MethodGet, "/api/v1/notes"+query, token, nil, &page); code != http.StatusOK || page.Total != want {
+			t.Errorf("list %q: got %d with %d notes, want %d", query, code, page.Total, want)
+		}
+	}
+
+	var results []*models.NoteSearchResult
+	if code := s.do(http.MethodGet, "/api/v1/notes/search?q=mundo", token, nil, &results); code != http.StatusOK || len(results) != 1 || results[0].Note.ID != created.ID {
+		t.Errorf("search: got %d with %d results", code, len(results))
+	}
+	if code := s.do(http.MethodGet, "/api/v1/notes/search?q=mundo", other, nil, &results); code != http.StatusOK || len(results) != 0 {
+		t.Errorf("search of another user: got %d with %d results", code, len(results))
+	}
+
+	var updated models.Note
+	request.Reference = ""
+	request.Tags = nil
+	if code := s.do(http.MethodPut, path, token, request, &updated); code != http.StatusOK {
+		t.Fatalf("update: got %d, want %d", code, http.StatusOK)
+	}
+	if updated.Reference != nil || len(updated.Tags) != 0 || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("updated note = %+v", updated)
+	}
+
+	if code := s.do(http.MethodDelete, path, token, nil, nil); code != http.StatusNoContent {
+		t.Errorf("delete: got %d, want %d", code, http.StatusNoContent)
+	}
+	if code := s.do(http.MethodGet, path, token, nil, nil); code != http.StatusNotFound {
+		t.Errorf("deleted note: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	for _, tt := range []struct {
+		method, path string
+		body         interface{}
+		status       int
+		want         apierror.Code
+	}{
+		{http.MethodPost, "/api/v1/notes", handlers.NoteRequest{Period: models.PeriodMorning, Body: " "}, http.StatusBadRequest, apierror.CodeInvalidNoteBody},
+		{http.MethodPost, "/api/v1/notes", handlers.NoteRequest{Period: "night", Body: "x"}, http.StatusBadRequest, apierror.CodeInvalidPeriod},
+		{http.MethodPost, "/api/v1/notes", handlers.NoteRequest{Period: models.PeriodMorning, Body: "x", Reference: "Jo 30"}, http.StatusBadRequest, apierror.CodeInvalidBibleReference},
+		{http.MethodPost, "/api/v1/notes", handlers.NoteRequest{Period: models.PeriodMorning, Body: "x", QuestionNumber: 9}, http.StatusNotFound, apierror.CodeQuestionNotFound},
+		{http.MethodPost, "/api/v1/notes", handlers.NoteRequest{Period: models.PeriodMorning, Body: "x", Tags: []string{strings.Repeat("a", models.MaxNoteTagLength+1)}}, http.StatusBadRequest, apierror.CodeInvalidNoteTags},
+		{http.MethodGet, "/api/v1/notes/abc", nil, http.StatusBadRequest, apierror.CodeInvalidNoteID},
+		{http.MethodGet, "/api/v1/notes?book=Xy", nil, http.StatusBadRequest, apierror.CodeUnknownBook},
+		{http.MethodGet, "/api/v1/notes?chapter=3", nil, http.StatusBadRequest, apierror.CodeChapterRequiresBook},
+		{http.MethodGet, "/api/v1/notes?book=Jo&chapter=22", nil, http.StatusBadRequest, apierror.CodeInvalidChapterNumber},
+		{http.MethodGet, "/api/v1/notes/search?q=a", nil, http.StatusBadRequest, apierror.CodeSearchQueryTooShort},
+	} {
+		var problem apierror.Problem
+		if code := s.do(tt.method, tt.path, token, tt.body, &problem); code != tt.status || problem.Code != tt.want {
+			t.Errorf("%s %s: got %d %s, want %d %s", tt.method, tt.path, code, problem.Code, tt.status, tt.want)
+		}
+	}
+}
+
+func TestAccountExport(t *testing.T) {
+	s := newTestServer(t)
+	s.seedReadingPlans()
+	s.seedCatechism(3)
+	token := s.register("ana@example.com")
+	other := s.register("bia@example.com")
+
+	if code := s.do(http.MethodPost, "/api/v1/readings/mark-completed", token, handlers.MarkCompletedRequest{Period: models.PeriodMorning}, nil); code != http.StatusOK {
+		t.Fatalf("mark reading: got %d, want %d", code, http.StatusOK)
+	}
+	if code := s.do(http.MethodPost, "/api/v1/catechism/mark-completed", token, handlers.MarkCatechismCompletedRequest{}, nil); code != http.StatusOK {
+		t.Fatalf("mark catechism: got %d, want %d", code, http.StatusOK)
+	}
+	note := handlers.NoteRequest{Date: "2025-01-02", Period: models.PeriodMorning, Reference: "Sl 23", Body: "O Senhor é o meu pastor.", Tags: []string{"salmos"}}
+	if code := s.do(http.MethodPost, "/api/v1/notes", token, note, nil); code != http.StatusCreated {
+		t.Fatalf("create note: got %d, want %d", code, http.StatusCreated)
+	}
+	if code := s.do(http.MethodPost, "/api/v1/notes", other, note, nil); code != http.StatusCreated {
+		t.Fatalf("create note of another user: got %d, want %d", code, http.StatusCreated)
+	}
+
+	var export handlers.AccountExport
+	if code := s.do(http.MethodGet, "/api/v1/account/export", token, nil, &export); code != http.StatusOK {
+		t.Fatalf("export: got %d, want %d", code, http.StatusOK)
+	}
+	if export.User == nil || export.User.Email != "ana@example.com" || export.ExportedAt.IsZero() {
+		t.Errorf("exported user = %+v at %v", export.User, export.ExportedAt)
+	}
+	if len(export.ReadingProgress) != 1 || !export.ReadingProgress[0].MorningCompleted {
+		t.Errorf("exported reading progress = %+v", export.ReadingProgress)
+	}
+	if len(export.CatechismProgress) != 1 || !export.CatechismProgress[0].Completed {
+		t.Errorf("exported catechism progress = %+v", export.CatechismProgress)
+	}
+	if len(export.ProgressEvents) != 2 || export.ProgressEvents[0].Type != models.ProgressTypeReading || export.ProgressEvents[1].Type != models.ProgressTypeCatechism {
+		t.Errorf("exported progress events = %+v", export.ProgressEvents)
+	}
+	if len(export.Notes) != 1 || export.Notes[0].Body != note.Body || !reflect.DeepEqual(export.Notes[0].Tags, note.Tags) {
+		t.Errorf("exported notes = %+v", export.Notes)
+	}
+
+	// Another account exports only its own data
+	if code := s.do(http.MethodGet, "/api/v1/account/export", other, nil, &export); code != http.StatusOK {
+		t.Fatalf("export of another user: got %d, want %d", code, http.StatusOK)
+	}
+	if export.User.Email != "bia@example.com" || len(export.ReadingProgress) != 0 || len(export.ProgressEvents) != 0 || len(export.Notes) != 1 {
+		t.Errorf("export of another user = %+v", export)
+	}
+}
+
+func TestAdminImportLoadsSections(t *testing.T) {
+	s := newTestServer(t)
+	token := s.register("ana@example.com")
+	if _, err := s.repos.Users.SetRole(context.Background(), "ana@example.com", models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"file": `[{"number": 1, "q": "Qual é o fim principal do homem?", "a": "Glorificar a Deus."},
+		          {"number": 2, "q": "Que regra Deus nos deu?", "a": "A palavra de Deus."},
+		          {"number": 3, "q": "Que ensinam as Escrituras?", "a": "O que o homem deve crer."}]`,
+		"sections": `{"shorter": [{"slug": "introduction", "title": "Introdução", "start_question": 1, "end_question": 3}],
+		              "larger": [{"slug": "god", "title": "Deus", "start_question": 1, "end_question": 9}]}`,
+	}
+	fields := map[string]string{"catechism": models.CatechismShorter}
+
+	var response handlers.CatechismImportResponse
+	if code := s.upload("/api/v1/admin/catechism/import", token, fields, files, &response); code != http.StatusOK || !response.Applied || response.Sections != 1 {
+		t.Fatalf("import: got %d %+v, want applied with 1 section", code, response)
+	}
+
+	var sections []*models.CatechismSection
+	if code := s.do(http.MethodGet, "/api/v1/catechism/sections", token, nil, &sections); code != http.StatusOK {
+		t.Fatalf("sections: got %d, want %d", code, http.StatusOK)
+	}
+	if len(sections) != 1 || sections[0].Slug != "introduction" || sections[0].EndQuestion != 3 {
+		t.Errorf("sections = %+v", sections)
+	}
+
+	// Sections alone are applied even when the questions are unchanged
+	files["sections"] = `[{"slug": "scriptures", "title": "As Escrituras", "start_question": 2, "end_question": 3}]`
+	response = handlers.CatechismImportResponse{}
+	if code := s.upload("/api/v1/admin/catechism/import", token, fields, files, &response); code != http.StatusOK || !response.Applied || response.Diff.HasChanges() {
+		t.Fatalf("sections only: got %d %+v", code, response)
+	}
+	sections, err := s.repos.CatechismSections.GetByCatechism(context.Background(), models.CatechismShorter)
+	if err != nil || len(sections) != 1 || sections[0].Slug != "scriptures" {
+		t.Errorf("sections after second import = %+v, %v", sections, err)
+	}
+
+	files["sections"] = `[{"slug": "all", "title": "Tudo", "start_question": 1, "end_question": 4}, {"slug": "all", "start_question": 3, "end_question": 3}]`
+	var problem struct {
+		Code     apierror.Code `json:"code"`
+		Problems []string      `json:"problems"`
+	}
+	if code := s.upload("/api/v1/admin/catechism/import", token, fields, files, &problem); code != http.StatusUnprocessableEntity || problem.Code != apierror.CodeCatechismInvalid {
+		t.Errorf("invalid sections: got %d %s", code, problem.Code)
+	}
+	if len(problem.Problems) != 3 {
+		t.Errorf("got problems %q, want the range, the repeated slug and the missing title", problem.Problems)
+	}
+}
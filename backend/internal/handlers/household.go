@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/repository"
+	"crypto/rand"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inviteCodeAlphabet leaves out the letters and digits that are easily
+// mistaken for one another when a code is read aloud or typed (0/O, 1/I)
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const inviteCodeLength = 8
+
+// HouseholdHandler manages the household of the user, whose members follow
+// each other's progress in the event stream
+type HouseholdHandler struct {
+	householdRepo repository.HouseholdRepository
+}
+
+func NewHouseholdHandler(householdRepo repository.HouseholdRepository) *HouseholdHandler {
+	return &HouseholdHandler{householdRepo: householdRepo}
+}
+
+type JoinHouseholdRequest struct {
+	InviteCode string `json:"invite_code"`
+}
+
+// Get returns the household of the user with its members
+func (h *HouseholdHandler) Get(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	household, err := h.householdRepo.GetByUser(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get household", err)
+		return
+	}
+	if household == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeHouseholdNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+// Create starts a household with the user as its only member. The others
+// join it with its invite code.
+func (h *HouseholdHandler) Create(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		apierror.Internal(c, "Failed to generate invite code", err)
+		return
+	}
+	household, err := h.householdRepo.Create(c.Request.Context(), userID, code)
+	if err != nil {
+		apierror.Internal(c, "Failed to create household", err)
+		return
+	}
+	if household == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeAlreadyInHousehold))
+		return
+	}
+
+	c.JSON(http.StatusCreated, household)
+}
+
+// Join adds the user to the household with the invite code
+func (h *HouseholdHandler) Join(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	var req JoinHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+		return
+	}
+	code := strings.ToUpper(strings.TrimSpace(req.InviteCode))
+
+	ctx := c.Request.Context()
+	current, err := h.householdRepo.GetByUser(ctx, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get household", err)
+		return
+	}
+	if current != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeAlreadyInHousehold))
+		return
+	}
+
+	household, err := h.householdRepo.Join(ctx, userID, code)
+	if err != nil {
+		apierror.Internal(c, "Failed to join household", err)
+		return
+	}
+	if household == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInviteCodeNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+// Leave takes the user out of their household. The household is deleted
+// with its last member.
+func (h *HouseholdHandler) Leave(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	left, err := h.householdRepo.Leave(c.Request.Context(), userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to leave household", err)
+		return
+	}
+	if !left {
+		apierror.Abort(c, apierror.New(apierror.CodeHouseholdNotFound))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// newInviteCode returns a random code of inviteCodeLength characters
+func newInviteCode() (string, error) {
+	random := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	code := make([]byte, inviteCodeLength)
+	for i, b := range random {
+		code[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/bibleref"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultNotesPerPage = 20
+	maxNotesPerPage     = 100
+)
+
+// NotesHandler serves the reading journal: the notes users write on the days
+// of the plan
+type NotesHandler struct {
+	noteRepo        repository.NoteRepository
+	readingPlanRepo repository.ReadingPlanRepository
+	catechismRepo   repository.CatechismRepository
+	userRepo        repository.UserRepository
+	location        *time.Location
+}
+
+func NewNotesHandler(
+	noteRepo repository.NoteRepository,
+	readingPlanRepo repository.ReadingPlanRepository,
+	catechismRepo repository.CatechismRepository,
+	userRepo repository.UserRepository,
+	location *time.Location,
+) *NotesHandler {
+	return &NotesHandler{
+		noteRepo:        noteRepo,
+		readingPlanRepo: readingPlanRepo,
+		catechismRepo:   catechismRepo,
+		userRepo:        userRepo,
+		location:        location,
+	}
+}
+
+// NoteRequest creates or replaces a note. Date defaults to today. Reference,
+// a passage like "Jo 3:16-18", and QuestionNumber, a catechism question, are
+// optional. The question is of Catechism, by default the one the user follows.
+type NoteRequest struct {
+	Date           string   `json:"date"`
+	Period         string   `json:"period"`
+	Reference      string   `json:"reference"`
+	QuestionNumber int      `json:"question_number"`
+	Catechism      string   `json:"catechism"`
+	Body           string   `json:"body"`
+	Tags           []string `json:"tags"`
+}
+
+type NotesResponse struct {
+	Notes      []*models.Note `json:"notes"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"per_page"`
+	Total      int            `json:"total"`
+	TotalPages int            `json:"total_pages"`
+}
+
+// Create saves a new note of the user
+func (h *NotesHandler) Create(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	note, ok := h.bindNote(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.noteRepo.Create(c.Request.Context(), note); err != nil {
+		apierror.Internal(c, "Failed to create note", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// Update replaces a note of the user
+func (h *NotesHandler) Update(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	id, ok := noteID(c)
+	if !ok {
+		return
+	}
+
+	note, ok := h.bindNote(c, userID)
+	if !ok {
+		return
+	}
+	note.ID = id
+
+	updated, err := h.noteRepo.Update(c.Request.Context(), note)
+	if err != nil {
+		apierror.Internal(c, "Failed to update note", err)
+		return
+	}
+	if !updated {
+		apierror.Abort(c, apierror.New(apierror.CodeNoteNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// bindNote validates a NoteRequest and resolves its plan day and question.
+// It aborts the request and returns false when the request is invalid.
+func (h *NotesHandler) bindNote(c *gin.Context, userID int) (*models.Note, bool) {
+	var req NoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+		return nil, false
+	}
+
+	if !models.IsValidPeriod(req.Period) {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidPeriod))
+		return nil, false
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" || utf8.RuneCountInString(body) > models.MaxNoteBodyLength {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidNoteBody, models.MaxNoteBodyLength))
+		return nil, false
+	}
+
+	tags, ok := models.NormalizeTags(req.Tags)
+	if !ok {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidNoteTags, models.MaxNoteTags, models.MaxNoteTagLength))
+		return nil, false
+	}
+
+	note := &models.Note{UserID: userID, Period: req.Period, Body: body, Tags: tags}
+
+	var err error
+	if strings.TrimSpace(req.Reference) != "" {
+		note.Reference, err = bibleref.Parse(req.Reference)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidBibleReference, req.Reference))
+			return nil, false
+		}
+	}
+
+	note.Date = localTime(h.location)
+	if req.Date != "" {
+		note.Date, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidDate))
+			return nil, false
+		}
+	}
+
+	ctx := c.Request.Context()
+	plan, err := h.readingPlanRepo.GetByDayOfYear(ctx, note.Date.YearDay())
+	if err != nil {
+		apierror.Internal(c, "Failed to get reading plan", err)
+		return nil, false
+	}
+	if plan == nil {
+		if req.Date == "" {
+			apierror.Abort(c, apierror.New(apierror.CodePlanNotFound))
+		} else {
+			apierror.Abort(c, apierror.New(apierror.CodePlanNotFoundForDate, req.Date))
+		}
+		return nil, false
+	}
+	note.ReadingPlanID = plan.ID
+
+	if req.QuestionNumber != 0 {
+		catechism := req.Catechism
+		if catechism == "" {
+			catechism, err = getUserCatechism(ctx, h.userRepo, userID)
+			if err != nil {
+				apierror.Internal(c, "Failed to get catechism", err)
+				return nil, false
+			}
+		}
+		if !models.IsValidCatechism(catechism) {
+			apierror.Abort(c, apierror.New(apierror.CodeUnknownCatechism, models.CatechismShorter, models.CatechismLarger))
+			return nil, false
+		}
+
+		question, err := h.catechismRepo.GetByQuestionNumber(ctx, catechism, req.QuestionNumber)
+		if err != nil {
+			apierror.Internal(c, "Failed to get question", err)
+			return nil, false
+		}
+		if question == nil {
+			apierror.Abort(c, apierror.New(apierror.CodeQuestionNotFound))
+			return nil, false
+		}
+		note.QuestionID = question.ID
+		note.QuestionNumber = question.QuestionNumber
+		note.Catechism = question.Catechism
+	}
+
+	return note, true
+}
+
+// noteID parses the ID in the path, aborting the request when it is invalid
+func noteID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidNoteID))
+		return 0, false
+	}
+	return id, true
+}
+
+// Get returns a note of the user
+func (h *NotesHandler) Get(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	id, ok := noteID(c)
+	if !ok {
+		return
+	}
+
+	note, err := h.noteRepo.GetByID(c.Request.Context(), userID, id)
+	if err != nil {
+		apierror.Internal(c, "Failed to get note", err)
+		return
+	}
+	if note == nil {
+		apierror.Abort(c, apierror.New(apierror.CodeNoteNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// Delete removes a note of the user
+func (h *NotesHandler) Delete(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	id, ok := noteID(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.noteRepo.Delete(c.Request.Context(), userID, id)
+	if err != nil {
+		apierror.Internal(c, "Failed to delete note", err)
+		return
+	}
+	if !deleted {
+		apierror.Abort(c, apierror.New(apierror.CodeNoteNotFound))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// List returns a page of the user's notes, latest day first. They can be
+// narrowed to a day (?date=), a book or a chapter of a book (?book=Jo&chapter=3,
+// matching the passages that include it) and a tag (?tag=).
+func (h *NotesHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	page := 1
+	if value := c.Query("page"); value != "" {
+		page, err = strconv.Atoi(value)
+		if err != nil || page < 1 {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidPage))
+			return
+		}
+	}
+
+	perPage := defaultNotesPerPage
+	if value := c.Query("per_page"); value != "" {
+		perPage, err = strconv.Atoi(value)
+		if err != nil || perPage < 1 || perPage > maxNotesPerPage {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidPerPage, maxNotesPerPage))
+			return
+		}
+	}
+
+	var filter models.NoteFilter
+	if value := c.Query("date"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidDate))
+			return
+		}
+		filter.Date = &date
+	}
+
+	if value := c.Query("book"); value != "" {
+		book := bibleref.LookupBook(value)
+		if book == nil {
+			apierror.Abort(c, apierror.New(apierror.CodeUnknownBook, value))
+			return
+		}
+		filter.Book = book.Abbreviation
+
+		if value := c.Query("chapter"); value != "" {
+			filter.Chapter, err = strconv.Atoi(value)
+			if err != nil || filter.Chapter < 1 || filter.Chapter > book.Chapters {
+				apierror.Abort(c, apierror.New(apierror.CodeInvalidChapterNumber))
+				return
+			}
+		}
+	} else if c.Query("chapter") != "" {
+		apierror.Abort(c, apierror.New(apierror.CodeChapterRequiresBook))
+		return
+	}
+
+	filter.Tag = models.NormalizeTag(c.Query("tag"))
+
+	notes, total, err := h.noteRepo.List(c.Request.Context(), userID, filter, perPage, (page-1)*perPage)
+	if err != nil {
+		apierror.Internal(c, "Failed to list notes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NotesResponse{
+		Notes:      notes,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	})
+}
+
+// Search finds the user's notes whose body matches ?q=, best matches first,
+// with the matching words highlighted in <mark> tags
+func (h *NotesHandler) Search(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	text := strings.TrimSpace(c.Query("q"))
+	if len([]rune(text)) < 2 {
+		apierror.Abort(c, apierror.New(apierror.CodeSearchQueryTooShort))
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidLimit, maxSearchLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.noteRepo.Search(c.Request.Context(), userID, text, limit)
+	if err != nil {
+		apierror.Internal(c, "Failed to search notes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
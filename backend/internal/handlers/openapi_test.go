@@ -0,0 +1,687 @@
+package handlers_test
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/catechismimport"
+	"biblia-am-pm/internal/handlers"
+	"biblia-am-pm/internal/health"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/openapi"
+	"biblia-am-pm/internal/textdiff"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// These tests fail when the handlers drift from openapi.yaml: a route added
+// without documenting it, a field renamed in a response type, or a response
+// that doesn't validate against the schema of its status code.
+
+// mainRoutes are registered by main.go rather than RegisterRoutes
+var mainRoutes = []string{"GET /healthz", "GET /readyz"}
+
+// specTypes maps each object schema in the spec to the Go type it describes
+var specTypes = map[string]reflect.Type{
+	"Problem":           reflect.TypeOf(apierror.Problem{}),
+	"HealthReport":      reflect.TypeOf(health.Report{}),
+	"HealthCheckResult": reflect.TypeOf(health.CheckResult{}),
+
+	"User":             reflect.TypeOf(models.User{}),
+	"RegisterRequest":  reflect.TypeOf(handlers.RegisterRequest{}),
+	"LoginRequest":     reflect.TypeOf(handlers.LoginRequest{}),
+	"SetLocaleRequest": reflect.TypeOf(handlers.SetLocaleRequest{}),
+	"AuthResponse":     reflect.TypeOf(handlers.AuthResponse{}),
+	"AccountExport":    reflect.TypeOf(handlers.AccountExport{}),
+
+	"Household":            reflect.TypeOf(models.Household{}),
+	"HouseholdMember":      reflect.TypeOf(models.HouseholdMember{}),
+	"JoinHouseholdRequest": reflect.TypeOf(handlers.JoinHouseholdRequest{}),
+
+	"ReadingPlan":           reflect.TypeOf(models.ReadingPlan{}),
+	"UserProgress":          reflect.TypeOf(models.UserProgress{}),
+	"TodayReadingsResponse": reflect.TypeOf(handlers.TodayReadingsResponse{}),
+	"MarkCompletedRequest":  reflect.TypeOf(handlers.MarkCompletedRequest{}),
+	"ProgressEvent":         reflect.TypeOf(models.ProgressEvent{}),
+
+	"CatechismQuestion":             reflect.TypeOf(models.CatechismQuestion{}),
+	"CatechismProgress":             reflect.TypeOf(models.CatechismProgress{}),
+	"CatechismDayStep":              reflect.TypeOf(handlers.CatechismDayStep{}),
+	"CurrentQuestionResponse":       reflect.TypeOf(handlers.CurrentQuestionResponse{}),
+	"MarkCatechismCompletedRequest": reflect.TypeOf(handlers.MarkCatechismCompletedRequest{}),
+	"SetCatechismModeRequest":       reflect.TypeOf(handlers.SetCatechismModeRequest{}),
+	"SetCatechismRequest":           reflect.TypeOf(handlers.SetCatechismRequest{}),
+	"CatechismSection":              reflect.TypeOf(models.CatechismSection{}),
+	"CatechismQuestionStatus":       reflect.TypeOf(models.CatechismQuestionStatus{}),
+	"CatechismQuestionLink":         reflect.TypeOf(handlers.CatechismQuestionLink{}),
+	"CatechismQuestionItem":         reflect.TypeOf(handlers.CatechismQuestionItem{}),
+	"CatechismQuestionsResponse":    reflect.TypeOf(handlers.CatechismQuestionsResponse{}),
+	"CatechismQuestionResponse":     reflect.TypeOf(handlers.CatechismQuestionResponse{}),
+	"CatechismSearchResult":         reflect.TypeOf(models.CatechismSearchResult{}),
+
+	"QuizExerciseView":   reflect.TypeOf(handlers.QuizExerciseView{}),
+	"QuizResponse":       reflect.TypeOf(handlers.QuizResponse{}),
+	"QuizAnswer":         reflect.TypeOf(models.QuizAnswer{}),
+	"SubmitQuizRequest":  reflect.TypeOf(handlers.SubmitQuizRequest{}),
+	"QuizExerciseResult": reflect.TypeOf(models.QuizExerciseResult{}),
+	"QuizResultResponse": reflect.TypeOf(handlers.QuizResultResponse{}),
+
+	"SyncRequest":     reflect.TypeOf(handlers.SyncRequest{}),
+	"SyncEvent":       reflect.TypeOf(handlers.SyncEvent{}),
+	"SyncEventResult": reflect.TypeOf(handlers.SyncEventResult{}),
+	"SyncResponse":    reflect.TypeOf(handlers.SyncResponse{}),
+
+	"BibleReference":   reflect.TypeOf(models.BibleReference{}),
+	"Note":             reflect.TypeOf(models.Note{}),
+	"NoteRequest":      reflect.TypeOf(handlers.NoteRequest{}),
+	"NotesResponse":    reflect.TypeOf(handlers.NotesResponse{}),
+	"NoteSearchResult": reflect.TypeOf(models.NoteSearchResult{}),
+
+	"ConfessionProof":           reflect.TypeOf(models.ConfessionProof{}),
+	"ConfessionSection":         reflect.TypeOf(models.ConfessionSection{}),
+	"ConfessionChapter":         reflect.TypeOf(models.ConfessionChapter{}),
+	"ConfessionLink":            reflect.TypeOf(handlers.ConfessionLink{}),
+	"ConfessionSectionItem":     reflect.TypeOf(handlers.ConfessionSectionItem{}),
+	"ConfessionChapterLink":     reflect.TypeOf(handlers.ConfessionChapterLink{}),
+	"ConfessionChapterResponse": reflect.TypeOf(handlers.ConfessionChapterResponse{}),
+
+	"CatechismImportChange":          reflect.TypeOf(catechismimport.Change{}),
+	"CatechismImportDiff":            reflect.TypeOf(catechismimport.Diff{}),
+	"CatechismImportResponse":        reflect.TypeOf(handlers.CatechismImportResponse{}),
+	"UpdateCatechismQuestionRequest": reflect.TypeOf(handlers.UpdateCatechismQuestionRequest{}),
+	"CatechismRevision":              reflect.TypeOf(models.CatechismRevision{}),
+	"TextDiffOp":                     reflect.TypeOf(textdiff.Op{}),
+	"CatechismRevisionDiffResponse":  reflect.TypeOf(handlers.CatechismRevisionDiffResponse{}),
+}
+
+// requestSchemas are only decoded by the handlers, so which fields they
+// require is up to the handler rather than to omitempty
+var requestSchemas = map[string]bool{
+	"RegisterRequest":                true,
+	"LoginRequest":                   true,
+	"SetLocaleRequest":               true,
+	"MarkCompletedRequest":           true,
+	"MarkCatechismCompletedRequest":  true,
+	"SetCatechismModeRequest":        true,
+	"SetCatechismRequest":            true,
+	"SubmitQuizRequest":              true,
+	"QuizAnswer":                     true,
+	"UpdateCatechismQuestionRequest": true,
+	"SyncRequest":                    true,
+	"SyncEvent":                      true,
+	"NoteRequest":                    true,
+	"JoinHouseholdRequest":           true,
+}
+
+type schema = map[string]interface{}
+
+func loadSpec(t *testing.T) schema {
+	t.Helper()
+	data, err := openapi.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec schema
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+func specSchemas(spec schema) schema {
+	return spec["components"].(schema)["schemas"].(schema)
+}
+
+// resolve follows a local $ref, returning the component name with the schema
+func resolve(spec schema, s schema) (string, schema) {
+	ref, ok := s["$ref"].(string)
+	if !ok {
+		return "", s
+	}
+	parts := strings.Split(strings.TrimPrefix(ref, "#/"), "/")
+	var node interface{} = spec
+	for _, part := range parts {
+		node = node.(schema)[part]
+	}
+	return parts[len(parts)-1], node.(schema)
+}
+
+var routeParam = regexp.MustCompile(`:(\w+)`)
+
+// serverPrefix is the path the routes of a path item are served under: the
+// URL of its own servers, when it overrides them, or of the document's
+func serverPrefix(spec, item schema) string {
+	servers, ok := item["servers"].([]interface{})
+	if !ok {
+		servers = spec["servers"].([]interface{})
+	}
+	return strings.TrimSuffix(servers[0].(schema)["url"].(string), "/")
+}
+
+func TestOpenAPIRoutesMatchSpec(t *testing.T) {
+	spec := loadSpec(t)
+	s := newTestServer(t)
+
+	routes := map[string]bool{}
+	for _, route := range mainRoutes {
+		routes[route] = true
+	}
+	for _, route := range s.router.Routes() {
+		// The legacy alias is checked by TestLegacyAPIAlias
+		if strings.HasPrefix(route.Path, handlers.V1Prefix+"/") {
+			routes[route.Method+" "+routeParam.ReplaceAllString(route.Path, "{$1}")] = true
+		}
+	}
+
+	documented := map[string]bool{}
+	for path, item := range spec["paths"].(schema) {
+		prefix := serverPrefix(spec, item.(schema))
+		for method := range item.(schema) {
+			if method != "servers" {
+				documented[strings.ToUpper(method)+" "+prefix+path] = true
+			}
+		}
+	}
+
+	for _, route := range sortedKeys(routes) {
+		if !documented[route] {
+			t.Errorf("route %s is not in openapi.yaml", route)
+		}
+	}
+	for _, route := range sortedKeys(documented) {
+		if !routes[route] {
+			t.Errorf("openapi.yaml documents %s, which is not registered", route)
+		}
+	}
+}
+
+func TestOpenAPISchemasMatchTypes(t *testing.T) {
+	spec := loadSpec(t)
+
+	for name, raw := range specSchemas(spec) {
+		s := raw.(schema)
+		typ, ok := specTypes[name]
+		if !ok {
+			if s["type"] == "object" {
+				t.Errorf("schema %s has no Go type in specTypes", name)
+			}
+			continue
+		}
+		checkStruct(t, spec, name, s, typ)
+	}
+	for name := range specTypes {
+		if _, ok := specSchemas(spec)[name]; !ok {
+			t.Errorf("specTypes has %s, which is not in openapi.yaml", name)
+		}
+	}
+}
+
+type jsonField struct {
+	typ       reflect.Type
+	omitempty bool
+}
+
+// jsonFields lists the fields encoding/json writes for typ, promoting the
+// fields of embedded structs the way it does
+func jsonFields(typ reflect.Type) map[string]jsonField {
+	fields := map[string]jsonField{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			for key, value := range jsonFields(embedded) {
+				if _, ok := fields[key]; !ok {
+					fields[key] = value
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = jsonField{typ: field.Type, omitempty: strings.Contains(options, "omitempty")}
+	}
+	return fields
+}
+
+func checkStruct(t *testing.T, spec schema, name string, s schema, typ reflect.Type) {
+	t.Helper()
+
+	properties, _ := s["properties"].(schema)
+	required := map[string]bool{}
+	if list, ok := s["required"].([]interface{}); ok {
+		for _, field := range list {
+			required[field.(string)] = true
+		}
+	}
+
+	fields := jsonFields(typ)
+	for _, fieldName := range sortedKeys(fields) {
+		field := fields[fieldName]
+		property, ok := properties[fieldName].(schema)
+		if !ok {
+			t.Errorf("%s: field %q of %s is not in the schema", name, fieldName, typ)
+			continue
+		}
+		if !requestSchemas[name] && required[fieldName] == field.omitempty {
+			t.Errorf("%s: field %q is required=%v but omitempty=%v", name, fieldName, required[fieldName], field.omitempty)
+		}
+		checkType(t, spec, name+"."+fieldName, property, field.typ)
+	}
+
+	if s["additionalProperties"] == false {
+		for _, property := range sortedKeys(properties) {
+			if _, ok := fields[property]; !ok {
+				t.Errorf("%s: property %q has no field in %s", name, property, typ)
+			}
+		}
+	}
+}
+
+// checkType compares the type a property is documented with to the Go type
+// of its field
+func checkType(t *testing.T, spec schema, where string, s schema, typ reflect.Type) {
+	t.Helper()
+
+	if branches, ok := s["anyOf"].([]interface{}); ok {
+		if typ.Kind() != reflect.Pointer {
+			t.Errorf("%s: nullable in the spec but %s can't be null", where, typ)
+		}
+		for _, branch := range branches {
+			if branch.(schema)["type"] != "null" {
+				checkType(t, spec, where, branch.(schema), typ)
+			}
+		}
+		return
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	ref, s := resolve(spec, s)
+	if ref != "" {
+		if want, ok := specTypes[ref]; ok && want != typ {
+			t.Errorf("%s: refers to %s (%s) but is %s", where, ref, want, typ)
+			return
+		}
+	}
+
+	var types []string
+	switch value := s["type"].(type) {
+	case string:
+		types = []string{value}
+	case []interface{}:
+		for _, v := range value {
+			types = append(types, v.(string))
+		}
+	}
+
+	want := jsonType(typ)
+	if want == "" {
+		return
+	}
+	for _, got := range types {
+		if got == want {
+			if want == "array" {
+				checkType(t, spec, where+"[]", s["items"].(schema), typ.Elem())
+			}
+			return
+		}
+	}
+	t.Errorf("%s: documented as %v but %s encodes as %s", where, types, typ, want)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// jsonType is the JSON type values of typ encode to, or "" when it can be anything
+func jsonType(typ reflect.Type) string {
+	if typ == timeType {
+		return "string"
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+	return ""
+}
+
+// validate checks a decoded JSON value against a schema, supporting the
+// subset of JSON Schema openapi.yaml uses
+func validate(spec schema, where string, value interface{}, s schema) []string {
+	_, s = resolve(spec, s)
+
+	if branches, ok := s["anyOf"].([]interface{}); ok {
+		var problems []string
+		for _, branch := range branches {
+			branchProblems := validate(spec, where, value, branch.(schema))
+			if len(branchProblems) == 0 {
+				return nil
+			}
+			problems = append(problems, branchProblems...)
+		}
+		return problems
+	}
+
+	if constant, ok := s["const"]; ok && value != constant {
+		return []string{fmt.Sprintf("%s: got %v, want %v", where, value, constant)}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			found = found || option == value
+		}
+		if !found {
+			return []string{fmt.Sprintf("%s: %v is not one of %v", where, value, enum)}
+		}
+	}
+
+	var types []string
+	switch typ := s["type"].(type) {
+	case string:
+		types = []string{typ}
+	case []interface{}:
+		for _, v := range typ {
+			types = append(types, v.(string))
+		}
+	}
+	if len(types) > 0 && !matchesType(value, types) {
+		return []string{fmt.Sprintf("%s: %s is not %v", where, valueKind(value), types)}
+	}
+
+	var problems []string
+	switch value := value.(type) {
+	case map[string]interface{}:
+		properties, _ := s["properties"].(schema)
+		if list, ok := s["required"].([]interface{}); ok {
+			for _, field := range list {
+				if _, ok := value[field.(string)]; !ok {
+					problems = append(problems, fmt.Sprintf("%s: missing %q", where, field))
+				}
+			}
+		}
+		for _, key := range sortedKeys(value) {
+			if property, ok := properties[key].(schema); ok {
+				problems = append(problems, validate(spec, where+"."+key, value[key], property)...)
+				continue
+			}
+			switch additional := s["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					problems = append(problems, fmt.Sprintf("%s: unexpected property %q", where, key))
+				}
+			case schema:
+				problems = append(problems, validate(spec, where+"."+key, value[key], additional)...)
+			}
+		}
+	case []interface{}:
+		if items, ok := s["items"].(schema); ok {
+			for i, item := range value {
+				problems = append(problems, validate(spec, where+"["+strconv.Itoa(i)+"]", item, items)...)
+			}
+		}
+	}
+	return problems
+}
+
+func matchesType(value interface{}, types []string) bool {
+	kind := valueKind(value)
+	for _, typ := range types {
+		if typ == kind || (typ == "number" && kind == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func valueKind(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// checkResponse sends a request and validates the response body against the
+// schema documented for specPath, the method and the returned status
+func (s *testServer) checkResponse(spec schema, method, path, specPath, token string, body interface{}, wantStatus int) json.RawMessage {
+	s.t.Helper()
+
+	var raw json.RawMessage
+	code := s.do(method, path, token, body, &raw)
+	if code != wantStatus {
+		s.t.Fatalf("%s %s: got %d, want %d: %s", method, path, code, wantStatus, raw)
+	}
+
+	operation, ok := spec["paths"].(schema)[specPath].(schema)[strings.ToLower(method)].(schema)
+	if !ok {
+		s.t.Fatalf("%s %s is not documented", method, specPath)
+	}
+	response, ok := operation["responses"].(schema)[strconv.Itoa(code)].(schema)
+	if !ok {
+		s.t.Errorf("%s %s: status %d is not documented", method, specPath, code)
+		return raw
+	}
+	_, response = resolve(spec, response)
+
+	content := response["content"].(schema)
+	media, ok := content["application/json"].(schema)
+	if !ok {
+		media = content[apierror.ContentType].(schema)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		s.t.Fatal(err)
+	}
+	for _, problem := range validate(spec, method+" "+path, value, media["schema"].(schema)) {
+		s.t.Error(problem)
+	}
+	return raw
+}
+
+func TestOpenAPIResponsesMatchSpec(t *testing.T) {
+	spec := loadSpec(t)
+	s := newTestServer(t)
+	s.seedCatechism(10)
+	s.seedReadingPlans()
+
+	chapters := []*models.ConfessionChapter{
+		{ChapterNumber: 1, Title: "Da Escritura Sagrada", Sections: []*models.ConfessionSection{
+			{SectionNumber: 1, Text: "Ainda que a luz da natureza...", Proofs: []*models.ConfessionProof{{Letter: "a", References: "Rm 2.14-15"}}},
+		}},
+		{ChapterNumber: 2, Title: "De Deus e da Santíssima Trindade", Sections: []*models.ConfessionSection{
+			{SectionNumber: 1, Text: "Há um só Deus vivo e verdadeiro..."},
+		}},
+	}
+	if err := s.repos.Confession.ReplaceAll(context.Background(), chapters); err != nil {
+		t.Fatal(err)
+	}
+	links := []*models.CatechismConfessionLink{{QuestionNumber: 2, ChapterNumber: 1, SectionNumber: 1}}
+	if err := s.repos.Confession.ReplaceLinks(context.Background(), models.CatechismShorter, links); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials := handlers.RegisterRequest{Email: "ana@example.com", Password: "secret123"}
+	var auth handlers.AuthResponse
+	raw := s.checkResponse(spec, http.MethodPost, "/api/v1/auth/register", "/auth/register", "", credentials, http.StatusCreated)
+	if err := json.Unmarshal(raw, &auth); err != nil {
+		t.Fatal(err)
+	}
+	token := auth.Token
+
+	s.checkResponse(spec, http.MethodPost, "/api/v1/auth/register", "/auth/register", "", credentials, http.StatusConflict)
+	s.checkResponse(spec, http.MethodPost, "/api/v1/auth/login", "/auth/login", "", handlers.LoginRequest(credentials), http.StatusOK)
+	s.checkResponse(spec, http.MethodPut, "/api/v1/user/locale", "/user/locale", token, handlers.SetLocaleRequest{Locale: "en"}, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/readings/today", "/readings/today", "", nil, http.StatusUnauthorized)
+
+	s.checkResponse(spec, http.MethodGet, "/api/v1/readings/today", "/readings/today", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodPost, "/api/v1/readings/mark-completed", "/readings/mark-completed", token, handlers.MarkCompletedRequest{Period: "morning"}, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/progress", "/progress", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodDelete, "/api/v1/readings/completions?period=morning", "/readings/completions", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodDelete, "/api/v1/readings/completions?period=night", "/readings/completions", token, nil, http.StatusBadRequest)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/progress/events", "/progress/events", token, nil, http.StatusOK)
+
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/current", "/catechism/current", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodPost, "/api/v1/catechism/mark-completed", "/catechism/mark-completed", token, handlers.MarkCatechismCompletedRequest{}, http.StatusOK)
+	s.checkResponse(spec, http.MethodDelete, "/api/v1/catechism/completions", "/catechism/completions", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/progress", "/catechism/progress", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodPut, "/api/v1/catechism/mode", "/catechism/mode", token, handlers.SetCatechismModeRequest{Mode: models.CatechismModeDaily}, http.StatusOK)
+	s.checkResponse(spec, http.MethodPut, "/api/v1/user/catechism", "/user/catechism", token, handlers.SetCatechismRequest{Catechism: "westminster"}, http.StatusBadRequest)
+	s.checkResponse(spec, http.MethodPut, "/api/v1/user/catechism", "/user/catechism", token, handlers.SetCatechismRequest{Catechism: models.CatechismShorter}, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/questions?per_page=5", "/catechism/questions", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/questions/1", "/catechism/questions/{number}", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/questions/2", "/catechism/questions/{number}", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/questions/99", "/catechism/questions/{number}", token, nil, http.StatusNotFound)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/sections", "/catechism/sections", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/search?q=Deus", "/catechism/search", token, nil, http.StatusOK)
+
+	var quiz handlers.QuizResponse
+	raw = s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/quiz?range=1-10&count=8", "/catechism/quiz", token, nil, http.StatusOK)
+	if err := json.Unmarshal(raw, &quiz); err != nil {
+		t.Fatal(err)
+	}
+	quizPath := fmt.Sprintf("/api/v1/catechism/quiz/%d", quiz.ID)
+	answers := handlers.SubmitQuizRequest{Answers: []models.QuizAnswer{{Exercise: 0, Text: "resposta"}}}
+	s.checkResponse(spec, http.MethodPost, quizPath, "/catechism/quiz/{id}", token, answers, http.StatusOK)
+	s.checkResponse(spec, http.MethodPost, quizPath, "/catechism/quiz/{id}", token, answers, http.StatusConflict)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/catechism/quiz/results", "/catechism/quiz/results", token, nil, http.StatusOK)
+
+	s.checkResponse(spec, http.MethodGet, "/api/v1/confession/chapters", "/confession/chapters", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/confession/chapters/1", "/confession/chapters/{number}", token, nil, http.StatusOK)
+
+	noteRequest := handlers.NoteRequest{
+		Date:           "2025-01-02",
+		Period:         models.PeriodMorning,
+		Reference:      "Gn 2:1-3",
+		QuestionNumber: 1,
+		Body:           "O descanso de Deus no sétimo dia.",
+		Tags:           []string{"Criação"},
+	}
+	var note models.Note
+	raw = s.checkResponse(spec, http.MethodPost, "/api/v1/notes", "/notes", token, noteRequest, http.StatusCreated)
+	if err := json.Unmarshal(raw, &note); err != nil {
+		t.Fatal(err)
+	}
+	notePath := fmt.Sprintf("/api/v1/notes/%d", note.ID)
+	s.checkResponse(spec, http.MethodPost, "/api/v1/notes", "/notes", token, handlers.NoteRequest{Period: models.PeriodMorning}, http.StatusBadRequest)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/notes?book=Gn&chapter=2", "/notes", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/notes/search?q=descanso", "/notes/search", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, notePath, "/notes/{id}", token, nil, http.StatusOK)
+	noteRequest.Reference = ""
+	s.checkResponse(spec, http.MethodPut, notePath, "/notes/{id}", token, noteRequest, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/account/export", "/account/export", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/account/export", "/account/export", "", nil, http.StatusUnauthorized)
+
+	s.checkResponse(spec, http.MethodGet, "/api/v1/household", "/household", token, nil, http.StatusNotFound)
+	s.checkResponse(spec, http.MethodPost, "/api/v1/household/join", "/household/join", token, handlers.JoinHouseholdRequest{InviteCode: "NOPE"}, http.StatusNotFound)
+	var household models.Household
+	raw = s.checkResponse(spec, http.MethodPost, "/api/v1/household", "/household", token, nil, http.StatusCreated)
+	if err := json.Unmarshal(raw, &household); err != nil {
+		t.Fatal(err)
+	}
+	s.checkResponse(spec, http.MethodPost, "/api/v1/household", "/household", token, nil, http.StatusConflict)
+	member := s.register("bia@example.com")
+	s.checkResponse(spec, http.MethodPost, "/api/v1/household/join", "/household/join", member, handlers.JoinHouseholdRequest{InviteCode: household.InviteCode}, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/household", "/household", token, nil, http.StatusOK)
+	if code := s.do(http.MethodDelete, "/api/v1/household", member, nil, nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE /api/v1/household: got %d, want %d", code, http.StatusNoContent)
+	}
+	s.checkResponse(spec, http.MethodDelete, "/api/v1/household", "/household", member, nil, http.StatusNotFound)
+	if code := s.do(http.MethodDelete, notePath, token, nil, nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE %s: got %d, want %d", notePath, code, http.StatusNoContent)
+	}
+	s.checkResponse(spec, http.MethodDelete, notePath, "/notes/{id}", token, nil, http.StatusNotFound)
+
+	recordedAt := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	syncRequest := handlers.SyncRequest{Events: []*handlers.SyncEvent{
+		{ID: "1", Type: models.ProgressTypeReading, Date: "2025-01-02", Period: models.PeriodEvening, RecordedAt: recordedAt},
+		{ID: "2", Type: models.ProgressTypeCatechism, Date: "2025-01-02", Step: models.CatechismStepReciteFull, RecordedAt: recordedAt},
+		{ID: "3", Type: models.ProgressTypeCatechism, Date: "2025-01-02", Step: models.CatechismStepReview, RecordedAt: recordedAt},
+		{ID: "4", Type: models.ProgressTypeReading, Action: models.ProgressActionUnmark, Date: "2025-01-02", Period: models.PeriodEvening, RecordedAt: recordedAt},
+	}}
+	s.checkResponse(spec, http.MethodPost, "/api/v1/sync", "/sync", token, syncRequest, http.StatusOK)
+	s.checkResponse(spec, http.MethodPost, "/api/v1/sync", "/sync", token, handlers.SyncRequest{Cursor: "x"}, http.StatusBadRequest)
+
+	revisions := "/admin/catechism/questions/{number}/revisions"
+	s.checkResponse(spec, http.MethodGet, "/api/v1/admin/catechism/questions/1/revisions", revisions, token, nil, http.StatusForbidden)
+	if _, err := s.repos.Users.SetRole(context.Background(), credentials.Email, models.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+	update := handlers.UpdateCatechismQuestionRequest{QuestionText: "Qual é o fim principal do homem?", AnswerText: "Glorificar a Deus."}
+	s.checkResponse(spec, http.MethodPut, "/api/v1/admin/catechism/questions/1", "/admin/catechism/questions/{number}", token, update, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/admin/catechism/questions/1/revisions", revisions, token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodGet, "/api/v1/admin/catechism/questions/1/revisions/diff", revisions+"/diff", token, nil, http.StatusOK)
+	s.checkResponse(spec, http.MethodPost, "/api/v1/admin/catechism/questions/1/revisions/1/revert", revisions+"/{revision}/revert", token, nil, http.StatusOK)
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	s := newTestServer(t)
+
+	var spec schema
+	if code := s.do(http.MethodGet, "/api/v1/openapi.json", "", nil, &spec); code != http.StatusOK {
+		t.Fatalf("openapi.json: got %d, want %d", code, http.StatusOK)
+	}
+	if spec["openapi"] != "3.1.0" {
+		t.Errorf("openapi version: got %v", spec["openapi"])
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "openapi.json") {
+		t.Errorf("docs: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/printsheet"
+	"biblia-am-pm/internal/repository"
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrintHandler struct {
+	catechismRepo   repository.CatechismRepository
+	readingPlanRepo repository.ReadingPlanRepository
+	userRepo        repository.UserRepository
+	location        *time.Location
+}
+
+func NewPrintHandler(
+	catechismRepo repository.CatechismRepository,
+	readingPlanRepo repository.ReadingPlanRepository,
+	userRepo repository.UserRepository,
+	location *time.Location,
+) *PrintHandler {
+	return &PrintHandler{
+		catechismRepo:   catechismRepo,
+		readingPlanRepo: readingPlanRepo,
+		userRepo:        userRepo,
+		location:        location,
+	}
+}
+
+// GetWeekPDF renders the printable sheet for the week containing ?date=YYYY-MM-DD
+// (defaults to today), with the question of the catechism the user follows
+func (h *PrintHandler) GetWeekPDF(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	catechism, err := getUserCatechism(c.Request.Context(), h.userRepo, userID)
+	if err != nil {
+		apierror.Internal(c, "Failed to get catechism", err)
+		return
+	}
+
+	date := localTime(h.location)
+	if value := c.Query("date"); value != "" {
+		parsedDate, err := time.ParseInLocation("2006-01-02", value, date.Location())
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidDate))
+			return
+		}
+		date = parsedDate
+	}
+
+	week, err := printsheet.LoadWeek(c.Request.Context(), date, catechism, h.catechismRepo, h.readingPlanRepo)
+	if err != nil {
+		apierror.Internal(c, "Failed to load week", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := printsheet.Render(&buf, []*printsheet.Week{week}); err != nil {
+		apierror.Internal(c, "Failed to generate PDF", err)
+		return
+	}
+
+	filename := fmt.Sprintf("semana-%s.pdf", week.Start.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientHeader tells which client sent a request, "web" or "mobile", so that
+// the progress log records where each mark came from. Requests without it
+// come from the web app.
+const ClientHeader = "X-Client"
+
+// progressSource returns the source of the progress events of a request
+func progressSource(c *gin.Context) string {
+	if c.GetHeader(ClientHeader) == models.ProgressSourceMobile {
+		return models.ProgressSourceMobile
+	}
+	return models.ProgressSourceWeb
+}
+
+// ProgressHandler serves the log of marks and unmarks behind the progress
+type ProgressHandler struct {
+	progressEventRepo repository.ProgressEventRepository
+	location          *time.Location
+}
+
+func NewProgressHandler(progressEventRepo repository.ProgressEventRepository, location *time.Location) *ProgressHandler {
+	return &ProgressHandler{
+		progressEventRepo: progressEventRepo,
+		location:          location,
+	}
+}
+
+// GetEvents lists the marks and unmarks of a day, today by default, in the
+// order they were recorded
+func (h *ProgressHandler) GetEvents(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	date := localTime(h.location)
+	if value := c.Query("date"); value != "" {
+		date, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidDate))
+			return
+		}
+	}
+
+	events, err := h.progressEventRepo.GetByUserAndDate(c.Request.Context(), userID, date)
+	if err != nil {
+		apierror.Internal(c, "Failed to get progress events", err)
+		return
+	}
+	if events == nil {
+		events = []*models.ProgressEvent{}
+	}
+
+	c.JSON(http.StatusOK, events)
+}
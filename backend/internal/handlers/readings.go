@@ -1,26 +1,36 @@
 package handlers
 
 import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/metrics"
 	"biblia-am-pm/internal/middleware"
 	"biblia-am-pm/internal/models"
 	"biblia-am-pm/internal/repository"
-	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ReadingsHandler struct {
-	readingPlanRepo  *repository.ReadingPlanRepository
-	userProgressRepo *repository.UserProgressRepository
+	readingPlanRepo   repository.ReadingPlanRepository
+	userProgressRepo  repository.UserProgressRepository
+	progressEventRepo repository.ProgressEventRepository
+	location          *time.Location
 }
 
-func NewReadingsHandler() *ReadingsHandler {
+func NewReadingsHandler(
+	readingPlanRepo repository.ReadingPlanRepository,
+	userProgressRepo repository.UserProgressRepository,
+	progressEventRepo repository.ProgressEventRepository,
+	location *time.Location,
+) *ReadingsHandler {
 	return &ReadingsHandler{
-		readingPlanRepo:  repository.NewReadingPlanRepository(),
-		userProgressRepo: repository.NewUserProgressRepository(),
+		readingPlanRepo:   readingPlanRepo,
+		userProgressRepo:  userProgressRepo,
+		progressEventRepo: progressEventRepo,
+		location:          location,
 	}
 }
 
@@ -34,31 +44,22 @@ type TodayReadingsResponse struct {
 
 type MarkCompletedRequest struct {
 	Period string `json:"period"` // "morning" or "evening"
+	Date   string `json:"date"`   // Optional, defaults to today
 }
 
-// getLocalTime returns the current time in the configured timezone
-func getLocalTime() time.Time {
-	tz := os.Getenv("TZ")
-	if tz == "" {
-		tz = "America/Sao_Paulo" // Default timezone
-	}
-	loc, err := time.LoadLocation(tz)
-	if err != nil {
-		// Fallback to UTC if timezone is invalid
-		loc = time.UTC
-	}
-	now := time.Now().In(loc)
-	return now
+// localTime returns the current time in the configured timezone
+func localTime(location *time.Location) time.Time {
+	return time.Now().In(location)
 }
 
 func (h *ReadingsHandler) GetTodayReadings(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
 
-	now := getLocalTime()
+	now := localTime(h.location)
 	dayOfYear := now.YearDay()
 	hour := now.Hour()
 
@@ -72,29 +73,25 @@ func (h *ReadingsHandler) GetTodayReadings(c *gin.Context) {
 		period = "all"
 	}
 
-	// Debug: log current time and period
-	tz := os.Getenv("TZ")
-	if tz == "" {
-		tz = "America/Sao_Paulo"
-	}
-	log.Printf("[DEBUG] Timezone: %s, Current time: %s, Hour: %d, Period: %s", tz, now.Format("2006-01-02 15:04:05 MST"), hour, period)
+	logging.FromContext(c.Request.Context()).Debug("today's readings period",
+		"timezone", h.location.String(), "time", now.Format(time.RFC3339), "period", period)
 
 	// Get reading plan for today
-	plan, err := h.readingPlanRepo.GetByDayOfYear(dayOfYear)
+	plan, err := h.readingPlanRepo.GetByDayOfYear(c.Request.Context(), dayOfYear)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reading plan"})
+		apierror.Internal(c, "Failed to get reading plan", err)
 		return
 	}
 
 	if plan == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Reading plan not found for today"})
+		apierror.Abort(c, apierror.New(apierror.CodePlanNotFound))
 		return
 	}
 
 	// Get user progress for today
-	progress, err := h.userProgressRepo.GetByUserAndDate(userID, now)
+	progress, err := h.userProgressRepo.GetByUserAndDate(c.Request.Context(), userID, now)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get progress"})
+		apierror.Internal(c, "Failed to get progress", err)
 		return
 	}
 
@@ -123,66 +120,89 @@ func (h *ReadingsHandler) GetTodayReadings(c *gin.Context) {
 func (h *ReadingsHandler) MarkCompleted(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
 
 	var req MarkCompletedRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
 		return
 	}
 
-	if req.Period != "morning" && req.Period != "evening" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Period must be 'morning' or 'evening'"})
+	h.recordCompletion(c, userID, models.ProgressActionMark, req.Date, req.Period)
+}
+
+// UnmarkCompleted undoes the completion of a period, by default today's
+func (h *ReadingsHandler) UnmarkCompleted(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
 
-	now := getLocalTime()
-	dayOfYear := now.YearDay()
+	h.recordCompletion(c, userID, models.ProgressActionUnmark, c.Query("date"), c.Query("period"))
+}
 
-	// Get reading plan for today
-	plan, err := h.readingPlanRepo.GetByDayOfYear(dayOfYear)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reading plan"})
+// recordCompletion marks or unmarks a period of the reading of date, today
+// when empty, and answers with the day's progress
+func (h *ReadingsHandler) recordCompletion(c *gin.Context, userID int, action string, date string, period string) {
+	if !models.IsValidPeriod(period) {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidPeriod))
 		return
 	}
 
-	if plan == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Reading plan not found for today"})
-		return
+	day := localTime(h.location)
+	if date != "" {
+		parsed, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidDate))
+			return
+		}
+		day = parsed
 	}
 
-	// Get or create progress
-	progress, err := h.userProgressRepo.GetByUserAndDate(userID, now)
+	plan, err := h.readingPlanRepo.GetByDayOfYear(c.Request.Context(), day.YearDay())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get progress"})
+		apierror.Internal(c, "Failed to get reading plan", err)
 		return
 	}
 
-	if progress == nil {
-		progress = &models.UserProgress{
-			UserID:           userID,
-			ReadingPlanID:    plan.ID,
-			Date:             now,
-			MorningCompleted: false,
-			EveningCompleted: false,
+	if plan == nil {
+		if date == "" {
+			apierror.Abort(c, apierror.New(apierror.CodePlanNotFound))
+		} else {
+			apierror.Abort(c, apierror.New(apierror.CodePlanNotFoundForDate, date))
 		}
+		return
 	}
 
-	// Update progress based on period
-	if req.Period == "morning" {
-		progress.MorningCompleted = true
-	} else {
-		progress.EveningCompleted = true
+	// Marking a period already completed keeps its earlier time
+	event := &models.ProgressEvent{
+		Type:          models.ProgressTypeReading,
+		Date:          day,
+		ReadingPlanID: plan.ID,
+		Period:        period,
+		Action:        action,
+		OccurredAt:    time.Now(),
+		Source:        progressSource(c),
+	}
+	if err := h.progressEventRepo.Record(c.Request.Context(), userID, []*models.ProgressEvent{event}); err != nil {
+		apierror.Internal(c, "Failed to save progress", err)
+		return
+	}
+	if event.Applied && action == models.ProgressActionMark {
+		metrics.RecordReadingCompleted(period)
 	}
 
-	// Save progress
-	err = h.userProgressRepo.CreateOrUpdate(progress)
+	progress, err := h.userProgressRepo.GetByUserAndDate(c.Request.Context(), userID, day)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save progress"})
+		apierror.Internal(c, "Failed to get progress", err)
 		return
 	}
+	if progress == nil {
+		progress = &models.UserProgress{UserID: userID, ReadingPlanID: plan.ID, Date: day}
+	}
 
 	c.JSON(http.StatusOK, progress)
 }
@@ -190,13 +210,13 @@ func (h *ReadingsHandler) MarkCompleted(c *gin.Context) {
 func (h *ReadingsHandler) GetProgress(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
 		return
 	}
 
-	progresses, err := h.userProgressRepo.GetUserProgress(userID)
+	progresses, err := h.userProgressRepo.GetUserProgress(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get progress"})
+		apierror.Internal(c, "Failed to get progress", err)
 		return
 	}
 
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/openapi"
+	"biblia-am-pm/internal/pubsub"
+	"biblia-am-pm/internal/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1Prefix is where the v1 routes are mounted. Links in v1 responses point
+// under it, whichever prefix the request came through.
+const V1Prefix = "/api/v1"
+
+// RegisterV1 wires the v1 API handlers and their repositories into r, using
+// the JWT secret and timezone from cfg. Progress changes are published to
+// broker for the event streams. Paths are relative to r, so the same routes
+// can be mounted under V1Prefix and under a deprecated alias.
+func RegisterV1(r gin.IRouter, repos *repository.Repositories, broker pubsub.Broker, cfg *config.Config) {
+	location := cfg.Location()
+	progressEvents := pubsub.PublishRecorded(repos.ProgressEvents, broker)
+	authHandler := NewAuthHandler(repos.Users, cfg.Auth.JWTSecret)
+	readingsHandler := NewReadingsHandler(repos.ReadingPlans, repos.UserProgress, progressEvents, location)
+	catechismHandler := NewCatechismHandler(
+		repos.Catechism,
+		repos.CatechismProgress,
+		progressEvents,
+		repos.CatechismQuizzes,
+		repos.CatechismSections,
+		repos.Confession,
+		repos.Users,
+		location,
+	)
+	adminHandler := NewAdminHandler(repos.Catechism, repos.CatechismSections)
+	printHandler := NewPrintHandler(repos.Catechism, repos.ReadingPlans, repos.Users, location)
+	confessionHandler := NewConfessionHandler(repos.Confession, repos.Users)
+	progressHandler := NewProgressHandler(progressEvents, location)
+	syncHandler := NewSyncHandler(repos.ReadingPlans, repos.Catechism, repos.Users, repos.Sync, progressEvents)
+	eventsHandler := NewEventsHandler(broker, repos.Households)
+	householdHandler := NewHouseholdHandler(repos.Households)
+	notesHandler := NewNotesHandler(repos.Notes, repos.ReadingPlans, repos.Catechism, repos.Users, location)
+	accountHandler := NewAccountHandler(repos.Users, repos.UserProgress, repos.CatechismProgress, repos.ProgressEvents, repos.Notes)
+	authMiddleware := middleware.AuthMiddleware(repos.Users, cfg.Auth.JWTSecret)
+	idempotency := middleware.Idempotency(repos.Idempotency, time.Duration(cfg.Server.IdempotencyKeyTTL))
+
+	public := r.Group("")
+	{
+		// Public routes
+		public.POST("/auth/register", authHandler.Register)
+		public.POST("/auth/login", authHandler.Login)
+
+		// API documentation
+		public.GET("/openapi.json", openapi.ServeSpec)
+		public.GET("/docs", openapi.DocsHandler("openapi.json"))
+	}
+
+	// Protected routes - create separate group with auth middleware
+	protected := r.Group("")
+	protected.Use(authMiddleware, idempotency)
+	{
+		protected.GET("/readings/today", readingsHandler.GetTodayReadings)
+		protected.POST("/readings/mark-completed", readingsHandler.MarkCompleted)
+		protected.DELETE("/readings/completions", readingsHandler.UnmarkCompleted)
+		protected.GET("/progress", readingsHandler.GetProgress)
+		protected.GET("/progress/events", progressHandler.GetEvents)
+		protected.GET("/events", eventsHandler.Stream)
+		protected.PUT("/user/locale", authHandler.SetLocale)
+		protected.PUT("/user/catechism", catechismHandler.SetCatechism)
+		protected.GET("/account/export", accountHandler.Export)
+
+		// Household whose members follow each other's progress
+		protected.GET("/household", householdHandler.Get)
+		protected.POST("/household", householdHandler.Create)
+		protected.POST("/household/join", householdHandler.Join)
+		protected.DELETE("/household", householdHandler.Leave)
+
+		// Catechism routes
+		protected.GET("/catechism/current", catechismHandler.GetCurrentQuestion)
+		protected.POST("/catechism/mark-completed", catechismHandler.MarkAsCompleted)
+		protected.DELETE("/catechism/completions", catechismHandler.UnmarkCompleted)
+		protected.GET("/catechism/progress", catechismHandler.GetProgress)
+		protected.PUT("/catechism/mode", catechismHandler.SetMode)
+		protected.GET("/catechism/questions", catechismHandler.ListQuestions)
+		protected.GET("/catechism/questions/:number", catechismHandler.GetQuestion)
+		protected.GET("/catechism/sections", catechismHandler.GetSections)
+		protected.GET("/catechism/search", catechismHandler.Search)
+		protected.GET("/catechism/quiz", catechismHandler.GetQuiz)
+		protected.GET("/catechism/quiz/results", catechismHandler.GetQuizResults)
+		protected.POST("/catechism/quiz/:id", catechismHandler.SubmitQuiz)
+
+		// Confession routes
+		protected.GET("/confession/chapters", confessionHandler.GetChapters)
+		protected.GET("/confession/chapters/:number", confessionHandler.GetChapter)
+
+		// Reading journal
+		protected.GET("/notes", notesHandler.List)
+		protected.POST("/notes", notesHandler.Create)
+		protected.GET("/notes/search", notesHandler.Search)
+		protected.GET("/notes/:id", notesHandler.Get)
+		protected.PUT("/notes/:id", notesHandler.Update)
+		protected.DELETE("/notes/:id", notesHandler.Delete)
+
+		// Print routes
+		protected.GET("/print/week.pdf", printHandler.GetWeekPDF)
+
+		// Offline sync of the mobile clients
+		protected.POST("/sync", syncHandler.Sync)
+	}
+
+	// Admin routes
+	admin := r.Group("/admin")
+	admin.Use(authMiddleware, middleware.RequireRole(models.RoleAdmin), idempotency)
+	{
+		admin.POST("/catechism/import", adminHandler.ImportCatechism)
+		admin.PUT("/catechism/questions/:number", adminHandler.UpdateCatechismQuestion)
+		admin.GET("/catechism/questions/:number/revisions", adminHandler.GetCatechismRevisions)
+		admin.GET("/catechism/questions/:number/revisions/diff", adminHandler.DiffCatechismRevisions)
+		admin.POST("/catechism/questions/:number/revisions/:revision/revert", adminHandler.RevertCatechismQuestion)
+	}
+}
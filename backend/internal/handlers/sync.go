@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/metrics"
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/schedule"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSyncEvents limits the events of a single sync request
+const maxSyncEvents = 500
+
+// maxClockSkew is how far in the future a client's recorded_at may be, to
+// allow for clocks slightly ahead of the server's
+const maxClockSkew = 5 * time.Minute
+
+// Statuses of the events of a sync request
+const (
+	// SyncStatusApplied means the event changed the progress
+	SyncStatusApplied = "applied"
+	// SyncStatusUnchanged means the event didn't change the progress, such as
+	// a completion the progress already had at the same time or earlier
+	SyncStatusUnchanged = "unchanged"
+	// SyncStatusRejected means the event is invalid and was ignored
+	SyncStatusRejected = "rejected"
+)
+
+// SyncHandler merges the marks and unmarks recorded by offline clients
+type SyncHandler struct {
+	readingPlanRepo   repository.ReadingPlanRepository
+	catechismRepo     repository.CatechismRepository
+	userRepo          repository.UserRepository
+	syncRepo          repository.SyncRepository
+	progressEventRepo repository.ProgressEventRepository
+}
+
+func NewSyncHandler(
+	readingPlanRepo repository.ReadingPlanRepository,
+	catechismRepo repository.CatechismRepository,
+	userRepo repository.UserRepository,
+	syncRepo repository.SyncRepository,
+	progressEventRepo repository.ProgressEventRepository,
+) *SyncHandler {
+	return &SyncHandler{
+		readingPlanRepo:   readingPlanRepo,
+		catechismRepo:     catechismRepo,
+		userRepo:          userRepo,
+		syncRepo:          syncRepo,
+		progressEventRepo: progressEventRepo,
+	}
+}
+
+// SyncRequest carries the events recorded since the last sync and the
+// cursor returned by it, empty on the first sync
+type SyncRequest struct {
+	Cursor string       `json:"cursor"`
+	Events []*SyncEvent `json:"events"`
+}
+
+// SyncEvent is a mark or unmark recorded by the client, a mark when action is
+// empty. Readings have a period; catechism events are about the step of the
+// question of their date, the scheduled one when step is empty.
+type SyncEvent struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Action     string `json:"action,omitempty"`
+	Date       string `json:"date"`
+	Period     string `json:"period,omitempty"`
+	Step       string `json:"step,omitempty"`
+	RecordedAt string `json:"recorded_at"`
+}
+
+// SyncEventResult tells what became of an event. Problem explains why a
+// rejected event was ignored.
+type SyncEventResult struct {
+	ID      string            `json:"id"`
+	Status  string            `json:"status"`
+	Problem *apierror.Problem `json:"problem,omitempty"`
+}
+
+// SyncResponse holds the result of each event and the progress rows changed
+// after the request cursor, including the ones changed by the events. Cursor
+// is sent with the next sync.
+type SyncResponse struct {
+	Cursor    string                      `json:"cursor"`
+	Results   []*SyncEventResult          `json:"results"`
+	Readings  []*models.UserProgress      `json:"readings"`
+	Catechism []*models.CatechismProgress `json:"catechism"`
+}
+
+// Sync merges the client's marks and unmarks into its progress and answers
+// with what changed since the client's cursor. A period or step is completed
+// at the earliest mark any device recorded after its last unmark, so sending
+// the same events again, or from several devices in any order, ends in the
+// same progress.
+func (h *SyncHandler) Sync(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+		return
+	}
+
+	var req SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+		return
+	}
+
+	after := int64(-1)
+	if req.Cursor != "" {
+		after, err = strconv.ParseInt(req.Cursor, 10, 64)
+		if err != nil || after < 0 {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidSyncCursor))
+			return
+		}
+	}
+
+	if len(req.Events) > maxSyncEvents {
+		apierror.Abort(c, apierror.New(apierror.CodeTooManySyncEvents, maxSyncEvents))
+		return
+	}
+
+	resolver := &syncResolver{h: h, c: c, userID: userID, now: time.Now()}
+	locale := apierror.RequestLocale(c)
+	results := make([]*SyncEventResult, len(req.Events))
+	var events []*models.ProgressEvent
+	resolved := make([]*models.ProgressEvent, len(req.Events))
+
+	for i, event := range req.Events {
+		results[i] = &SyncEventResult{ID: event.ID}
+		progressEvent, apiErr, err := resolver.resolve(event)
+		if err != nil {
+			apierror.Internal(c, "Failed to resolve sync event", err)
+			return
+		}
+		if apiErr != nil {
+			results[i].Status = SyncStatusRejected
+			results[i].Problem = apiErr.Problem(locale)
+			continue
+		}
+		resolved[i] = progressEvent
+		events = append(events, progressEvent)
+	}
+
+	if len(events) > 0 {
+		if err := h.progressEventRepo.Record(c.Request.Context(), userID, events); err != nil {
+			apierror.Internal(c, "Failed to apply sync events", err)
+			return
+		}
+	}
+
+	for i, event := range resolved {
+		if event == nil {
+			continue
+		}
+		if !event.Applied {
+			results[i].Status = SyncStatusUnchanged
+			continue
+		}
+		results[i].Status = SyncStatusApplied
+		if event.Action != models.ProgressActionMark {
+			continue
+		}
+		if event.Type == models.ProgressTypeReading {
+			metrics.RecordReadingCompleted(event.Period)
+		} else {
+			metrics.RecordCatechismStepCompleted(event.Step)
+		}
+	}
+
+	changes, err := h.syncRepo.GetChanges(c.Request.Context(), userID, after)
+	if err != nil {
+		apierror.Internal(c, "Failed to get sync changes", err)
+		return
+	}
+
+	response := SyncResponse{
+		Cursor:    strconv.FormatInt(changes.Cursor, 10),
+		Results:   results,
+		Readings:  changes.Readings,
+		Catechism: changes.Catechism,
+	}
+	if response.Readings == nil {
+		response.Readings = []*models.UserProgress{}
+	}
+	if response.Catechism == nil {
+		response.Catechism = []*models.CatechismProgress{}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// syncResolver resolves the events of a sync request to the periods and steps
+// they are about. What every catechism event needs is loaded once, on the
+// first one.
+type syncResolver struct {
+	h      *SyncHandler
+	c      *gin.Context
+	userID int
+	now    time.Time
+
+	catechismLoaded bool
+	catechism       string
+	totalQuestions  int
+	mode            string
+}
+
+// resolve returns the progress event of a sync event, or the API error it is
+// rejected with. err is set when the lookup itself failed.
+func (r *syncResolver) resolve(event *SyncEvent) (*models.ProgressEvent, *apierror.Error, error) {
+	if event.Type != models.ProgressTypeReading && event.Type != models.ProgressTypeCatechism {
+		return nil, apierror.New(apierror.CodeInvalidSyncEventType, event.Type, models.ProgressTypeReading, models.ProgressTypeCatechism), nil
+	}
+
+	action := event.Action
+	if action == "" {
+		action = models.ProgressActionMark
+	}
+	if !models.IsValidProgressAction(action) {
+		return nil, apierror.New(apierror.CodeInvalidSyncEventAction, action, models.ProgressActionMark, models.ProgressActionUnmark), nil
+	}
+
+	date, err := time.Parse("2006-01-02", event.Date)
+	if err != nil {
+		return nil, apierror.New(apierror.CodeInvalidDate), nil
+	}
+
+	recordedAt, err := time.Parse(time.RFC3339, event.RecordedAt)
+	if err != nil || recordedAt.After(r.now.Add(maxClockSkew)) {
+		return nil, apierror.New(apierror.CodeInvalidRecordedAt), nil
+	}
+
+	progressEvent := &models.ProgressEvent{
+		Type:       event.Type,
+		Date:       date,
+		Action:     action,
+		OccurredAt: recordedAt,
+		Source:     models.ProgressSourceSync,
+	}
+	ctx := r.c.Request.Context()
+
+	if event.Type == models.ProgressTypeReading {
+		if !models.IsValidPeriod(event.Period) {
+			return nil, apierror.New(apierror.CodeInvalidPeriod), nil
+		}
+
+		plan, err := r.h.readingPlanRepo.GetByDayOfYear(ctx, date.YearDay())
+		if err != nil {
+			return nil, nil, err
+		}
+		if plan == nil {
+			return nil, apierror.New(apierror.CodePlanNotFoundForDate, event.Date), nil
+		}
+
+		progressEvent.ReadingPlanID = plan.ID
+		progressEvent.Period = event.Period
+		return progressEvent, nil, nil
+	}
+
+	if !r.catechismLoaded {
+		if r.catechism, err = getUserCatechism(ctx, r.h.userRepo, r.userID); err != nil {
+			return nil, nil, err
+		}
+		if r.totalQuestions, err = r.h.catechismRepo.GetMaxQuestionNumber(ctx, r.catechism); err != nil {
+			return nil, nil, err
+		}
+		if r.mode, err = getUserCatechismMode(ctx, r.h.userRepo, r.userID); err != nil {
+			return nil, nil, err
+		}
+		r.catechismLoaded = true
+	}
+	if r.totalQuestions == 0 {
+		return nil, apierror.New(apierror.CodeCatechismNotPopulated), nil
+	}
+
+	question, err := r.h.catechismRepo.GetByQuestionNumber(ctx, r.catechism, schedule.QuestionNumber(date, r.totalQuestions))
+	if err != nil {
+		return nil, nil, err
+	}
+	if question == nil {
+		return nil, apierror.New(apierror.CodeQuestionNotFound), nil
+	}
+
+	step := event.Step
+	if step == "" {
+		step = scheduledStep(r.mode, date)
+	}
+	if !models.IsValidCatechismStep(r.mode, step) {
+		return nil, apierror.New(apierror.CodeInvalidStep, step, r.mode), nil
+	}
+
+	progressEvent.QuestionID = question.ID
+	progressEvent.Step = step
+	return progressEvent, nil, nil
+}
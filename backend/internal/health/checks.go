@@ -0,0 +1,55 @@
+package health
+
+import (
+	"biblia-am-pm/internal/migrate"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Database checks that the database answers a ping
+func Database(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// Migrations checks that the schema is at the newest migration this binary knows
+func Migrations(migrator *migrate.Migrator) CheckFunc {
+	return func(ctx context.Context) error {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("%d pending migrations, expected version %d", len(pending), migrator.Latest())
+		}
+		return nil
+	}
+}
+
+// Populated checks that count reports at least one row, so an instance with
+// an empty table doesn't receive traffic. command is the one that fills it.
+func Populated(count func(ctx context.Context) (int, error), command string) CheckFunc {
+	return func(ctx context.Context) error {
+		total, err := count(ctx)
+		if err != nil {
+			return err
+		}
+		if total == 0 {
+			return fmt.Errorf("no rows, run %s", command)
+		}
+		return nil
+	}
+}
+
+// Serving fails once the server has started shutting down
+func Serving(ready func() bool) CheckFunc {
+	return func(ctx context.Context) error {
+		if !ready() {
+			return errors.New("shutting down")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+// Package health serves the liveness (/healthz) and readiness (/readyz)
+// probes. Liveness only says the process is up; readiness runs every
+// registered check with a timeout and reports each one, so orchestrators stop
+// routing traffic to an instance that can't serve it.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout bounds each readiness check
+const DefaultTimeout = 2 * time.Second
+
+const (
+	statusOK   = "ok"
+	statusFail = "fail"
+)
+
+// CheckFunc returns an error when the dependency it checks isn't usable
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Report is the body of the probe responses
+type Report struct {
+	Status string                  `json:"status"`
+	Checks map[string]*CheckResult `json:"checks,omitempty"`
+}
+
+// Checker holds the readiness checks
+type Checker struct {
+	timeout time.Duration
+	checks  []check
+}
+
+// New returns a checker that gives each check up to timeout to finish
+func New(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a readiness check under name
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// Run executes the checks concurrently and returns their results
+func (c *Checker) Run(ctx context.Context) *Report {
+	report := &Report{Status: statusOK, Checks: make(map[string]*CheckResult, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			result := c.runCheck(ctx, chk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[chk.name] = result
+			if result.Status != statusOK {
+				report.Status = statusFail
+			}
+		}(chk)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// runCheck stops waiting at the timeout even when the check ignores ctx
+func (c *Checker) runCheck(ctx context.Context, chk check) *CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- chk.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	result := &CheckResult{Status: statusOK, DurationMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = statusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Liveness answers 200 while the process can serve requests at all
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: statusOK})
+}
+
+// Readiness answers 200 when every check passes and 503 otherwise, with the
+// result of each check
+func (c *Checker) Readiness(ctx *gin.Context) {
+	report := c.Run(ctx.Request.Context())
+
+	status := http.StatusOK
+	if report.Status != statusOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(status, report)
+}
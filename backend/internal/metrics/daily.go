@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"biblia-am-pm/internal/repository"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectTimeout bounds the queries of a scrape
+const collectTimeout = 5 * time.Second
+
+var (
+	readingsCompletedTodayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "readings_completed_today"),
+		"Users who completed the reading of a period (morning or evening) today.",
+		[]string{"period"}, nil,
+	)
+	catechismStepsCompletedTodayDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "catechism_steps_completed_today"),
+		"Catechism steps completed today, across users.",
+		nil, nil,
+	)
+)
+
+// dailyCollector reads today's progress from the database at scrape time, so
+// the gauges agree across replicas and survive restarts
+type dailyCollector struct {
+	userProgressRepo      repository.UserProgressRepository
+	catechismProgressRepo repository.CatechismProgressRepository
+	location              *time.Location
+}
+
+// RegisterDailyProgress exports gauges with today's reading and catechism
+// completions, "today" being the current day in location
+func RegisterDailyProgress(userProgressRepo repository.UserProgressRepository, catechismProgressRepo repository.CatechismProgressRepository, location *time.Location) {
+	Registry.MustRegister(&dailyCollector{
+		userProgressRepo:      userProgressRepo,
+		catechismProgressRepo: catechismProgressRepo,
+		location:              location,
+	})
+}
+
+func (c *dailyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- readingsCompletedTodayDesc
+	ch <- catechismStepsCompletedTodayDesc
+}
+
+func (c *dailyCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+	today := time.Now().In(c.location)
+
+	morning, evening, err := c.userProgressRepo.CountCompletedOnDate(ctx, today)
+	if err != nil {
+		slog.Error("metrics: failed to count today's readings", "error", err)
+		ch <- prometheus.NewInvalidMetric(readingsCompletedTodayDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(readingsCompletedTodayDesc, prometheus.GaugeValue, float64(morning), "morning")
+		ch <- prometheus.MustNewConstMetric(readingsCompletedTodayDesc, prometheus.GaugeValue, float64(evening), "evening")
+	}
+
+	steps, err := c.catechismProgressRepo.CountCompletedOnDate(ctx, today)
+	if err != nil {
+		slog.Error("metrics: failed to count today's catechism steps", "error", err)
+		ch <- prometheus.NewInvalidMetric(catechismStepsCompletedTodayDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(catechismStepsCompletedTodayDesc, prometheus.GaugeValue, float64(steps))
+}
@@ -0,0 +1,131 @@
+// Package metrics exposes the Prometheus metrics of the API: HTTP requests by
+// Gin route, the database connection pool, logins and the daily reading and
+// catechism activity. Collectors live in their own registry, served by
+// Handler on a separate listen address or behind a bearer token.
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "biblia"
+
+// unmatchedRoute labels requests that matched no route, so scanners probing
+// random paths don't create a series per path
+const unmatchedRoute = "unmatched"
+
+// Registry holds every collector of the application
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_logins_total",
+		Help:      "Login attempts by result (success or failure).",
+	}, []string{"result"})
+
+	readingsCompleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readings_marked_completed_total",
+		Help:      "Readings marked as completed by period (morning or evening).",
+	}, []string{"period"})
+
+	catechismStepsCompleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "catechism_steps_marked_completed_total",
+		Help:      "Catechism steps marked as completed by step.",
+	}, []string{"step"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		logins,
+		readingsCompleted,
+		catechismStepsCompleted,
+	)
+}
+
+// Middleware records the count and latency of every request under its route
+// template (/api/catechism/questions/:number), not the raw path
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RecordLogin counts a login attempt
+func RecordLogin(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	logins.WithLabelValues(result).Inc()
+}
+
+// RecordReadingCompleted counts a reading marked as completed in a period
+func RecordReadingCompleted(period string) {
+	readingsCompleted.WithLabelValues(period).Inc()
+}
+
+// RecordCatechismStepCompleted counts a catechism step marked as completed
+func RecordCatechismStepCompleted(step string) {
+	catechismStepsCompleted.WithLabelValues(step).Inc()
+}
+
+// RegisterDB exports the connection pool statistics of db (DB.Stats)
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RequireToken only lets through requests with "Authorization: Bearer token"
+func RequireToken(token string, next http.Handler) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
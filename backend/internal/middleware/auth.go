@@ -1,9 +1,10 @@
 package middleware
 
 import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/logging"
 	"biblia-am-pm/internal/repository"
-	"net/http"
-	"os"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -11,26 +12,24 @@ import (
 )
 
 const UserIDKey = "userID"
+const UserRoleKey = "userRole"
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware validates the JWT signed with secret and loads the user's role with userRepo
+func AuthMiddleware(userRepo repository.UserRepository, secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required", "path": c.Request.URL.Path})
+			apierror.Abort(c, apierror.New(apierror.CodeAuthHeaderMissing))
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization header format"})
+			apierror.Abort(c, apierror.New(apierror.CodeAuthHeaderInvalid))
 			return
 		}
 
 		tokenString := parts[1]
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "your-secret-key"
-		}
 
 		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -40,47 +39,67 @@ func AuthMiddleware() gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			apierror.Abort(c, apierror.New(apierror.CodeTokenInvalid))
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			apierror.Abort(c, apierror.New(apierror.CodeTokenInvalid))
 			return
 		}
 
 		userIDFloat, ok := claims["user_id"].(float64)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID in token"})
+			apierror.Abort(c, apierror.New(apierror.CodeTokenInvalid))
 			return
 		}
 
 		userID := int(userIDFloat)
 
 		// Verify user exists
-		userRepo := repository.NewUserRepository()
-		user, err := userRepo.GetUserByID(userID)
-		if err != nil || user == nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		user, err := userRepo.GetUserByID(c.Request.Context(), userID)
+		if err != nil {
+			apierror.Internal(c, "Failed to get user", err)
+			return
+		}
+		if user == nil {
+			apierror.Abort(c, apierror.New(apierror.CodeUserNotFound))
 			return
 		}
 
-		// Set user ID in context
+		// Set user ID, role and locale in context, and the user ID in the request logger
 		c.Set(UserIDKey, userID)
+		c.Set(UserRoleKey, user.Role)
+		c.Set(apierror.LocaleKey, user.Locale)
+		c.Request = c.Request.WithContext(logging.With(c.Request.Context(), "user_id", userID))
 		c.Next()
 	}
 }
 
+// RequireRole only lets through users with the given role. It must run after AuthMiddleware.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(UserRoleKey) != role {
+			apierror.Abort(c, apierror.New(apierror.CodeForbidden))
+			return
+		}
+		c.Next()
+	}
+}
+
+// ErrNoUser is returned by GetUserIDFromContext outside of AuthMiddleware
+var ErrNoUser = errors.New("no authenticated user in context")
+
 func GetUserIDFromContext(c *gin.Context) (int, error) {
 	userID, exists := c.Get(UserIDKey)
 	if !exists {
-		return 0, http.ErrMissingFile
+		return 0, ErrNoUser
 	}
 	
 	id, ok := userID.(int)
 	if !ok {
-		return 0, http.ErrMissingFile
+		return 0, ErrNoUser
 	}
 	return id, nil
 }
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers announcing that a route is deprecated
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	LinkHeader        = "Link"
+)
+
+// Deprecated marks every response of a group as deprecated since the given
+// date (RFC 9745) and due to be removed at sunset (RFC 8594). Requests under
+// prefix are pointed to the same path under successor with a
+// rel="successor-version" link.
+func Deprecated(since, sunset time.Time, prefix, successor string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(c *gin.Context) {
+		c.Header(DeprecationHeader, deprecation)
+		c.Header(SunsetHeader, sunsetDate)
+		if rest, ok := strings.CutPrefix(c.Request.URL.Path, prefix); ok {
+			c.Header(LinkHeader, fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, rest))
+		}
+		c.Next()
+	}
+}
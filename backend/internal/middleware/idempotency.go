@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers of idempotent requests
+const (
+	// IdempotencyKeyHeader is a key chosen by the client for a request and
+	// sent again with its retries
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a stored response answering a retry
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength bounds the keys, which are stored as they come
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotentBodySize bounds the body read to fingerprint a request. The
+// largest body of the API is a 5 MB catechism file in a multipart form.
+const maxIdempotentBodySize = 6 << 20
+
+// idempotencyLockTimeout is how long a request holds its key before it
+// answers. A retry after that runs again, in case the first one never will.
+const idempotencyLockTimeout = time.Minute
+
+// Idempotency makes the mutating requests sent with an Idempotency-Key header
+// safe to retry. The first request with a key runs and its response is stored
+// for ttl; retries with the key get that response back, with the
+// Idempotent-Replayed header, instead of running again. A key is bound to its
+// request: reusing it for another method, path or body is an error, as is
+// retrying while the first request is still running. Server errors aren't
+// stored, so those requests can be retried.
+//
+// It must run after AuthMiddleware, since keys belong to the user.
+func Idempotency(repo repository.IdempotencyRepository, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidIdempotencyKey, maxIdempotencyKeyLength))
+			return
+		}
+
+		userID, err := GetUserIDFromContext(c)
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeUnauthorized))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			apierror.Abort(c, apierror.New(apierror.CodeInvalidRequestBody))
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			apierror.Abort(c, apierror.New(apierror.CodeIdempotentRequestTooLarge, maxIdempotentBodySize>>20))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now()
+		record := &models.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint(c.Request, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(idempotencyLockTimeout),
+		}
+
+		reserved, err := repo.Reserve(c.Request.Context(), record)
+		if err != nil {
+			apierror.Internal(c, "Failed to reserve idempotency key", err)
+			return
+		}
+		if !reserved {
+			replay(c, repo, record)
+			return
+		}
+
+		// The client may be gone, which is when the response matters most
+		ctx := context.WithoutCancel(c.Request.Context())
+
+		// A handler that panics never answers: its key is released, so the
+		// retry runs again, before the panic goes on to the recovery middleware
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				releaseKey(ctx, repo, userID, key)
+				panic(recovered)
+			}
+		}()
+
+		writer := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			releaseKey(ctx, repo, userID, key)
+			return
+		}
+
+		record.Status = writer.Status()
+		record.ContentType = writer.Header().Get("Content-Type")
+		record.Body = writer.body.Bytes()
+		record.ExpiresAt = time.Now().Add(ttl)
+		if err := repo.Complete(ctx, record); err != nil {
+			logging.FromContext(ctx).Error("failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// releaseKey frees a reserved key whose request failed, logging the errors
+func releaseKey(ctx context.Context, repo repository.IdempotencyRepository, userID int, key string) {
+	if err := repo.Release(ctx, userID, key); err != nil {
+		logging.FromContext(ctx).Error("failed to release idempotency key", "error", err)
+	}
+}
+
+// replay answers a request whose key is already taken with the stored response
+func replay(c *gin.Context, repo repository.IdempotencyRepository, request *models.IdempotencyRecord) {
+	stored, err := repo.Get(c.Request.Context(), request.UserID, request.Key)
+	if err != nil {
+		apierror.Internal(c, "Failed to get idempotency key", err)
+		return
+	}
+
+	switch {
+	case stored != nil && stored.Fingerprint != request.Fingerprint:
+		apierror.Abort(c, apierror.New(apierror.CodeIdempotencyKeyReused))
+	case stored == nil || stored.Status == 0:
+		// Still running, or released after a failure since Reserve
+		apierror.Abort(c, apierror.New(apierror.CodeIdempotencyKeyInUse))
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(stored.Status, stored.ContentType, stored.Body)
+		c.Abort()
+	}
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// validIdempotencyKey accepts the keys of visible ASCII characters, such as
+// UUIDs, up to maxIdempotencyKeyLength
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < '!' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// fingerprint identifies a request by its method, path, query and body
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+"\n"+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordingWriter keeps a copy of the response body it writes
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// ExpireIdempotencyKeys deletes the expired idempotency keys every interval
+// until ctx is cancelled. It is meant to run as a server worker.
+func ExpireIdempotencyKeys(repo repository.IdempotencyRepository, interval time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				deleted, err := repo.DeleteExpired(ctx, time.Now())
+				if err != nil {
+					logging.FromContext(ctx).Error("failed to delete expired idempotency keys", "error", err)
+					continue
+				}
+				logging.FromContext(ctx).Debug("deleted expired idempotency keys", "count", deleted)
+			}
+		}
+	}
+}
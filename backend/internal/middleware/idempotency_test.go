@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"biblia-am-pm/internal/middleware"
+	"biblia-am-pm/internal/repository/memory"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	repos := memory.New()
+
+	calls := 0
+	router := gin.New()
+	router.Use(
+		gin.RecoveryWithWriter(io.Discard),
+		func(c *gin.Context) { c.Set(middleware.UserIDKey, 1) },
+		middleware.Idempotency(repos.Idempotency, time.Hour),
+	)
+	router.POST("/things", func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{"name":"a"}`))
+		req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(); w.Code != http.StatusInternalServerError {
+		t.Fatalf("panicking request: got %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	// The retry runs again instead of finding the key in use
+	w := send()
+	if w.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("retry: got %d after %d calls, want %d after 2", w.Code, calls, http.StatusCreated)
+	}
+	if w.Header().Get(middleware.IdempotentReplayedHeader) != "" {
+		t.Error("retry after a panic was answered as a replay")
+	}
+
+	w = send()
+	if w.Code != http.StatusCreated || calls != 2 || w.Header().Get(middleware.IdempotentReplayedHeader) != "true" {
+		t.Errorf("second retry: got %d after %d calls, want the stored response", w.Code, calls)
+	}
+	if body := w.Body.String(); body != `{"call":2}` {
+		t.Errorf("replayed body = %s", body)
+	}
+}
@@ -2,12 +2,61 @@ package models
 
 import "time"
 
+// Catechism modes
+const (
+	// CatechismModeWeekly reviews the week's question once a day
+	CatechismModeWeekly = "weekly"
+	// CatechismModeDaily follows a schedule of steps over the week
+	CatechismModeDaily = "daily"
+)
+
+// Catechism progress steps
+const (
+	CatechismStepReview     = "review"
+	CatechismStepRead       = "read"
+	CatechismStepReciteHalf = "recite_half"
+	CatechismStepReciteFull = "recite_full"
+)
+
+// DailyCatechismSchedule is the step of each weekday in daily mode, indexed by time.Weekday
+var DailyCatechismSchedule = [7]string{
+	time.Sunday:    CatechismStepRead,
+	time.Monday:    CatechismStepRead,
+	time.Tuesday:   CatechismStepRead,
+	time.Wednesday: CatechismStepReciteHalf,
+	time.Thursday:  CatechismStepReciteHalf,
+	time.Friday:    CatechismStepReciteFull,
+	time.Saturday:  CatechismStepReciteFull,
+}
+
+// IsValidCatechismMode reports whether mode is a known catechism mode
+func IsValidCatechismMode(mode string) bool {
+	return mode == CatechismModeWeekly || mode == CatechismModeDaily
+}
+
+// IsValidCatechismStep reports whether step can be recorded in the given mode
+func IsValidCatechismStep(mode, step string) bool {
+	if mode == CatechismModeDaily {
+		return step == CatechismStepRead || step == CatechismStepReciteHalf || step == CatechismStepReciteFull
+	}
+	return step == CatechismStepReview
+}
+
 type CatechismProgress struct {
 	ID          int       `json:"id"`
 	UserID    int       `json:"user_id"`
 	QuestionID  int       `json:"question_id"`
 	Date        time.Time `json:"date"`
+	Step        string    `json:"step"`
 	Completed   bool      `json:"completed"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// ChangeSeq is the user's sync sequence number of the last change to the row
+	ChangeSeq int64 `json:"-"`
 }
 
+// SetCompletion sets the completion time of the step, nil when it isn't
+// completed, and reports whether the progress changed. The time is kept in
+// UTC, as for reading periods.
+func (p *CatechismProgress) SetCompletion(completedAt *time.Time) bool {
+	return setCompletion(&p.Completed, &p.CompletedAt, completedAt)
+}
@@ -1,9 +1,30 @@
 package models
 
+// Catechisms that can be loaded into westminster_catechism
+const (
+	CatechismShorter = "shorter"
+	CatechismLarger  = "larger"
+)
+
 type CatechismQuestion struct {
 	ID            int    `json:"id"`
 	QuestionNumber int   `json:"question_number"`
 	QuestionText   string `json:"question_text"`
 	AnswerText     string `json:"answer_text"`
+	Revision       int    `json:"revision"`
+	Catechism      string `json:"catechism"`
+}
+
+// CatechismSearchResult is a question matching a full-text search, with the
+// matching words highlighted in the snippets
+type CatechismSearchResult struct {
+	Question        *CatechismQuestion `json:"question"`
+	Rank            float64            `json:"rank"`
+	QuestionSnippet string             `json:"question_snippet"`
+	AnswerSnippet   string             `json:"answer_snippet"`
 }
 
+// IsValidCatechism reports whether name is a known catechism
+func IsValidCatechism(name string) bool {
+	return name == CatechismShorter || name == CatechismLarger
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Quiz exercise types
+const (
+	QuizExerciseRecall         = "recall"
+	QuizExerciseMultipleChoice = "multiple_choice"
+	QuizExerciseFillBlank      = "fill_blank"
+	QuizExerciseQuestionNumber = "question_number"
+)
+
+// QuizExercise is a single generated exercise, including its solution.
+// Solutions are stored with the quiz and never sent to the client before grading.
+type QuizExercise struct {
+	Type           string   `json:"type"`
+	QuestionNumber int      `json:"question_number"`
+	Prompt         string   `json:"prompt"`
+	Text           string   `json:"text,omitempty"`
+	Options        []string `json:"options,omitempty"`
+	CorrectOption  int      `json:"correct_option"`
+	Blanks         []string `json:"blanks,omitempty"`
+	Answer         string   `json:"answer"`
+}
+
+// QuizAnswer is the user's answer to one exercise
+type QuizAnswer struct {
+	Exercise int      `json:"exercise"`
+	Text     string   `json:"text,omitempty"`
+	Option   *int     `json:"option,omitempty"`
+	Number   *int     `json:"number,omitempty"`
+	Blanks   []string `json:"blanks,omitempty"`
+}
+
+// QuizExerciseResult is the grading outcome of one exercise
+type QuizExerciseResult struct {
+	Exercise      int     `json:"exercise"`
+	Correct       bool    `json:"correct"`
+	Score         float64 `json:"score"`
+	CorrectAnswer string  `json:"correct_answer"`
+}
+
+type CatechismQuiz struct {
+	ID           int                  `json:"id"`
+	UserID       int                  `json:"user_id"`
+	RangeStart   int                  `json:"range_start"`
+	RangeEnd     int                  `json:"range_end"`
+	Exercises    []QuizExercise       `json:"exercises"`
+	Answers      []QuizAnswer         `json:"answers,omitempty"`
+	Results      []QuizExerciseResult `json:"results,omitempty"`
+	Score        float64              `json:"score"`
+	CorrectCount int                  `json:"correct_count"`
+	CreatedAt    time.Time            `json:"created_at"`
+	SubmittedAt  *time.Time           `json:"submitted_at,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// CatechismRevision is a snapshot of a question's text. A new revision is
+// created every time the text of a question changes.
+type CatechismRevision struct {
+	ID             int       `json:"id"`
+	QuestionID     int       `json:"question_id"`
+	Revision       int       `json:"revision"`
+	QuestionNumber int       `json:"question_number"`
+	QuestionText   string    `json:"question_text"`
+	AnswerText     string    `json:"answer_text"`
+	AuthorID       *int      `json:"author_id,omitempty"`
+	AuthorEmail    string    `json:"author_email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// CatechismSection is a thematic group of consecutive questions (God, Creation, Law, Prayer...)
+type CatechismSection struct {
+	ID            int    `json:"id"`
+	Catechism     string `json:"catechism"`
+	Position      int    `json:"position"`
+	Slug          string `json:"slug"`
+	Title         string `json:"title"`
+	StartQuestion int    `json:"start_question"`
+	EndQuestion   int    `json:"end_question"`
+}
+
+// Contains reports whether the question number belongs to the section
+func (s *CatechismSection) Contains(questionNumber int) bool {
+	return questionNumber >= s.StartQuestion && questionNumber <= s.EndQuestion
+}
+
+// Question study statuses
+const (
+	QuestionStatusNotStarted = "not_started"
+	QuestionStatusCompleted  = "completed"
+	QuestionStatusReviewDue  = "review_due"
+)
+
+// CatechismQuestionStatus summarizes a user's progress on a question
+type CatechismQuestionStatus struct {
+	Status          string     `json:"status"`
+	TimesCompleted  int        `json:"times_completed"`
+	LastCompletedAt *time.Time `json:"last_completed_at,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+// ConfessionProof is a lettered footnote of a section with its scripture references
+type ConfessionProof struct {
+	Letter     string `json:"letter"`
+	References string `json:"references"`
+}
+
+// ConfessionSection is a numbered paragraph of a chapter of the Westminster Confession of Faith
+type ConfessionSection struct {
+	ID            int                `json:"id"`
+	SectionNumber int                `json:"section_number"`
+	Text          string             `json:"text"`
+	Proofs        []*ConfessionProof `json:"proofs"`
+}
+
+// ConfessionChapter is a chapter of the Confession. Sections are only loaded
+// when reading a single chapter.
+type ConfessionChapter struct {
+	ID            int                  `json:"id"`
+	ChapterNumber int                  `json:"chapter_number"`
+	Title         string               `json:"title"`
+	Sections      []*ConfessionSection `json:"sections,omitempty"`
+}
+
+// CatechismConfessionLink relates a catechism question to a section of the Confession
+type CatechismConfessionLink struct {
+	Catechism      string `json:"catechism"`
+	QuestionNumber int    `json:"question_number"`
+	ChapterNumber  int    `json:"chapter_number"`
+	SectionNumber  int    `json:"section_number"`
+}
+
+// ConfessionReference is a Confession section related to a catechism question
+type ConfessionReference struct {
+	ChapterNumber int    `json:"chapter_number"`
+	ChapterTitle  string `json:"chapter_title"`
+	SectionNumber int    `json:"section_number"`
+}
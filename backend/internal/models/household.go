@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Household groups the accounts of people who read together, like a family.
+// Its members follow each other's progress in the event stream.
+type Household struct {
+	ID int `json:"id"`
+	// InviteCode lets another account join the household
+	InviteCode string             `json:"invite_code"`
+	Members    []*HouseholdMember `json:"members"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// HouseholdMember is an account in a household
+type HouseholdMember struct {
+	UserID   int       `json:"user_id"`
+	Email    string    `json:"email"`
+	JoinedAt time.Time `json:"joined_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// IdempotencyRecord is the response to a request sent with an idempotency key,
+// replayed to the retries of the request until it expires
+type IdempotencyRecord struct {
+	UserID int
+	Key    string
+	// Fingerprint identifies the request, so the key can't be reused for another one
+	Fingerprint string
+	// Status is 0 while the first request is still running
+	Status      int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of a note
+const (
+	MaxNoteBodyLength = 20000
+	MaxNoteTags       = 20
+	MaxNoteTagLength  = 40
+)
+
+// BibleReference is a passage of a single book, from Chapter:Verse to
+// EndChapter:EndVerse. Verse is 0 when the passage covers whole chapters.
+// Text is the reference as the reading plan writes it, like "Jo 3:16-18".
+type BibleReference struct {
+	Text       string `json:"text"`
+	Book       string `json:"book"`
+	Chapter    int    `json:"chapter"`
+	Verse      int    `json:"verse,omitempty"`
+	EndChapter int    `json:"end_chapter"`
+	EndVerse   int    `json:"end_verse,omitempty"`
+}
+
+// Covers reports whether the passage includes a chapter of book
+func (r *BibleReference) Covers(book string, chapter int) bool {
+	return r.Book == book && r.Chapter <= chapter && chapter <= r.EndChapter
+}
+
+// Note is a reflection of a user, written in markdown, on a period of a day
+// of the reading plan. It may be about a passage, a catechism question or
+// both.
+type Note struct {
+	ID            int             `json:"id"`
+	UserID        int             `json:"user_id"`
+	Date          time.Time       `json:"date"`
+	ReadingPlanID int             `json:"reading_plan_id"`
+	Period        string          `json:"period"`
+	Reference     *BibleReference `json:"reference,omitempty"`
+	// QuestionID is 0 when the note isn't about a question. QuestionNumber
+	// and Catechism are loaded with it.
+	QuestionID     int       `json:"question_id,omitempty"`
+	QuestionNumber int       `json:"question_number,omitempty"`
+	Catechism      string    `json:"catechism,omitempty"`
+	Body           string    `json:"body"`
+	Tags           []string  `json:"tags"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NoteFilter narrows a listing of notes. Zero fields don't filter; Chapter
+// is only used together with Book.
+type NoteFilter struct {
+	Date    *time.Time
+	Book    string
+	Chapter int
+	Tag     string
+}
+
+// NoteSearchResult is a note matching a full-text search, with the matching
+// words of the body highlighted in the snippet
+type NoteSearchResult struct {
+	Note    *Note   `json:"note"`
+	Rank    float64 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// NormalizeTag trims and lowercases a tag
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags normalizes tags, dropping the empty and repeated ones, and
+// sorts them as they are listed. ok is false when there are more than
+// MaxNoteTags or one is longer than MaxNoteTagLength.
+func NormalizeTags(tags []string) (normalized []string, ok bool) {
+	normalized = []string{}
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxNoteTagLength {
+			return nil, false
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+	return normalized, len(normalized) <= MaxNoteTags
+}
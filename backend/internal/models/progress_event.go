@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Types of progress, which are also the types of the sync events
+const (
+	ProgressTypeReading   = "reading"
+	ProgressTypeCatechism = "catechism"
+)
+
+// Actions of the progress events
+const (
+	ProgressActionMark   = "mark"
+	ProgressActionUnmark = "unmark"
+)
+
+// Sources of the progress events
+const (
+	ProgressSourceWeb    = "web"
+	ProgressSourceMobile = "mobile"
+	ProgressSourceSync   = "sync"
+	// ProgressSourceMigration marks the completions recorded before the log
+	ProgressSourceMigration = "migration"
+)
+
+// IsValidProgressAction reports whether action is a known progress action
+func IsValidProgressAction(action string) bool {
+	return action == ProgressActionMark || action == ProgressActionUnmark
+}
+
+// ProgressEvent is an entry of the append-only log of marks and unmarks. The
+// progress rows are a projection of the log: a period or step is completed at
+// the time given by CompletionTime for its events.
+type ProgressEvent struct {
+	ID     int       `json:"id"`
+	UserID int       `json:"user_id"`
+	Type   string    `json:"type"`
+	Date   time.Time `json:"date"`
+	// ReadingPlanID and Period are set for readings
+	ReadingPlanID int    `json:"reading_plan_id,omitempty"`
+	Period        string `json:"period,omitempty"`
+	// QuestionID and Step are set for catechism steps
+	QuestionID int    `json:"question_id,omitempty"`
+	Step       string `json:"step,omitempty"`
+	Action     string `json:"action"`
+	// OccurredAt is when the user marked or unmarked, which for sync events is
+	// when the client recorded it
+	OccurredAt time.Time `json:"occurred_at"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Applied is set when recording the event changed the progress
+	Applied bool `json:"-"`
+}
+
+// SameAs reports whether e records the same action, at the same time, as other
+// did for the same period or step, as when a client sends an event again
+func (e *ProgressEvent) SameAs(other *ProgressEvent) bool {
+	return e.Action == other.Action && e.OccurredAt.Equal(other.OccurredAt)
+}
+
+// CompletionTime projects the events of a single period or step onto its
+// completion time: the earliest mark after the last unmark, or nil when there
+// is none. A mark at the time of an unmark is undone by it. The result doesn't
+// depend on the order of the events, so devices merging the same events in any
+// order end in the same progress.
+func CompletionTime(events []*ProgressEvent) *time.Time {
+	var lastUnmark *time.Time
+	for _, event := range events {
+		if event.Action == ProgressActionUnmark && (lastUnmark == nil || event.OccurredAt.After(*lastUnmark)) {
+			lastUnmark = &event.OccurredAt
+		}
+	}
+
+	var completedAt *time.Time
+	for _, event := range events {
+		if event.Action != ProgressActionMark || (lastUnmark != nil && !event.OccurredAt.After(*lastUnmark)) {
+			continue
+		}
+		if completedAt == nil || event.OccurredAt.Before(*completedAt) {
+			at := event.OccurredAt.UTC()
+			completedAt = &at
+		}
+	}
+	return completedAt
+}
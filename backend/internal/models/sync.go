@@ -0,0 +1,9 @@
+package models
+
+// SyncChanges holds the progress rows of a user changed after a cursor
+type SyncChanges struct {
+	// Cursor is the sequence number of the last change included
+	Cursor    int64
+	Readings  []*UserProgress
+	Catechism []*CatechismProgress
+}
@@ -2,10 +2,21 @@ package models
 
 import "time"
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID        int       `json:"id"`
-	Email     string    `json:"email"`
-	Password  string    `json:"-"`
+	ID            int    `json:"id"`
+	Email         string `json:"email"`
+	Password      string `json:"-"`
+	Role          string `json:"role"`
+	CatechismMode string `json:"catechism_mode"`
+	// Catechism is the catechism the user follows, shorter or larger
+	Catechism string `json:"catechism"`
+	// Locale is the language of the API messages, empty to follow Accept-Language
+	Locale    string    `json:"locale"`
 	CreatedAt time.Time `json:"created_at"`
 }
-
@@ -2,6 +2,17 @@ package models
 
 import "time"
 
+// Reading periods
+const (
+	PeriodMorning = "morning"
+	PeriodEvening = "evening"
+)
+
+// IsValidPeriod reports whether period is a reading period that can be completed
+func IsValidPeriod(period string) bool {
+	return period == PeriodMorning || period == PeriodEvening
+}
+
 type UserProgress struct {
 	ID              int       `json:"id"`
 	UserID          int       `json:"user_id"`
@@ -9,6 +20,60 @@ type UserProgress struct {
 	Date            time.Time `json:"date"`
 	MorningCompleted bool     `json:"morning_completed"`
 	EveningCompleted bool     `json:"evening_completed"`
+	MorningCompletedAt *time.Time `json:"morning_completed_at,omitempty"`
+	EveningCompletedAt *time.Time `json:"evening_completed_at,omitempty"`
 	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	// ChangeSeq is the user's sync sequence number of the last change to the row
+	ChangeSeq int64 `json:"-"`
 }
 
+// SetPeriod sets the completion time of a period, nil when it isn't
+// completed, and reports whether the progress changed. The day is completed
+// when its last period was. Times are kept in UTC, as TIMESTAMP columns drop
+// the time zone.
+func (p *UserProgress) SetPeriod(period string, completedAt *time.Time) bool {
+	completed, periodCompletedAt := &p.MorningCompleted, &p.MorningCompletedAt
+	if period == PeriodEvening {
+		completed, periodCompletedAt = &p.EveningCompleted, &p.EveningCompletedAt
+	}
+
+	if !setCompletion(completed, periodCompletedAt, completedAt) {
+		return false
+	}
+
+	p.CompletedAt = nil
+	if p.MorningCompleted && p.EveningCompleted {
+		p.CompletedAt = latest(p.MorningCompletedAt, p.EveningCompletedAt)
+	}
+	return true
+}
+
+// setCompletion sets a completed flag and its time to completedAt, in UTC, and
+// reports whether either changed
+func setCompletion(completed *bool, at **time.Time, completedAt *time.Time) bool {
+	if completedAt == nil {
+		if !*completed && *at == nil {
+			return false
+		}
+		*completed, *at = false, nil
+		return true
+	}
+
+	if *completed && *at != nil && (*at).Equal(*completedAt) {
+		return false
+	}
+	utc := completedAt.UTC()
+	*completed, *at = true, &utc
+	return true
+}
+
+// latest returns the latest of the times that are set, or nil
+func latest(times ...*time.Time) *time.Time {
+	var last *time.Time
+	for _, t := range times {
+		if t != nil && (last == nil || t.After(*last)) {
+			last = t
+		}
+	}
+	return last
+}
@@ -0,0 +1,79 @@
+// Package openapi serves the OpenAPI 3.1 description of the API and a
+// Swagger UI page to browse it. The document is written by hand in
+// openapi.yaml and converted to JSON once, at startup; the contract test in
+// the handlers package keeps it in sync with the routes and response types.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	specErr  error
+)
+
+// JSON returns the OpenAPI document encoded as JSON
+func JSON() ([]byte, error) {
+	specOnce.Do(func() {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(specYAML, &doc); err != nil {
+			specErr = fmt.Errorf("parse openapi.yaml: %w", err)
+			return
+		}
+		specJSON, specErr = json.Marshal(doc)
+	})
+	return specJSON, specErr
+}
+
+// ServeSpec responds with the OpenAPI document
+func ServeSpec(c *gin.Context) {
+	spec, err := JSON()
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+// docsPage loads Swagger UI from a CDN and points it at the spec route
+const docsPage = `<!DOCTYPE html>
+<html lang="pt-BR">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Bíblia AM/PM API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "%s",
+      dom_id: "#swagger-ui",
+      persistAuthorization: true
+    });
+  </script>
+</body>
+</html>
+`
+
+// DocsHandler serves a Swagger UI page that loads the spec from specPath
+func DocsHandler(specPath string) gin.HandlerFunc {
+	page := []byte(fmt.Sprintf(docsPage, specPath))
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
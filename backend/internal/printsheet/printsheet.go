@@ -0,0 +1,209 @@
+// Package printsheet renders the printable weekly sheet (catechism question
+// and reading plan) as a PDF. It is shared by the API and the print-weeks CLI.
+package printsheet
+
+import (
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/schedule"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Day is one day of the week with its reading plan (nil when missing)
+type Day struct {
+	Date time.Time
+	Plan *models.ReadingPlan
+}
+
+// Week holds everything printed on one sheet
+type Week struct {
+	Start    time.Time
+	Question *models.CatechismQuestion
+	Days     []Day
+}
+
+// LoadWeek gathers the question of catechism and the seven days of reading
+// plan for the week containing date
+func LoadWeek(ctx context.Context, date time.Time, catechism string, catechismRepo repository.CatechismRepository, readingPlanRepo repository.ReadingPlanRepository) (*Week, error) {
+	start := schedule.WeekStart(date)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	week := &Week{Start: start}
+
+	totalQuestions, err := catechismRepo.GetMaxQuestionNumber(ctx, catechism)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total questions: %w", err)
+	}
+	if totalQuestions > 0 {
+		week.Question, err = catechismRepo.GetByQuestionNumber(ctx, catechism, schedule.QuestionNumber(start, totalQuestions))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get question: %w", err)
+		}
+	}
+
+	for i := 0; i < 7; i++ {
+		day := start.AddDate(0, 0, i)
+		plan, err := readingPlanRepo.GetByDayOfYear(ctx, day.YearDay())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get reading plan for %s: %w", day.Format("2006-01-02"), err)
+		}
+		week.Days = append(week.Days, Day{Date: day, Plan: plan})
+	}
+
+	return week, nil
+}
+
+// proofRef matches the start of a scripture reference such as "Rm 11.36" or "1Co 10.31"
+var proofRef = regexp.MustCompile(`(?:^|\s)((?:[1-3]\s?)?[A-ZÁÉÍÓÚÊÔÂ][a-záéíóúâêôãõç]{0,3}\.?\s?\d+[.:]\d+)`)
+
+// proofToken matches the tokens allowed in a list of references
+var proofToken = regexp.MustCompile(`^(?:[1-3]?[A-ZÁÉÍÓÚÊÔÂ]?[a-záéíóúâêôãõç]{0,3}\.?)?[\d.,;:\-–]*$`)
+
+// SplitProofs separates the scripture proofs listed at the end of an answer,
+// as in the Larger Catechism, from the answer itself
+func SplitProofs(answer string) (string, string) {
+	for _, match := range proofRef.FindAllStringSubmatchIndex(answer, -1) {
+		start := match[2]
+		rest := answer[start:]
+		isProofList := true
+		for _, token := range strings.Fields(rest) {
+			if !proofToken.MatchString(token) {
+				isProofList = false
+				break
+			}
+		}
+		if isProofList {
+			return strings.TrimSpace(answer[:start]), strings.Trim(strings.TrimSpace(rest), ";,")
+		}
+	}
+	return answer, ""
+}
+
+var weekdayNames = [7]string{"Dom", "Seg", "Ter", "Qua", "Qui", "Sex", "Sáb"}
+
+const (
+	pageMargin   = 15.0
+	lineHeight   = 6.0
+	checkboxSize = 4.0
+)
+
+// Render writes a PDF with one page per week
+func Render(w io.Writer, weeks []*Week) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle("Bíblia AM/PM", true)
+	// Core fonts use cp1252, which covers Portuguese accents
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for _, week := range weeks {
+		pdf.AddPage()
+		renderWeek(pdf, tr, week)
+	}
+
+	return pdf.Output(w)
+}
+
+func checkbox(pdf *fpdf.Fpdf, x, y float64) {
+	pdf.Rect(x, y+(lineHeight-checkboxSize)/2, checkboxSize, checkboxSize, "D")
+}
+
+func renderWeek(pdf *fpdf.Fpdf, tr func(string) string, week *Week) {
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pageMargin
+	end := week.Start.AddDate(0, 0, 6)
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(contentWidth, 10, tr("Bíblia AM/PM"), "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(contentWidth, lineHeight, tr(fmt.Sprintf("Semana de %s a %s",
+		week.Start.Format("02/01/2006"), end.Format("02/01/2006"))), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	// Catechism
+	pdf.SetFont("Helvetica", "B", 13)
+	if week.Question == nil {
+		pdf.CellFormat(contentWidth, 8, tr("Catecismo"), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.MultiCell(contentWidth, lineHeight, tr("O catecismo ainda não foi cadastrado."), "", "L", false)
+	} else {
+		answer, proofs := SplitProofs(week.Question.AnswerText)
+
+		pdf.CellFormat(contentWidth, 8, tr(fmt.Sprintf("Catecismo - Pergunta %d", week.Question.QuestionNumber)), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.MultiCell(contentWidth, lineHeight, tr(week.Question.QuestionText), "", "L", false)
+		pdf.Ln(1)
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(contentWidth, lineHeight, tr(answer), "", "L", false)
+		if proofs != "" {
+			pdf.Ln(1)
+			pdf.SetFont("Helvetica", "I", 9)
+			pdf.MultiCell(contentWidth, 5, tr("Provas: "+proofs), "", "L", false)
+		}
+
+		pdf.Ln(3)
+		pdf.SetFont("Helvetica", "", 10)
+		dayWidth := contentWidth / 7
+		y := pdf.GetY()
+		for i, day := range week.Days {
+			x := pageMargin + float64(i)*dayWidth
+			checkbox(pdf, x, y)
+			pdf.SetXY(x+checkboxSize+1.5, y)
+			pdf.CellFormat(dayWidth-checkboxSize-1.5, lineHeight, tr(weekdayNames[day.Date.Weekday()]), "", 0, "L", false, 0, "")
+		}
+		pdf.SetXY(pageMargin, y+lineHeight)
+	}
+	pdf.Ln(6)
+
+	// Reading plan
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(contentWidth, 8, tr("Plano de Leitura"), "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	dateWidth := 32.0
+	periodWidth := (contentWidth - dateWidth) / 2
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(dateWidth, lineHeight, tr("Dia"), "B", 0, "L", false, 0, "")
+	pdf.CellFormat(periodWidth, lineHeight, tr("Manhã (AT + Salmos)"), "B", 0, "L", false, 0, "")
+	pdf.CellFormat(periodWidth, lineHeight, tr("Noite (NT + Provérbios)"), "B", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, day := range week.Days {
+		morning, evening := "-", "-"
+		if day.Plan != nil {
+			morning = joinRefs(day.Plan.OldTestamentRef, day.Plan.PsalmsRef)
+			evening = joinRefs(day.Plan.NewTestamentRef, day.Plan.ProverbsRef)
+		}
+
+		y := pdf.GetY()
+		pdf.CellFormat(dateWidth, lineHeight*1.5, tr(fmt.Sprintf("%s %s", weekdayNames[day.Date.Weekday()], day.Date.Format("02/01"))), "B", 0, "L", false, 0, "")
+		for i, refs := range []string{morning, evening} {
+			x := pageMargin + dateWidth + float64(i)*periodWidth
+			checkbox(pdf, x, y+lineHeight*0.25)
+			pdf.SetXY(x+checkboxSize+1.5, y)
+			pdf.CellFormat(periodWidth-checkboxSize-1.5, lineHeight*1.5, tr(refs), "B", 0, "L", false, 0, "")
+		}
+		pdf.SetXY(pageMargin, y+lineHeight*1.5)
+	}
+}
+
+func joinRefs(refs ...string) string {
+	var parts []string
+	for _, ref := range refs {
+		if ref = strings.TrimSpace(ref); ref != "" {
+			parts = append(parts, ref)
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " · ")
+}
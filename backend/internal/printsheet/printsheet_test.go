@@ -0,0 +1,102 @@
+package printsheet_test
+
+import (
+	"biblia-am-pm/internal/printsheet"
+	"testing"
+)
+
+func TestSplitProofs(t *testing.T) {
+	tests := []struct {
+		name       string
+		answer     string
+		wantAnswer string
+		wantProofs string
+	}{
+		{
+			"shorter 1, no proofs",
+			"O fim principal do homem é glorificar a Deus, e gozá-lo para sempre.",
+			"O fim principal do homem é glorificar a Deus, e gozá-lo para sempre.",
+			"",
+		},
+		{
+			"shorter 45, quotation",
+			`O primeiro mandamento é: "Não terás outros deuses além de mim."`,
+			`O primeiro mandamento é: "Não terás outros deuses além de mim."`,
+			"",
+		},
+		{
+			"larger 6 without its proofs",
+			"As Escrituras revelam o que Deus é, quantas pessoas há na Divindade, os seus decretos e como Ele os executa.",
+			"As Escrituras revelam o que Deus é, quantas pessoas há na Divindade, os seus decretos e como Ele os executa.",
+			"",
+		},
+		{
+			"reference inside the answer",
+			"Como diz Rm 11.36, dele, por ele e para ele são todas as coisas.",
+			"Como diz Rm 11.36, dele, por ele e para ele são todas as coisas.",
+			"",
+		},
+		{
+			"larger 41, no proofs",
+			"O nosso Mediador foi chamado Jesus, porque salva o seu povo dos pecados.",
+			"O nosso Mediador foi chamado Jesus, porque salva o seu povo dos pecados.",
+			"",
+		},
+		{
+			"larger 163, a cross-reference to the Confession",
+			"As partes de um sacramento são duas: uma, um sinal exterior e sensível usado segundo a própria instituição de Cristo, a outra, uma graça inferior e espiritual significada pelo sinal. Veja-se Confissão de Fé, Cap. XXVII e as passagens ali citadas.",
+			"As partes de um sacramento são duas: uma, um sinal exterior e sensível usado segundo a própria instituição de Cristo, a outra, uma graça inferior e espiritual significada pelo sinal. Veja-se Confissão de Fé, Cap. XXVII e as passagens ali citadas.",
+			"",
+		},
+		{
+			// Proofs followed by a note aren't a list at the end, so they stay in the answer
+			"larger 153, a note after the proofs",
+			"Para escaparmos à ira e maldição de Deus, em que incorremos pela transgressão da lei, ele exige de nós o arrependimento para com Deus, a fé em nosso Senhor Jesus Cristo e o uso diligente de todos os meios exteriores pelos quais Cristo nos comunica os benefícios de sua mediação. At 20.21; Mc 1.15; Jo 3.18. Vejam-se os textos citados sob a questão 154.",
+			"Para escaparmos à ira e maldição de Deus, em que incorremos pela transgressão da lei, ele exige de nós o arrependimento para com Deus, a fé em nosso Senhor Jesus Cristo e o uso diligente de todos os meios exteriores pelos quais Cristo nos comunica os benefícios de sua mediação. At 20.21; Mc 1.15; Jo 3.18. Vejam-se os textos citados sob a questão 154.",
+			"",
+		},
+		{
+			"larger 98, one reference",
+			"A lei moral acha-se resumidamente compreendida nos dez mandamentos, que foram dados pela voz de Deus no monte Sinai e por Ele escritos em duas tábuas de pedra, e estão registrados no capítulo vigésimo do Êxodo. Os quatro primeiros mandamentos contêm os nossos deveres para com Deus e os outros seis os nossos deveres para com o homem. Dt 10.4;",
+			"A lei moral acha-se resumidamente compreendida nos dez mandamentos, que foram dados pela voz de Deus no monte Sinai e por Ele escritos em duas tábuas de pedra, e estão registrados no capítulo vigésimo do Êxodo. Os quatro primeiros mandamentos contêm os nossos deveres para com Deus e os outros seis os nossos deveres para com o homem.",
+			"Dt 10.4",
+		},
+		{
+			"larger 1, several references",
+			"O fim supremo e principal do homem é glorificar a Deus e gozá-lo para sempre. Rm 11.36; 1Co 10.31; Sl 73.24-26;",
+			"O fim supremo e principal do homem é glorificar a Deus e gozá-lo para sempre.",
+			"Rm 11.36; 1Co 10.31; Sl 73.24-26",
+		},
+		{
+			"larger 2, references separated by a colon",
+			"A própria luz da natureza no espírito do homem e as obras de Deus claramente manifestam que existe um Deus; porém só a sua Palavra e o seu Espírito o revelam de um modo suficiente e eficazmente aos homens para a sua salvação. Rm 1.19-20; 1Co 2.9-10: 2Tm 3.15-17.",
+			"A própria luz da natureza no espírito do homem e as obras de Deus claramente manifestam que existe um Deus; porém só a sua Palavra e o seu Espírito o revelam de um modo suficiente e eficazmente aos homens para a sua salvação.",
+			"Rm 1.19-20; 1Co 2.9-10: 2Tm 3.15-17.",
+		},
+		{
+			"larger 10, chapters joined by e",
+			"O Pai gerou o Filho, o Filho foi gerado pelo Pai, e o Espírito Santo procede do Pai e do Filho, desde toda à eternidade. Hb 1.5-6; Jo 1.14 e 15.26;",
+			"O Pai gerou o Filho, o Filho foi gerado pelo Pai, e o Espírito Santo procede do Pai e do Filho, desde toda à eternidade.",
+			"Hb 1.5-6; Jo 1.14 e 15.26",
+		},
+		{
+			"larger 60, many references",
+			"Aqueles que nunca ouviram o Evangelho e não conhecem a Jesus Cristo, nem nEle crêem, não poderão se salvar, por mais diligentes que sejam em conformar as suas vidas à luz da natureza, ou às leis da religião que professam; nem há salvação em nenhum outro, senão em Cristo, que é o único Salvador do seu corpo, a Igreja. Rm 10.14; 2Ts 1.8-9; Ef 2.12; Jo 3.18, e 8.24; 1Co 1.21; Rm 3.20, e 2.14-15; Jo 4.22; At 4.12;",
+			"Aqueles que nunca ouviram o Evangelho e não conhecem a Jesus Cristo, nem nEle crêem, não poderão se salvar, por mais diligentes que sejam em conformar as suas vidas à luz da natureza, ou às leis da religião que professam; nem há salvação em nenhum outro, senão em Cristo, que é o único Salvador do seu corpo, a Igreja.",
+			"Rm 10.14; 2Ts 1.8-9; Ef 2.12; Jo 3.18, e 8.24; 1Co 1.21; Rm 3.20, e 2.14-15; Jo 4.22; At 4.12",
+		},
+		{"empty", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, proofs := printsheet.SplitProofs(tt.answer)
+			if answer != tt.wantAnswer {
+				t.Errorf("answer = %q, want %q", answer, tt.wantAnswer)
+			}
+			if proofs != tt.wantProofs {
+				t.Errorf("proofs = %q, want %q", proofs, tt.wantProofs)
+			}
+		})
+	}
+}
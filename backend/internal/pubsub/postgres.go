@@ -0,0 +1,92 @@
+package pubsub
+
+import (
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// progressChannel is the notification channel the replicas share
+const progressChannel = "progress_events"
+
+// Reconnection delays of the listener after it loses its connection
+const (
+	minReconnectInterval = 10 * time.Second
+	maxReconnectInterval = time.Minute
+)
+
+// listenerPingInterval is how often an idle listener checks its connection
+const listenerPingInterval = 90 * time.Second
+
+// Postgres is a Broker for several replicas. Events are published with
+// NOTIFY and every replica, including the publisher, delivers the ones it is
+// notified of to its local subscribers. Listen must be running for them to
+// be delivered at all.
+type Postgres struct {
+	*Local
+	db  *sql.DB
+	dsn string
+}
+
+// NewPostgres returns a broker publishing through db and listening with its
+// own connection to dsn, since a listener holds a connection of its own
+func NewPostgres(db *sql.DB, dsn string) *Postgres {
+	return &Postgres{Local: NewLocal(), db: db, dsn: dsn}
+}
+
+func (p *Postgres) Publish(ctx context.Context, event *models.ProgressEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode progress event: %w", err)
+	}
+	if _, err := p.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", progressChannel, string(payload)); err != nil {
+		return fmt.Errorf("failed to notify progress event: %w", err)
+	}
+	return nil
+}
+
+// Listen delivers the events notified by every replica until ctx is
+// cancelled. It is meant to run as a server worker. Events notified while
+// the connection is down are lost; the clients reload the progress when
+// their stream is reconnected.
+func (p *Postgres) Listen(ctx context.Context) error {
+	listener := pq.NewListener(p.dsn, minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logging.FromContext(ctx).Warn("progress listener connection failed", "error", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(progressChannel); err != nil {
+		return fmt.Errorf("failed to listen to %s: %w", progressChannel, err)
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			// nil after a reconnection
+			if notification == nil {
+				continue
+			}
+			var event models.ProgressEvent
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				logging.FromContext(ctx).Error("invalid progress notification", "error", err)
+				continue
+			}
+			p.deliver(&event)
+		case <-ticker.C:
+			go listener.Ping()
+		}
+	}
+}
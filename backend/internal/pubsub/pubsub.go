@@ -0,0 +1,147 @@
+// Package pubsub delivers the marks and unmarks of a user to the other
+// devices the user, or the members of their household, have connected, as
+// they are recorded. Local delivers them
+// within a single instance; Postgres sends them through LISTEN/NOTIFY, so they
+// reach the devices connected to any replica.
+package pubsub
+
+import (
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"context"
+	"sync"
+)
+
+// subscriptionBuffer is how many events a subscriber may fall behind by
+// before it is dropped
+const subscriptionBuffer = 32
+
+// Broker publishes progress events to the subscribers of their user
+type Broker interface {
+	// Publish sends event to the subscribers of event.UserID
+	Publish(ctx context.Context, event *models.ProgressEvent) error
+	// Subscribe returns the events published for any of userIDs from now on,
+	// and a function that ends the subscription. The channel is closed when
+	// the subscription ends, when the subscriber falls too far behind, or when
+	// the broker is closed.
+	Subscribe(userIDs ...int) (<-chan *models.ProgressEvent, func())
+	// Close ends every subscription; new ones are closed right away
+	Close()
+}
+
+// Local is a Broker for a single instance: events only reach the
+// subscribers of the process that published them
+type Local struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan *models.ProgressEvent]struct{}
+	// userIDs holds the users each open subscription follows
+	userIDs map[chan *models.ProgressEvent][]int
+	closed  bool
+}
+
+func NewLocal() *Local {
+	return &Local{
+		subscribers: make(map[int]map[chan *models.ProgressEvent]struct{}),
+		userIDs:     make(map[chan *models.ProgressEvent][]int),
+	}
+}
+
+func (l *Local) Publish(ctx context.Context, event *models.ProgressEvent) error {
+	l.deliver(event)
+	return nil
+}
+
+// deliver sends event to the subscribers of its user. A subscriber whose
+// buffer is full is dropped rather than blocking the others; its client
+// reconnects and reloads the progress.
+func (l *Local) deliver(event *models.ProgressEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for events := range l.subscribers[event.UserID] {
+		select {
+		case events <- event:
+		default:
+			l.unsubscribe(events)
+		}
+	}
+}
+
+func (l *Local) Subscribe(userIDs ...int) (<-chan *models.ProgressEvent, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := make(chan *models.ProgressEvent, subscriptionBuffer)
+	if l.closed {
+		close(events)
+		return events, func() {}
+	}
+	for _, userID := range userIDs {
+		if l.subscribers[userID] == nil {
+			l.subscribers[userID] = make(map[chan *models.ProgressEvent]struct{})
+		}
+		l.subscribers[userID][events] = struct{}{}
+	}
+	l.userIDs[events] = append([]int(nil), userIDs...)
+
+	return events, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.unsubscribe(events)
+	}
+}
+
+// unsubscribe closes a subscription that is still open. The caller must hold mu.
+func (l *Local) unsubscribe(events chan *models.ProgressEvent) {
+	userIDs, ok := l.userIDs[events]
+	if !ok {
+		return
+	}
+	for _, userID := range userIDs {
+		delete(l.subscribers[userID], events)
+		if len(l.subscribers[userID]) == 0 {
+			delete(l.subscribers, userID)
+		}
+	}
+	delete(l.userIDs, events)
+	close(events)
+}
+
+func (l *Local) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.closed = true
+	for events := range l.userIDs {
+		l.unsubscribe(events)
+	}
+}
+
+// PublishRecorded wraps repo so that the events that change the progress are
+// published to broker once recorded. Publishing is best effort: the progress
+// is already saved, so a failure is only logged.
+func PublishRecorded(repo repository.ProgressEventRepository, broker Broker) repository.ProgressEventRepository {
+	return &publishingRepository{ProgressEventRepository: repo, broker: broker}
+}
+
+type publishingRepository struct {
+	repository.ProgressEventRepository
+	broker Broker
+}
+
+func (r *publishingRepository) Record(ctx context.Context, userID int, events []*models.ProgressEvent) error {
+	if err := r.ProgressEventRepository.Record(ctx, userID, events); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if !event.Applied {
+			continue
+		}
+		if err := r.broker.Publish(ctx, event); err != nil {
+			logging.FromContext(ctx).Error("failed to publish progress event", "error", err)
+		}
+	}
+	return nil
+}
@@ -1,121 +1,87 @@
 package repository
 
 import (
-	"biblia-am-pm/internal/database"
 	"biblia-am-pm/internal/models"
+	"context"
 	"database/sql"
 	"time"
 )
 
-type CatechismProgressRepository struct{}
+type catechismProgressRepository struct {
+	db *DB
+}
 
-func NewCatechismProgressRepository() *CatechismProgressRepository {
-	return &CatechismProgressRepository{}
+func NewCatechismProgressRepository(db *DB) CatechismProgressRepository {
+	return &catechismProgressRepository{db: db}
 }
 
-func (r *CatechismProgressRepository) GetByUserAndDate(userID int, questionID int, date time.Time) (*models.CatechismProgress, error) {
-	query := `SELECT id, user_id, question_id, date, completed, completed_at 
-	          FROM catechism_progress WHERE user_id = $1 AND question_id = $2 AND date = $3`
-	
-	progress := &models.CatechismProgress{}
-	var completedAt sql.NullTime
-	
-	err := database.DB.QueryRow(query, userID, questionID, date.Format("2006-01-02")).Scan(
-		&progress.ID,
-		&progress.UserID,
-		&progress.QuestionID,
-		&progress.Date,
-		&progress.Completed,
-		&completedAt,
-	)
+const catechismProgressColumns = `id, user_id, question_id, date, step, completed, completed_at, change_seq`
+
+func (r *catechismProgressRepository) GetByUserAndDate(ctx context.Context, userID int, questionID int, date time.Time, step string) (*models.CatechismProgress, error) {
+	return getCatechismProgressTx(ctx, r.db, userID, questionID, date, step)
+}
+
+func getCatechismProgressTx(ctx context.Context, tx DBTX, userID int, questionID int, date time.Time, step string) (*models.CatechismProgress, error) {
+	query := `SELECT ` + catechismProgressColumns + `
+	          FROM catechism_progress WHERE user_id = $1 AND question_id = $2 AND date = $3 AND step = $4`
 	
+	progress, err := scanCatechismProgress(tx.QueryRowContext(ctx, query, userID, questionID, date.Format("2006-01-02"), step))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	
-	if err != nil {
-		return nil, err
-	}
-	
-	if completedAt.Valid {
-		progress.CompletedAt = &completedAt.Time
-	}
-	
-	return progress, nil
+	return progress, err
 }
 
-func (r *CatechismProgressRepository) GetByUserAndQuestionForWeek(userID int, questionID int, weekStart time.Time) ([]*models.CatechismProgress, error) {
+func (r *catechismProgressRepository) GetByUserAndQuestionForWeek(ctx context.Context, userID int, questionID int, weekStart time.Time) ([]*models.CatechismProgress, error) {
 	weekEnd := weekStart.AddDate(0, 0, 6) // 6 days after start (7 days total)
-	query := `SELECT id, user_id, question_id, date, completed, completed_at 
+	query := `SELECT ` + catechismProgressColumns + `
 	          FROM catechism_progress 
 	          WHERE user_id = $1 AND question_id = $2 
 	          AND date >= $3 AND date <= $4 
-	          ORDER BY date`
+	          ORDER BY date, step`
 	
-	rows, err := database.DB.Query(query, userID, questionID, weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	
-	var progresses []*models.CatechismProgress
-	for rows.Next() {
-		progress := &models.CatechismProgress{}
-		var completedAt sql.NullTime
-		
-		err := rows.Scan(
-			&progress.ID,
-			&progress.UserID,
-			&progress.QuestionID,
-			&progress.Date,
-			&progress.Completed,
-			&completedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		
-		if completedAt.Valid {
-			progress.CompletedAt = &completedAt.Time
-		}
-		
-		progresses = append(progresses, progress)
-	}
-	
-	return progresses, rows.Err()
+	return queryCatechismProgress(ctx, r.db, query, userID, questionID, weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
 }
 
-func (r *CatechismProgressRepository) CreateOrUpdate(progress *models.CatechismProgress) error {
-	query := `INSERT INTO catechism_progress (user_id, question_id, date, completed, completed_at)
-	          VALUES ($1, $2, $3, $4, $5)
-	          ON CONFLICT (user_id, question_id, date)
+// saveCatechismProgressTx upserts the progress with a change sequence number
+// taken in tx from nextChangeSeqTx
+func saveCatechismProgressTx(ctx context.Context, tx DBTX, progress *models.CatechismProgress, seq int64) error {
+	query := `INSERT INTO catechism_progress (user_id, question_id, date, step, completed, completed_at, change_seq)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7)
+	          ON CONFLICT (user_id, question_id, date, step)
 	          DO UPDATE SET 
 	            completed = EXCLUDED.completed,
-	            completed_at = EXCLUDED.completed_at
+	            completed_at = EXCLUDED.completed_at,
+	            change_seq = EXCLUDED.change_seq
 	          RETURNING id`
 	
-	var completedAt *time.Time
-	if progress.Completed {
-		now := time.Now()
-		completedAt = &now
-	}
-	
-	err := database.DB.QueryRow(query,
+	err := tx.QueryRowContext(ctx, query,
 		progress.UserID,
 		progress.QuestionID,
 		progress.Date.Format("2006-01-02"),
+		progress.Step,
 		progress.Completed,
-		completedAt,
+		progress.CompletedAt,
+		seq,
 	).Scan(&progress.ID)
-	
-	return err
+	if err != nil {
+		return err
+	}
+
+	progress.ChangeSeq = seq
+	return nil
 }
 
-func (r *CatechismProgressRepository) GetUserProgress(userID int) ([]*models.CatechismProgress, error) {
-	query := `SELECT id, user_id, question_id, date, completed, completed_at 
+func (r *catechismProgressRepository) GetUserProgress(ctx context.Context, userID int) ([]*models.CatechismProgress, error) {
+	query := `SELECT ` + catechismProgressColumns + `
 	          FROM catechism_progress WHERE user_id = $1 ORDER BY date DESC`
 	
-	rows, err := database.DB.Query(query, userID)
+	return queryCatechismProgress(ctx, r.db, query, userID)
+}
+
+func queryCatechismProgress(ctx context.Context, tx DBTX, query string, args ...interface{}) ([]*models.CatechismProgress, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -123,28 +89,77 @@ func (r *CatechismProgressRepository) GetUserProgress(userID int) ([]*models.Cat
 	
 	var progresses []*models.CatechismProgress
 	for rows.Next() {
-		progress := &models.CatechismProgress{}
-		var completedAt sql.NullTime
-		
-		err := rows.Scan(
-			&progress.ID,
-			&progress.UserID,
-			&progress.QuestionID,
-			&progress.Date,
-			&progress.Completed,
-			&completedAt,
-		)
+		progress, err := scanCatechismProgress(rows)
 		if err != nil {
 			return nil, err
 		}
 		
-		if completedAt.Valid {
-			progress.CompletedAt = &completedAt.Time
-		}
-		
 		progresses = append(progresses, progress)
 	}
 	
 	return progresses, rows.Err()
 }
 
+func scanCatechismProgress(row rowScanner) (*models.CatechismProgress, error) {
+	progress := &models.CatechismProgress{}
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&progress.ID,
+		&progress.UserID,
+		&progress.QuestionID,
+		&progress.Date,
+		&progress.Step,
+		&progress.Completed,
+		&completedAt,
+		&progress.ChangeSeq,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.CompletedAt = nullTime(completedAt)
+	return progress, nil
+}
+
+// GetQuestionStatuses summarizes the user's completed days for every question
+// they have marked, keyed by question ID. Status is left for the caller to derive.
+func (r *catechismProgressRepository) GetQuestionStatuses(ctx context.Context, userID int) (map[int]*models.CatechismQuestionStatus, error) {
+	query := `SELECT question_id, COUNT(DISTINCT date), MAX(completed_at)
+	          FROM catechism_progress
+	          WHERE user_id = $1 AND completed = TRUE
+	          GROUP BY question_id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := map[int]*models.CatechismQuestionStatus{}
+	for rows.Next() {
+		var questionID int
+		var lastCompletedAt sql.NullTime
+		status := &models.CatechismQuestionStatus{}
+
+		if err := rows.Scan(&questionID, &status.TimesCompleted, &lastCompletedAt); err != nil {
+			return nil, err
+		}
+
+		if lastCompletedAt.Valid {
+			status.LastCompletedAt = &lastCompletedAt.Time
+		}
+
+		statuses[questionID] = status
+	}
+
+	return statuses, rows.Err()
+}
+
+func (r *catechismProgressRepository) CountCompletedOnDate(ctx context.Context, date time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM catechism_progress WHERE date = $1 AND completed`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, date.Format("2006-01-02")).Scan(&count)
+	return count, err
+}
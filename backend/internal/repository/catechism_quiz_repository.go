@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+type catechismQuizRepository struct {
+	db *DB
+}
+
+func NewCatechismQuizRepository(db *DB) CatechismQuizRepository {
+	return &catechismQuizRepository{db: db}
+}
+
+func (r *catechismQuizRepository) Create(ctx context.Context, quiz *models.CatechismQuiz) error {
+	query := `INSERT INTO catechism_quizzes (user_id, range_start, range_end, exercises, created_at)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING id`
+
+	exercises, err := json.Marshal(quiz.Exercises)
+	if err != nil {
+		return err
+	}
+
+	quiz.CreatedAt = time.Now()
+	return r.db.QueryRowContext(ctx, query,
+		quiz.UserID,
+		quiz.RangeStart,
+		quiz.RangeEnd,
+		exercises,
+		quiz.CreatedAt,
+	).Scan(&quiz.ID)
+}
+
+func (r *catechismQuizRepository) GetByIDAndUser(ctx context.Context, id int, userID int) (*models.CatechismQuiz, error) {
+	query := `SELECT id, user_id, range_start, range_end, exercises, answers, results,
+	                 score, correct_count, created_at, submitted_at
+	          FROM catechism_quizzes WHERE id = $1 AND user_id = $2`
+
+	quiz, err := scanCatechismQuiz(r.db.QueryRowContext(ctx, query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return quiz, nil
+}
+
+// SaveResult stores the graded answers. It only updates quizzes that have not
+// been submitted yet and reports whether the row was updated.
+func (r *catechismQuizRepository) SaveResult(ctx context.Context, quiz *models.CatechismQuiz) (bool, error) {
+	query := `UPDATE catechism_quizzes
+	          SET answers = $1, results = $2, score = $3, correct_count = $4, submitted_at = $5
+	          WHERE id = $6 AND user_id = $7 AND submitted_at IS NULL`
+
+	answers, err := json.Marshal(quiz.Answers)
+	if err != nil {
+		return false, err
+	}
+	results, err := json.Marshal(quiz.Results)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	res, err := r.db.ExecContext(ctx, query, answers, results, quiz.Score, quiz.CorrectCount, now, quiz.ID, quiz.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	quiz.SubmittedAt = &now
+	return true, nil
+}
+
+func (r *catechismQuizRepository) GetSubmittedByUser(ctx context.Context, userID int) ([]*models.CatechismQuiz, error) {
+	query := `SELECT id, user_id, range_start, range_end, exercises, answers, results,
+	                 score, correct_count, created_at, submitted_at
+	          FROM catechism_quizzes
+	          WHERE user_id = $1 AND submitted_at IS NOT NULL
+	          ORDER BY submitted_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quizzes []*models.CatechismQuiz
+	for rows.Next() {
+		quiz, err := scanCatechismQuiz(rows)
+		if err != nil {
+			return nil, err
+		}
+		quizzes = append(quizzes, quiz)
+	}
+
+	return quizzes, rows.Err()
+}
+
+func scanCatechismQuiz(row rowScanner) (*models.CatechismQuiz, error) {
+	quiz := &models.CatechismQuiz{}
+	var exercises []byte
+	var answers, results sql.NullString
+	var submittedAt sql.NullTime
+
+	err := row.Scan(
+		&quiz.ID,
+		&quiz.UserID,
+		&quiz.RangeStart,
+		&quiz.RangeEnd,
+		&exercises,
+		&answers,
+		&results,
+		&quiz.Score,
+		&quiz.CorrectCount,
+		&quiz.CreatedAt,
+		&submittedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(exercises, &quiz.Exercises); err != nil {
+		return nil, err
+	}
+	if answers.Valid {
+		if err := json.Unmarshal([]byte(answers.String), &quiz.Answers); err != nil {
+			return nil, err
+		}
+	}
+	if results.Valid {
+		if err := json.Unmarshal([]byte(results.String), &quiz.Results); err != nil {
+			return nil, err
+		}
+	}
+	if submittedAt.Valid {
+		quiz.SubmittedAt = &submittedAt.Time
+	}
+
+	return quiz, nil
+}
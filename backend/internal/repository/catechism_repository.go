@@ -1,27 +1,32 @@
 package repository
 
 import (
-	"biblia-am-pm/internal/database"
 	"biblia-am-pm/internal/models"
+	"context"
 	"database/sql"
+	"time"
 )
 
-type CatechismRepository struct{}
+type catechismRepository struct {
+	db *DB
+}
 
-func NewCatechismRepository() *CatechismRepository {
-	return &CatechismRepository{}
+func NewCatechismRepository(db *DB) CatechismRepository {
+	return &catechismRepository{db: db}
 }
 
-func (r *CatechismRepository) GetByQuestionNumber(questionNumber int) (*models.CatechismQuestion, error) {
-	query := `SELECT id, question_number, question_text, answer_text 
-	          FROM westminster_catechism WHERE question_number = $1`
+func (r *catechismRepository) GetByQuestionNumber(ctx context.Context, catechism string, questionNumber int) (*models.CatechismQuestion, error) {
+	query := `SELECT id, question_number, question_text, answer_text, current_revision, catechism
+	          FROM westminster_catechism WHERE catechism = $1 AND question_number = $2 AND retired_at IS NULL`
 	
 	question := &models.CatechismQuestion{}
-	err := database.DB.QueryRow(query, questionNumber).Scan(
+	err := r.db.QueryRowContext(ctx, query, catechism, questionNumber).Scan(
 		&question.ID,
 		&question.QuestionNumber,
 		&question.QuestionText,
 		&question.AnswerText,
+		&question.Revision,
+		&question.Catechism,
 	)
 	
 	if err == sql.ErrNoRows {
@@ -35,11 +40,11 @@ func (r *CatechismRepository) GetByQuestionNumber(questionNumber int) (*models.C
 	return question, nil
 }
 
-func (r *CatechismRepository) GetAll() ([]*models.CatechismQuestion, error) {
-	query := `SELECT id, question_number, question_text, answer_text 
-	          FROM westminster_catechism ORDER BY question_number`
+func (r *catechismRepository) GetAll(ctx context.Context, catechism string) ([]*models.CatechismQuestion, error) {
+	query := `SELECT id, question_number, question_text, answer_text, current_revision, catechism
+	          FROM westminster_catechism WHERE catechism = $1 AND retired_at IS NULL ORDER BY question_number`
 	
-	rows, err := database.DB.Query(query)
+	rows, err := r.db.QueryContext(ctx, query, catechism)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +58,8 @@ func (r *CatechismRepository) GetAll() ([]*models.CatechismQuestion, error) {
 			&question.QuestionNumber,
 			&question.QuestionText,
 			&question.AnswerText,
+			&question.Revision,
+			&question.Catechism,
 		)
 		if err != nil {
 			return nil, err
@@ -63,44 +70,347 @@ func (r *CatechismRepository) GetAll() ([]*models.CatechismQuestion, error) {
 	return questions, rows.Err()
 }
 
-func (r *CatechismRepository) Create(question *models.CatechismQuestion) error {
-	query := `INSERT INTO westminster_catechism (question_number, question_text, answer_text) 
-	          VALUES ($1, $2, $3)
-	          ON CONFLICT (question_number) 
-	          DO UPDATE SET 
-	            question_text = EXCLUDED.question_text,
-	            answer_text = EXCLUDED.answer_text
-	          RETURNING id`
-	
-	err := database.DB.QueryRow(query,
+func (r *catechismRepository) GetByQuestionRange(ctx context.Context, catechism string, start, end int) ([]*models.CatechismQuestion, error) {
+	query := `SELECT id, question_number, question_text, answer_text, current_revision, catechism
+	          FROM westminster_catechism
+	          WHERE catechism = $1 AND question_number >= $2 AND question_number <= $3 AND retired_at IS NULL
+	          ORDER BY question_number`
+
+	rows, err := r.db.QueryContext(ctx, query, catechism, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var questions []*models.CatechismQuestion
+	for rows.Next() {
+		question := &models.CatechismQuestion{}
+		err := rows.Scan(
+			&question.ID,
+			&question.QuestionNumber,
+			&question.QuestionText,
+			&question.AnswerText,
+			&question.Revision,
+			&question.Catechism,
+		)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, question)
+	}
+
+	return questions, rows.Err()
+}
+
+// GetPage returns questions of a catechism numbered between start and end,
+// paginated, along with the total number of questions in that range
+func (r *catechismRepository) GetPage(ctx context.Context, catechism string, start, end, limit, offset int) ([]*models.CatechismQuestion, int, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM westminster_catechism
+	                             WHERE catechism = $1 AND question_number >= $2 AND question_number <= $3 AND retired_at IS NULL`,
+		catechism, start, end,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, question_number, question_text, answer_text, current_revision, catechism
+	          FROM westminster_catechism
+	          WHERE catechism = $1 AND question_number >= $2 AND question_number <= $3 AND retired_at IS NULL
+	          ORDER BY question_number
+	          LIMIT $4 OFFSET $5`
+
+	rows, err := r.db.QueryContext(ctx, query, catechism, start, end, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	questions := []*models.CatechismQuestion{}
+	for rows.Next() {
+		question := &models.CatechismQuestion{}
+		err := rows.Scan(
+			&question.ID,
+			&question.QuestionNumber,
+			&question.QuestionText,
+			&question.AnswerText,
+			&question.Revision,
+			&question.Catechism,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		questions = append(questions, question)
+	}
+
+	return questions, total, rows.Err()
+}
+
+// GetAdjacentNumbers returns the numbers of the questions before and after the
+// given one in its catechism, or 0 when there is none. Numbering may have gaps.
+func (r *catechismRepository) GetAdjacentNumbers(ctx context.Context, catechism string, questionNumber int) (int, int, error) {
+	query := `SELECT
+	            (SELECT MAX(question_number) FROM westminster_catechism WHERE catechism = $1 AND question_number < $2 AND retired_at IS NULL),
+	            (SELECT MIN(question_number) FROM westminster_catechism WHERE catechism = $1 AND question_number > $2 AND retired_at IS NULL)`
+
+	var previous, next sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, query, catechism, questionNumber).Scan(&previous, &next); err != nil {
+		return 0, 0, err
+	}
+	return int(previous.Int64), int(next.Int64), nil
+}
+
+// Create inserts or updates a question without an author. See Save.
+func (r *catechismRepository) Create(ctx context.Context, question *models.CatechismQuestion) error {
+	return r.Save(ctx, question, nil)
+}
+
+// Save inserts or updates a question by its catechism and number. Whenever the
+// text changes a new revision is recorded with the given author, while the
+// question keeps its ID so progress rows are unaffected by edits. Saving a
+// retired question restores it.
+func (r *catechismRepository) Save(ctx context.Context, question *models.CatechismQuestion, authorID *int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveQuestionTx(ctx, tx, question, authorID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func saveQuestionTx(ctx context.Context, tx DBTX, question *models.CatechismQuestion, authorID *int) error {
+	if question.Catechism == "" {
+		question.Catechism = models.CatechismShorter
+	}
+
+	var questionText, answerText string
+	var retired bool
+	err := tx.QueryRowContext(ctx, `SELECT id, question_text, answer_text, current_revision, retired_at IS NOT NULL
+	                    FROM westminster_catechism WHERE catechism = $1 AND question_number = $2 FOR UPDATE`,
+		question.Catechism,
+		question.QuestionNumber,
+	).Scan(&question.ID, &questionText, &answerText, &question.Revision, &retired)
+
+	switch {
+	case err == sql.ErrNoRows:
+		question.Revision = 1
+		err = tx.QueryRowContext(ctx, `INSERT INTO westminster_catechism (question_number, question_text, answer_text, current_revision, catechism)
+		                   VALUES ($1, $2, $3, $4, $5)
+		                   RETURNING id`,
+			question.QuestionNumber,
+			question.QuestionText,
+			question.AnswerText,
+			question.Revision,
+			question.Catechism,
+		).Scan(&question.ID)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case questionText == question.QuestionText && answerText == question.AnswerText:
+		if !retired {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE westminster_catechism SET retired_at = NULL WHERE id = $1`, question.ID)
+		return err
+	default:
+		question.Revision++
+		_, err = tx.ExecContext(ctx, `UPDATE westminster_catechism
+		                  SET question_text = $1, answer_text = $2, current_revision = $3, retired_at = NULL
+		                  WHERE id = $4`,
+			question.QuestionText,
+			question.AnswerText,
+			question.Revision,
+			question.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO catechism_revisions (question_id, revision, question_number, question_text, answer_text, author_id, created_at)
+	                  VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		question.ID,
+		question.Revision,
 		question.QuestionNumber,
 		question.QuestionText,
 		question.AnswerText,
-	).Scan(&question.ID)
-	
+		authorID,
+		time.Now(),
+	)
 	return err
 }
 
-func (r *CatechismRepository) CreateBatch(questions []*models.CatechismQuestion) error {
+// ApplyImport saves the given questions into a catechism and retires its
+// questions with the given numbers in a single transaction
+func (r *catechismRepository) ApplyImport(ctx context.Context, catechism string, upserts []*models.CatechismQuestion, removeNumbers []int, authorID *int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, question := range upserts {
+		question.Catechism = catechism
+		if err := saveQuestionTx(ctx, tx, question, authorID); err != nil {
+			return err
+		}
+	}
+
+	retiredAt := time.Now()
+	for _, number := range removeNumbers {
+		_, err := tx.ExecContext(ctx, `UPDATE westminster_catechism SET retired_at = $1
+		                  WHERE catechism = $2 AND question_number = $3 AND retired_at IS NULL`,
+			retiredAt, catechism, number)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Search finds questions whose question or answer text matches the query,
+// using Portuguese stemming and ignoring accents. Results are ranked with
+// matches highlighted in <mark> tags. An empty catechism searches all of them.
+func (r *catechismRepository) Search(ctx context.Context, text string, catechism string, limit int) ([]*models.CatechismSearchResult, error) {
+	query := `SELECT id, question_number, question_text, answer_text, current_revision, catechism,
+	                 ts_rank(search_vector, q) AS rank,
+	                 ts_headline('portuguese_unaccent', question_text, q,
+	                             'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+	                 ts_headline('portuguese_unaccent', answer_text, q,
+	                             'StartSel=<mark>, StopSel=</mark>, MinWords=15, MaxWords=35, MaxFragments=2')
+	          FROM westminster_catechism, websearch_to_tsquery('portuguese_unaccent', $1) q
+	          WHERE search_vector @@ q AND ($2 = '' OR catechism = $2) AND retired_at IS NULL
+	          ORDER BY rank DESC, question_number
+	          LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, text, catechism, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*models.CatechismSearchResult{}
+	for rows.Next() {
+		result := &models.CatechismSearchResult{Question: &models.CatechismQuestion{}}
+		err := rows.Scan(
+			&result.Question.ID,
+			&result.Question.QuestionNumber,
+			&result.Question.QuestionText,
+			&result.Question.AnswerText,
+			&result.Question.Revision,
+			&result.Question.Catechism,
+			&result.Rank,
+			&result.QuestionSnippet,
+			&result.AnswerSnippet,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// GetRevisions returns every revision of a question, newest first
+func (r *catechismRepository) GetRevisions(ctx context.Context, questionID int) ([]*models.CatechismRevision, error) {
+	query := `SELECT r.id, r.question_id, r.revision, r.question_number, r.question_text, r.answer_text,
+	                 r.author_id, COALESCE(u.email, ''), r.created_at
+	          FROM catechism_revisions r
+	          LEFT JOIN users u ON u.id = r.author_id
+	          WHERE r.question_id = $1
+	          ORDER BY r.revision DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, questionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*models.CatechismRevision
+	for rows.Next() {
+		revision, err := scanCatechismRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (r *catechismRepository) GetRevision(ctx context.Context, questionID int, revisionNumber int) (*models.CatechismRevision, error) {
+	query := `SELECT r.id, r.question_id, r.revision, r.question_number, r.question_text, r.answer_text,
+	                 r.author_id, COALESCE(u.email, ''), r.created_at
+	          FROM catechism_revisions r
+	          LEFT JOIN users u ON u.id = r.author_id
+	          WHERE r.question_id = $1 AND r.revision = $2`
+
+	revision, err := scanCatechismRevision(r.db.QueryRowContext(ctx, query, questionID, revisionNumber))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func scanCatechismRevision(row rowScanner) (*models.CatechismRevision, error) {
+	revision := &models.CatechismRevision{}
+	var authorID sql.NullInt64
+
+	err := row.Scan(
+		&revision.ID,
+		&revision.QuestionID,
+		&revision.Revision,
+		&revision.QuestionNumber,
+		&revision.QuestionText,
+		&revision.AnswerText,
+		&authorID,
+		&revision.AuthorEmail,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorID.Valid {
+		id := int(authorID.Int64)
+		revision.AuthorID = &id
+	}
+
+	return revision, nil
+}
+
+func (r *catechismRepository) CreateBatch(ctx context.Context, questions []*models.CatechismQuestion) error {
 	for _, question := range questions {
-		if err := r.Create(question); err != nil {
+		if err := r.Create(ctx, question); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *CatechismRepository) GetTotalCount() (int, error) {
-	query := `SELECT COUNT(*) FROM westminster_catechism`
+func (r *catechismRepository) GetTotalCount(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM westminster_catechism WHERE retired_at IS NULL`
 	var count int
-	err := database.DB.QueryRow(query).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
 	return count, err
 }
 
-func (r *CatechismRepository) GetMaxQuestionNumber() (int, error) {
-	query := `SELECT MAX(question_number) FROM westminster_catechism`
+func (r *catechismRepository) GetMaxQuestionNumber(ctx context.Context, catechism string) (int, error) {
+	query := `SELECT MAX(question_number) FROM westminster_catechism WHERE catechism = $1 AND retired_at IS NULL`
 	var maxNum sql.NullInt64
-	err := database.DB.QueryRow(query).Scan(&maxNum)
+	err := r.db.QueryRowContext(ctx, query, catechism).Scan(&maxNum)
 	if err != nil {
 		return 0, err
 	}
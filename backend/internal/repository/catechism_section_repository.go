@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+)
+
+type catechismSectionRepository struct {
+	db *DB
+}
+
+func NewCatechismSectionRepository(db *DB) CatechismSectionRepository {
+	return &catechismSectionRepository{db: db}
+}
+
+func (r *catechismSectionRepository) GetByCatechism(ctx context.Context, catechism string) ([]*models.CatechismSection, error) {
+	query := `SELECT id, catechism, position, slug, title, start_question, end_question
+	          FROM catechism_sections WHERE catechism = $1 ORDER BY position`
+
+	rows, err := r.db.QueryContext(ctx, query, catechism)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sections := []*models.CatechismSection{}
+	for rows.Next() {
+		section := &models.CatechismSection{}
+		err := rows.Scan(
+			&section.ID,
+			&section.Catechism,
+			&section.Position,
+			&section.Slug,
+			&section.Title,
+			&section.StartQuestion,
+			&section.EndQuestion,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+
+	return sections, rows.Err()
+}
+
+// ReplaceForCatechism replaces every section of a catechism in a single transaction
+func (r *catechismSectionRepository) ReplaceForCatechism(ctx context.Context, catechism string, sections []*models.CatechismSection) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM catechism_sections WHERE catechism = $1`, catechism); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO catechism_sections (catechism, position, slug, title, start_question, end_question)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          RETURNING id`
+	for i, section := range sections {
+		section.Catechism = catechism
+		section.Position = i + 1
+		err := tx.QueryRowContext(ctx, query,
+			section.Catechism,
+			section.Position,
+			section.Slug,
+			section.Title,
+			section.StartQuestion,
+			section.EndQuestion,
+		).Scan(&section.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
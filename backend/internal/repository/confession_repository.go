@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+)
+
+type confessionRepository struct {
+	db *DB
+}
+
+func NewConfessionRepository(db *DB) ConfessionRepository {
+	return &confessionRepository{db: db}
+}
+
+// GetChapters returns the chapters index, without sections
+func (r *confessionRepository) GetChapters(ctx context.Context) ([]*models.ConfessionChapter, error) {
+	query := `SELECT id, chapter_number, title FROM confession_chapters ORDER BY chapter_number`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chapters := []*models.ConfessionChapter{}
+	for rows.Next() {
+		chapter := &models.ConfessionChapter{}
+		if err := rows.Scan(&chapter.ID, &chapter.ChapterNumber, &chapter.Title); err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, chapter)
+	}
+
+	return chapters, rows.Err()
+}
+
+// GetChapter returns a chapter with its sections and proofs, or nil if it doesn't exist
+func (r *confessionRepository) GetChapter(ctx context.Context, chapterNumber int) (*models.ConfessionChapter, error) {
+	chapter := &models.ConfessionChapter{}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, chapter_number, title FROM confession_chapters WHERE chapter_number = $1`,
+		chapterNumber,
+	).Scan(&chapter.ID, &chapter.ChapterNumber, &chapter.Title)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, section_number, text FROM confession_sections
+		 WHERE chapter_id = $1 ORDER BY section_number`,
+		chapter.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chapter.Sections = []*models.ConfessionSection{}
+	sectionsByID := make(map[int]*models.ConfessionSection)
+	for rows.Next() {
+		section := &models.ConfessionSection{Proofs: []*models.ConfessionProof{}}
+		if err := rows.Scan(&section.ID, &section.SectionNumber, &section.Text); err != nil {
+			return nil, err
+		}
+		chapter.Sections = append(chapter.Sections, section)
+		sectionsByID[section.ID] = section
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	proofRows, err := r.db.QueryContext(ctx,
+		`SELECT p.section_id, p.letter, p.reference_text
+		 FROM confession_proofs p
+		 JOIN confession_sections s ON s.id = p.section_id
+		 WHERE s.chapter_id = $1
+		 ORDER BY p.section_id, p.position`,
+		chapter.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer proofRows.Close()
+
+	for proofRows.Next() {
+		var sectionID int
+		proof := &models.ConfessionProof{}
+		if err := proofRows.Scan(&sectionID, &proof.Letter, &proof.References); err != nil {
+			return nil, err
+		}
+		if section, ok := sectionsByID[sectionID]; ok {
+			section.Proofs = append(section.Proofs, proof)
+		}
+	}
+
+	return chapter, proofRows.Err()
+}
+
+// GetLinkedQuestions returns, for each section of a chapter, the numbers of
+// the related questions of a catechism
+func (r *confessionRepository) GetLinkedQuestions(ctx context.Context, chapterNumber int, catechism string) (map[int][]int, error) {
+	query := `SELECT section_number, question_number FROM catechism_confession_links
+	          WHERE chapter_number = $1 AND catechism = $2
+	          ORDER BY section_number, question_number`
+
+	rows, err := r.db.QueryContext(ctx, query, chapterNumber, catechism)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	questions := make(map[int][]int)
+	for rows.Next() {
+		var sectionNumber, questionNumber int
+		if err := rows.Scan(&sectionNumber, &questionNumber); err != nil {
+			return nil, err
+		}
+		questions[sectionNumber] = append(questions[sectionNumber], questionNumber)
+	}
+
+	return questions, rows.Err()
+}
+
+// GetReferencesForQuestion returns the Confession sections related to a catechism question.
+// Links to chapters that were not imported are skipped.
+func (r *confessionRepository) GetReferencesForQuestion(ctx context.Context, catechism string, questionNumber int) ([]*models.ConfessionReference, error) {
+	query := `SELECT l.chapter_number, c.title, l.section_number
+	          FROM catechism_confession_links l
+	          JOIN confession_chapters c ON c.chapter_number = l.chapter_number
+	          WHERE l.catechism = $1 AND l.question_number = $2
+	          ORDER BY l.chapter_number, l.section_number`
+
+	rows, err := r.db.QueryContext(ctx, query, catechism, questionNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	references := []*models.ConfessionReference{}
+	for rows.Next() {
+		reference := &models.ConfessionReference{}
+		if err := rows.Scan(&reference.ChapterNumber, &reference.ChapterTitle, &reference.SectionNumber); err != nil {
+			return nil, err
+		}
+		references = append(references, reference)
+	}
+
+	return references, rows.Err()
+}
+
+// ReplaceAll replaces the whole Confession (chapters, sections and proofs) in a single transaction
+func (r *confessionRepository) ReplaceAll(ctx context.Context, chapters []*models.ConfessionChapter) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Sections and proofs are removed by ON DELETE CASCADE
+	if _, err := tx.ExecContext(ctx, `DELETE FROM confession_chapters`); err != nil {
+		return err
+	}
+
+	for _, chapter := range chapters {
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO confession_chapters (chapter_number, title) VALUES ($1, $2) RETURNING id`,
+			chapter.ChapterNumber, chapter.Title,
+		).Scan(&chapter.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, section := range chapter.Sections {
+			err := tx.QueryRowContext(ctx,
+				`INSERT INTO confession_sections (chapter_id, section_number, text) VALUES ($1, $2, $3) RETURNING id`,
+				chapter.ID, section.SectionNumber, section.Text,
+			).Scan(&section.ID)
+			if err != nil {
+				return err
+			}
+
+			for i, proof := range section.Proofs {
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO confession_proofs (section_id, position, letter, reference_text) VALUES ($1, $2, $3, $4)`,
+					section.ID, i+1, proof.Letter, proof.References,
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceLinks replaces every Confession link of a catechism in a single transaction
+func (r *confessionRepository) ReplaceLinks(ctx context.Context, catechism string, links []*models.CatechismConfessionLink) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM catechism_confession_links WHERE catechism = $1`, catechism); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO catechism_confession_links (catechism, question_number, chapter_number, section_number)
+	          VALUES ($1, $2, $3, $4)
+	          ON CONFLICT DO NOTHING`
+	for _, link := range links {
+		if _, err := tx.ExecContext(ctx, query, catechism, link.QuestionNumber, link.ChapterNumber, link.SectionNumber); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span attributes of the queries
+const (
+	attrDBSystem       = attribute.Key("db.system")
+	attrDBStatement    = attribute.Key("db.statement")
+	attrDBOperation    = attribute.Key("db.operation")
+	attrRowsReturned   = attribute.Key("db.rows_returned")
+	attrRowsAffected   = attribute.Key("db.rows_affected")
+	tracerInstrumentor = "biblia-am-pm/internal/repository"
+)
+
+var tracer = otel.Tracer(tracerInstrumentor)
+
+// DB is a *sql.DB whose queries are traced: each one is a span, child of the
+// span in the context, with the SQL text and the rows it returned or changed.
+// Transactions begun from it are traced the same way.
+type DB struct {
+	db     *sql.DB
+	system string
+}
+
+// NewDB wraps db. system names the database in the spans ("postgresql" or
+// "sqlite").
+func NewDB(db *sql.DB, system string) *DB {
+	return &DB{db: db, system: system}
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, d.db, d.system, query, args...)
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return queryContext(ctx, d.db, d.system, query, args...)
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return queryRowContext(ctx, d.db, d.system, query, args...)
+}
+
+// BeginTx starts a transaction. Its statements are traced under ctx.
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, system: d.system}, nil
+}
+
+// Tx is a traced *sql.Tx
+type Tx struct {
+	tx     *sql.Tx
+	system string
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return execContext(ctx, t.tx, t.system, query, args...)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	return queryContext(ctx, t.tx, t.system, query, args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	return queryRowContext(ctx, t.tx, t.system, query, args...)
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// Rows counts the rows read and ends the span of its query when closed
+type Rows struct {
+	*sql.Rows
+	span  trace.Span
+	count int
+	once  sync.Once
+}
+
+func (r *Rows) Next() bool {
+	if r.Rows.Next() {
+		r.count++
+		return true
+	}
+	return false
+}
+
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.once.Do(func() {
+		if rowsErr := r.Rows.Err(); rowsErr != nil {
+			recordError(r.span, rowsErr)
+		}
+		r.span.SetAttributes(attrRowsReturned.Int(r.count))
+		r.span.End()
+	})
+	return err
+}
+
+// Row ends the span of its query when scanned, or when its error is checked
+// without scanning
+type Row struct {
+	row  *sql.Row
+	span trace.Span
+	once sync.Once
+}
+
+func (r *Row) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	r.once.Do(func() {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			r.span.SetAttributes(attrRowsReturned.Int(0))
+		case err != nil:
+			recordError(r.span, err)
+		default:
+			r.span.SetAttributes(attrRowsReturned.Int(1))
+		}
+		r.span.End()
+	})
+	return err
+}
+
+func (r *Row) Err() error {
+	err := r.row.Err()
+	r.once.Do(func() {
+		if err != nil {
+			recordError(r.span, err)
+		}
+		r.span.End()
+	})
+	return err
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func execContext(ctx context.Context, q querier, system, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startSpan(ctx, system, query)
+	defer span.End()
+
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err == nil {
+		span.SetAttributes(attrRowsAffected.Int64(affected))
+	}
+	return result, nil
+}
+
+func queryContext(ctx context.Context, q querier, system, query string, args ...interface{}) (*Rows, error) {
+	ctx, span := startSpan(ctx, system, query)
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return &Rows{Rows: rows, span: span}, nil
+}
+
+func queryRowContext(ctx context.Context, q querier, system, query string, args ...interface{}) *Row {
+	ctx, span := startSpan(ctx, system, query)
+	return &Row{row: q.QueryRowContext(ctx, query, args...), span: span}
+}
+
+func startSpan(ctx context.Context, system, query string) (context.Context, trace.Span) {
+	query = normalizeQuery(query)
+	operation := query
+	if i := strings.IndexByte(query, ' '); i > 0 {
+		operation = query[:i]
+	}
+	operation = strings.ToUpper(operation)
+
+	return tracer.Start(ctx, "db."+strings.ToLower(operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attrDBSystem.String(system),
+			attrDBOperation.String(operation),
+			attrDBStatement.String(query),
+		),
+	)
+}
+
+// normalizeQuery collapses the indentation of the queries written across lines
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+func recordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type householdRepository struct {
+	db *DB
+}
+
+func NewHouseholdRepository(db *DB) HouseholdRepository {
+	return &householdRepository{db: db}
+}
+
+func (r *householdRepository) Create(ctx context.Context, userID int, inviteCode string) (*models.Household, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	var householdID int
+	err = tx.QueryRowContext(ctx, `INSERT INTO households (invite_code, created_at) VALUES ($1, $2) RETURNING id`,
+		inviteCode, now).Scan(&householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	joined, err := joinHouseholdTx(ctx, tx, userID, householdID, now)
+	if err != nil || !joined {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByUser(ctx, userID)
+}
+
+func (r *householdRepository) Join(ctx context.Context, userID int, inviteCode string) (*models.Household, error) {
+	var householdID int
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM households WHERE invite_code = $1`, inviteCode).Scan(&householdID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	joined, err := joinHouseholdTx(ctx, r.db, userID, householdID, time.Now().UTC())
+	if err != nil || !joined {
+		return nil, err
+	}
+	return r.GetByUser(ctx, userID)
+}
+
+// joinHouseholdTx adds a member to a household, unless the user already
+// belongs to one
+func joinHouseholdTx(ctx context.Context, tx DBTX, userID, householdID int, now time.Time) (bool, error) {
+	result, err := tx.ExecContext(ctx, `INSERT INTO household_members (household_id, user_id, joined_at)
+	                                    VALUES ($1, $2, $3)
+	                                    ON CONFLICT (user_id) DO NOTHING`, householdID, userID, now)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	return inserted > 0, err
+}
+
+func (r *householdRepository) Leave(ctx context.Context, userID int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var householdID int
+	err = tx.QueryRowContext(ctx, `DELETE FROM household_members WHERE user_id = $1 RETURNING household_id`, userID).Scan(&householdID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM households
+	                              WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM household_members WHERE household_id = $1)`,
+		householdID)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *householdRepository) GetByUser(ctx context.Context, userID int) (*models.Household, error) {
+	query := `SELECT h.id, h.invite_code, h.created_at
+	          FROM households h JOIN household_members m ON m.household_id = h.id
+	          WHERE m.user_id = $1`
+
+	household := &models.Household{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&household.ID, &household.InviteCode, &household.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT m.user_id, u.email, m.joined_at
+	                                     FROM household_members m JOIN users u ON u.id = m.user_id
+	                                     WHERE m.household_id = $1
+	                                     ORDER BY m.joined_at, m.user_id`, household.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	household.Members = []*models.HouseholdMember{}
+	for rows.Next() {
+		member := &models.HouseholdMember{}
+		if err := rows.Scan(&member.UserID, &member.Email, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		household.Members = append(household.Members, member)
+	}
+	return household, rows.Err()
+}
+
+func (r *householdRepository) GetMemberIDs(ctx context.Context, userID int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT m.user_id
+	                                     FROM household_members m
+	                                     JOIN household_members own ON own.household_id = m.household_id
+	                                     WHERE own.user_id = $1
+	                                     ORDER BY m.user_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		ids = []int{userID}
+	}
+	return ids, nil
+}
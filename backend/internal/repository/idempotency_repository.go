@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type idempotencyRepository struct {
+	db *DB
+}
+
+func NewIdempotencyRepository(db *DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve inserts the record without a response. An expired record with the
+// same key is replaced, which also frees keys held by requests that never
+// answered.
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	query := `INSERT INTO idempotency_keys (user_id, idempotency_key, fingerprint, status, content_type, body, created_at, expires_at)
+	          VALUES ($1, $2, $3, NULL, '', NULL, $4, $5)
+	          ON CONFLICT (user_id, idempotency_key) DO UPDATE SET
+	            fingerprint = EXCLUDED.fingerprint,
+	            status = NULL,
+	            content_type = '',
+	            body = NULL,
+	            created_at = EXCLUDED.created_at,
+	            expires_at = EXCLUDED.expires_at
+	          WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	          RETURNING user_id`
+
+	var userID int
+	err := r.db.QueryRowContext(ctx, query,
+		record.UserID,
+		record.Key,
+		record.Fingerprint,
+		record.CreatedAt.UTC(),
+		record.ExpiresAt.UTC(),
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+	query := `SELECT user_id, idempotency_key, fingerprint, status, content_type, body, created_at, expires_at
+	          FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2`
+
+	record := &models.IdempotencyRecord{}
+	var status sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, userID, key).Scan(
+		&record.UserID,
+		&record.Key,
+		&record.Fingerprint,
+		&status,
+		&record.ContentType,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	record.Status = int(status.Int64)
+	return record, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE idempotency_keys
+	                  SET status = $1, content_type = $2, body = $3, expires_at = $4
+	                  WHERE user_id = $5 AND idempotency_key = $6`,
+		record.Status,
+		record.ContentType,
+		record.Body,
+		record.ExpiresAt.UTC(),
+		record.UserID,
+		record.Key,
+	)
+	return err
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, userID int, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2 AND status IS NULL`, userID, key)
+	return err
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
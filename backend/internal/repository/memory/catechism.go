@@ -0,0 +1,258 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+type catechismRepository struct {
+	s *store
+}
+
+// questionKey identifies a question, like the UNIQUE(catechism, question_number) of the table
+type questionKey struct {
+	catechism string
+	number    int
+}
+
+// sortedQuestions returns copies of the questions matching keep, by number. Callers hold the lock.
+func (r *catechismRepository) sortedQuestions(keep func(*models.CatechismQuestion) bool) []*models.CatechismQuestion {
+	questions := []*models.CatechismQuestion{}
+	for _, question := range r.s.questions {
+		if keep(question) {
+			found := *question
+			questions = append(questions, &found)
+		}
+	}
+	sort.Slice(questions, func(i, j int) bool { return questions[i].QuestionNumber < questions[j].QuestionNumber })
+	return questions
+}
+
+func (r *catechismRepository) GetByQuestionNumber(ctx context.Context, catechism string, questionNumber int) (*models.CatechismQuestion, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	question, ok := r.s.questions[questionKey{catechism, questionNumber}]
+	if !ok {
+		return nil, nil
+	}
+	found := *question
+	return &found, nil
+}
+
+func (r *catechismRepository) GetAll(ctx context.Context, catechism string) ([]*models.CatechismQuestion, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.sortedQuestions(func(q *models.CatechismQuestion) bool { return q.Catechism == catechism }), nil
+}
+
+func (r *catechismRepository) GetByQuestionRange(ctx context.Context, catechism string, start, end int) ([]*models.CatechismQuestion, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.sortedQuestions(func(q *models.CatechismQuestion) bool {
+		return q.Catechism == catechism && q.QuestionNumber >= start && q.QuestionNumber <= end
+	}), nil
+}
+
+func (r *catechismRepository) GetPage(ctx context.Context, catechism string, start, end, limit, offset int) ([]*models.CatechismQuestion, int, error) {
+	questions, _ := r.GetByQuestionRange(ctx, catechism, start, end)
+	total := len(questions)
+
+	if offset >= total {
+		return []*models.CatechismQuestion{}, total, nil
+	}
+	if offset+limit < total {
+		questions = questions[:offset+limit]
+	}
+	return questions[offset:], total, nil
+}
+
+func (r *catechismRepository) GetAdjacentNumbers(ctx context.Context, catechism string, questionNumber int) (int, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	previous, next := 0, 0
+	for key := range r.s.questions {
+		if key.catechism != catechism {
+			continue
+		}
+		number := key.number
+		if number < questionNumber && number > previous {
+			previous = number
+		}
+		if number > questionNumber && (next == 0 || number < next) {
+			next = number
+		}
+	}
+	return previous, next, nil
+}
+
+func (r *catechismRepository) Create(ctx context.Context, question *models.CatechismQuestion) error {
+	return r.Save(ctx, question, nil)
+}
+
+func (r *catechismRepository) Save(ctx context.Context, question *models.CatechismQuestion, authorID *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	r.save(question, authorID)
+	return nil
+}
+
+// save follows the rules of the PostgreSQL implementation. Callers hold the lock.
+func (r *catechismRepository) save(question *models.CatechismQuestion, authorID *int) {
+	if question.Catechism == "" {
+		question.Catechism = models.CatechismShorter
+	}
+
+	key := questionKey{question.Catechism, question.QuestionNumber}
+	existing, ok := r.s.questions[key]
+	if retired, wasRetired := r.s.retiredQuestions[key]; wasRetired {
+		delete(r.s.retiredQuestions, key)
+		r.s.questions[key] = retired
+		existing, ok = retired, true
+	}
+	switch {
+	case !ok:
+		question.ID = r.s.nextID()
+		question.Revision = 1
+	case existing.QuestionText == question.QuestionText && existing.AnswerText == question.AnswerText:
+		question.ID = existing.ID
+		question.Revision = existing.Revision
+		return
+	default:
+		question.ID = existing.ID
+		question.Revision = existing.Revision + 1
+	}
+
+	stored := *question
+	r.s.questions[key] = &stored
+	r.s.revisions = append(r.s.revisions, &models.CatechismRevision{
+		ID:             r.s.nextID(),
+		QuestionID:     question.ID,
+		Revision:       question.Revision,
+		QuestionNumber: question.QuestionNumber,
+		QuestionText:   question.QuestionText,
+		AnswerText:     question.AnswerText,
+		AuthorID:       authorID,
+		CreatedAt:      time.Now(),
+	})
+}
+
+func (r *catechismRepository) ApplyImport(ctx context.Context, catechism string, upserts []*models.CatechismQuestion, removeNumbers []int, authorID *int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, question := range upserts {
+		question.Catechism = catechism
+		r.save(question, authorID)
+	}
+
+	// Retired questions keep their revisions, progress and notes
+	for _, number := range removeNumbers {
+		key := questionKey{catechism, number}
+		if question, ok := r.s.questions[key]; ok {
+			delete(r.s.questions, key)
+			r.s.retiredQuestions[key] = question
+		}
+	}
+	return nil
+}
+
+func (r *catechismRepository) Search(ctx context.Context, text string, catechism string, limit int) ([]*models.CatechismSearchResult, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	text = strings.ToLower(strings.TrimSpace(text))
+	questions := r.sortedQuestions(func(q *models.CatechismQuestion) bool {
+		return (catechism == "" || q.Catechism == catechism) &&
+			(strings.Contains(strings.ToLower(q.QuestionText), text) || strings.Contains(strings.ToLower(q.AnswerText), text))
+	})
+
+	results := []*models.CatechismSearchResult{}
+	for _, question := range questions {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, &models.CatechismSearchResult{
+			Question:        question,
+			Rank:            1,
+			QuestionSnippet: question.QuestionText,
+			AnswerSnippet:   question.AnswerText,
+		})
+	}
+	return results, nil
+}
+
+// withAuthor returns a copy of the revision with the author's email. Callers hold the lock.
+func (r *catechismRepository) withAuthor(revision *models.CatechismRevision) *models.CatechismRevision {
+	found := *revision
+	if found.AuthorID != nil {
+		for _, user := range r.s.users {
+			if user.ID == *found.AuthorID {
+				found.AuthorEmail = user.Email
+			}
+		}
+	}
+	return &found
+}
+
+func (r *catechismRepository) GetRevisions(ctx context.Context, questionID int) ([]*models.CatechismRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var revisions []*models.CatechismRevision
+	for _, revision := range r.s.revisions {
+		if revision.QuestionID == questionID {
+			revisions = append(revisions, r.withAuthor(revision))
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool { return revisions[i].Revision > revisions[j].Revision })
+	return revisions, nil
+}
+
+func (r *catechismRepository) GetRevision(ctx context.Context, questionID int, revisionNumber int) (*models.CatechismRevision, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, revision := range r.s.revisions {
+		if revision.QuestionID == questionID && revision.Revision == revisionNumber {
+			return r.withAuthor(revision), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *catechismRepository) CreateBatch(ctx context.Context, questions []*models.CatechismQuestion) error {
+	for _, question := range questions {
+		if err := r.Create(ctx, question); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *catechismRepository) GetTotalCount(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.questions), nil
+}
+
+func (r *catechismRepository) GetMaxQuestionNumber(ctx context.Context, catechism string) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	maxNumber := 0
+	for key := range r.s.questions {
+		if key.catechism == catechism && key.number > maxNumber {
+			maxNumber = key.number
+		}
+	}
+	return maxNumber, nil
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type catechismProgressRepository struct {
+	s *store
+}
+
+func (r *catechismProgressRepository) GetByUserAndDate(ctx context.Context, userID int, questionID int, date time.Time, step string) (*models.CatechismProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.getCatechismProgress(userID, questionID, date, step), nil
+}
+
+// getCatechismProgress returns a copy of the progress of a step, or nil.
+// Callers hold the lock.
+func (s *store) getCatechismProgress(userID int, questionID int, date time.Time, step string) *models.CatechismProgress {
+	for _, progress := range s.catechismProgress {
+		if progress.UserID == userID && progress.QuestionID == questionID && sameDate(progress.Date, date) && progress.Step == step {
+			found := *progress
+			return &found
+		}
+	}
+	return nil
+}
+
+func (r *catechismProgressRepository) GetByUserAndQuestionForWeek(ctx context.Context, userID int, questionID int, weekStart time.Time) ([]*models.CatechismProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	start := truncateDate(weekStart)
+	end := start.AddDate(0, 0, 6)
+
+	var progresses []*models.CatechismProgress
+	for _, progress := range r.s.catechismProgress {
+		if progress.UserID == userID && progress.QuestionID == questionID &&
+			!progress.Date.Before(start) && !progress.Date.After(end) {
+			found := *progress
+			progresses = append(progresses, &found)
+		}
+	}
+	sort.Slice(progresses, func(i, j int) bool {
+		if !progresses[i].Date.Equal(progresses[j].Date) {
+			return progresses[i].Date.Before(progresses[j].Date)
+		}
+		return progresses[i].Step < progresses[j].Step
+	})
+	return progresses, nil
+}
+
+// saveCatechismProgress upserts the progress with the given change sequence
+// number. Callers hold the lock.
+func (s *store) saveCatechismProgress(progress *models.CatechismProgress, seq int64) {
+	progress.ChangeSeq = seq
+	stored := *progress
+	stored.Date = truncateDate(progress.Date)
+
+	for i, existing := range s.catechismProgress {
+		if existing.UserID == progress.UserID && existing.QuestionID == progress.QuestionID &&
+			sameDate(existing.Date, progress.Date) && existing.Step == progress.Step {
+			stored.ID = existing.ID
+			progress.ID = existing.ID
+			s.catechismProgress[i] = &stored
+			return
+		}
+	}
+
+	stored.ID = s.nextID()
+	progress.ID = stored.ID
+	s.catechismProgress = append(s.catechismProgress, &stored)
+}
+
+func (r *catechismProgressRepository) GetUserProgress(ctx context.Context, userID int) ([]*models.CatechismProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var progresses []*models.CatechismProgress
+	for _, progress := range r.s.catechismProgress {
+		if progress.UserID == userID {
+			found := *progress
+			progresses = append(progresses, &found)
+		}
+	}
+	sort.SliceStable(progresses, func(i, j int) bool { return progresses[i].Date.After(progresses[j].Date) })
+	return progresses, nil
+}
+
+func (r *catechismProgressRepository) GetQuestionStatuses(ctx context.Context, userID int) (map[int]*models.CatechismQuestionStatus, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	statuses := map[int]*models.CatechismQuestionStatus{}
+	days := map[int]map[string]bool{}
+	for _, progress := range r.s.catechismProgress {
+		if progress.UserID != userID || !progress.Completed {
+			continue
+		}
+
+		status, ok := statuses[progress.QuestionID]
+		if !ok {
+			status = &models.CatechismQuestionStatus{}
+			statuses[progress.QuestionID] = status
+			days[progress.QuestionID] = map[string]bool{}
+		}
+
+		days[progress.QuestionID][progress.Date.Format("2006-01-02")] = true
+		status.TimesCompleted = len(days[progress.QuestionID])
+		if progress.CompletedAt != nil && (status.LastCompletedAt == nil || progress.CompletedAt.After(*status.LastCompletedAt)) {
+			completedAt := *progress.CompletedAt
+			status.LastCompletedAt = &completedAt
+		}
+	}
+	return statuses, nil
+}
+
+func (r *catechismProgressRepository) CountCompletedOnDate(ctx context.Context, date time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	count := 0
+	for _, progress := range r.s.catechismProgress {
+		if progress.Completed && sameDate(progress.Date, date) {
+			count++
+		}
+	}
+	return count, nil
+}
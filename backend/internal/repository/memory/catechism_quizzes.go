@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type catechismQuizRepository struct {
+	s *store
+}
+
+func (r *catechismQuizRepository) Create(ctx context.Context, quiz *models.CatechismQuiz) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	quiz.ID = r.s.nextID()
+	quiz.CreatedAt = time.Now()
+	stored := *quiz
+	r.s.quizzes = append(r.s.quizzes, &stored)
+	return nil
+}
+
+func (r *catechismQuizRepository) GetByIDAndUser(ctx context.Context, id int, userID int) (*models.CatechismQuiz, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, quiz := range r.s.quizzes {
+		if quiz.ID == id && quiz.UserID == userID {
+			found := *quiz
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *catechismQuizRepository) SaveResult(ctx context.Context, quiz *models.CatechismQuiz) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, stored := range r.s.quizzes {
+		if stored.ID != quiz.ID || stored.UserID != quiz.UserID {
+			continue
+		}
+		if stored.SubmittedAt != nil {
+			return false, nil
+		}
+
+		now := time.Now()
+		stored.Answers = quiz.Answers
+		stored.Results = quiz.Results
+		stored.Score = quiz.Score
+		stored.CorrectCount = quiz.CorrectCount
+		stored.SubmittedAt = &now
+		quiz.SubmittedAt = &now
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *catechismQuizRepository) GetSubmittedByUser(ctx context.Context, userID int) ([]*models.CatechismQuiz, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var quizzes []*models.CatechismQuiz
+	for _, quiz := range r.s.quizzes {
+		if quiz.UserID == userID && quiz.SubmittedAt != nil {
+			found := *quiz
+			quizzes = append(quizzes, &found)
+		}
+	}
+	sort.SliceStable(quizzes, func(i, j int) bool { return quizzes[i].SubmittedAt.After(*quizzes[j].SubmittedAt) })
+	return quizzes, nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+)
+
+type catechismSectionRepository struct {
+	s *store
+}
+
+func (r *catechismSectionRepository) GetByCatechism(ctx context.Context, catechism string) ([]*models.CatechismSection, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	sections := []*models.CatechismSection{}
+	for _, section := range r.s.sections[catechism] {
+		found := *section
+		sections = append(sections, &found)
+	}
+	return sections, nil
+}
+
+func (r *catechismSectionRepository) ReplaceForCatechism(ctx context.Context, catechism string, sections []*models.CatechismSection) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := make([]*models.CatechismSection, 0, len(sections))
+	for i, section := range sections {
+		section.ID = r.s.nextID()
+		section.Catechism = catechism
+		section.Position = i + 1
+		copied := *section
+		stored = append(stored, &copied)
+	}
+	r.s.sections[catechism] = stored
+	return nil
+}
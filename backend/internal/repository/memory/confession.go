@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+)
+
+type confessionRepository struct {
+	s *store
+}
+
+func (r *confessionRepository) GetChapters(ctx context.Context) ([]*models.ConfessionChapter, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	chapters := []*models.ConfessionChapter{}
+	for _, chapter := range r.s.chapters {
+		chapters = append(chapters, &models.ConfessionChapter{
+			ID:            chapter.ID,
+			ChapterNumber: chapter.ChapterNumber,
+			Title:         chapter.Title,
+		})
+	}
+	return chapters, nil
+}
+
+func (r *confessionRepository) GetChapter(ctx context.Context, chapterNumber int) (*models.ConfessionChapter, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, chapter := range r.s.chapters {
+		if chapter.ChapterNumber != chapterNumber {
+			continue
+		}
+
+		found := *chapter
+		found.Sections = []*models.ConfessionSection{}
+		for _, section := range chapter.Sections {
+			copied := *section
+			copied.Proofs = append([]*models.ConfessionProof{}, section.Proofs...)
+			found.Sections = append(found.Sections, &copied)
+		}
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *confessionRepository) GetLinkedQuestions(ctx context.Context, chapterNumber int, catechism string) (map[int][]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	questions := make(map[int][]int)
+	for _, link := range r.s.links {
+		if link.ChapterNumber == chapterNumber && link.Catechism == catechism {
+			questions[link.SectionNumber] = append(questions[link.SectionNumber], link.QuestionNumber)
+		}
+	}
+	for _, numbers := range questions {
+		sort.Ints(numbers)
+	}
+	return questions, nil
+}
+
+func (r *confessionRepository) GetReferencesForQuestion(ctx context.Context, catechism string, questionNumber int) ([]*models.ConfessionReference, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	references := []*models.ConfessionReference{}
+	for _, link := range r.s.links {
+		if link.Catechism != catechism || link.QuestionNumber != questionNumber {
+			continue
+		}
+		for _, chapter := range r.s.chapters {
+			if chapter.ChapterNumber == link.ChapterNumber {
+				references = append(references, &models.ConfessionReference{
+					ChapterNumber: chapter.ChapterNumber,
+					ChapterTitle:  chapter.Title,
+					SectionNumber: link.SectionNumber,
+				})
+			}
+		}
+	}
+	sort.Slice(references, func(i, j int) bool {
+		if references[i].ChapterNumber != references[j].ChapterNumber {
+			return references[i].ChapterNumber < references[j].ChapterNumber
+		}
+		return references[i].SectionNumber < references[j].SectionNumber
+	})
+	return references, nil
+}
+
+func (r *confessionRepository) ReplaceAll(ctx context.Context, chapters []*models.ConfessionChapter) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := make([]*models.ConfessionChapter, 0, len(chapters))
+	for _, chapter := range chapters {
+		chapter.ID = r.s.nextID()
+		copied := *chapter
+		copied.Sections = nil
+		for _, section := range chapter.Sections {
+			section.ID = r.s.nextID()
+			copiedSection := *section
+			copiedSection.Proofs = append([]*models.ConfessionProof{}, section.Proofs...)
+			copied.Sections = append(copied.Sections, &copiedSection)
+		}
+		sort.Slice(copied.Sections, func(i, j int) bool {
+			return copied.Sections[i].SectionNumber < copied.Sections[j].SectionNumber
+		})
+		stored = append(stored, &copied)
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].ChapterNumber < stored[j].ChapterNumber })
+	r.s.chapters = stored
+	return nil
+}
+
+func (r *confessionRepository) ReplaceLinks(ctx context.Context, catechism string, links []*models.CatechismConfessionLink) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	kept := []*models.CatechismConfessionLink{}
+	for _, link := range r.s.links {
+		if link.Catechism != catechism {
+			kept = append(kept, link)
+		}
+	}
+	// Duplicated links are ignored, like ON CONFLICT DO NOTHING
+	seen := make(map[models.CatechismConfessionLink]bool)
+	for _, link := range links {
+		copied := *link
+		copied.Catechism = catechism
+		if seen[copied] {
+			continue
+		}
+		seen[copied] = true
+		kept = append(kept, &copied)
+	}
+	r.s.links = kept
+	return nil
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+// householdMember is a row of household_members
+type householdMember struct {
+	householdID int
+	userID      int
+	joinedAt    time.Time
+}
+
+type householdRepository struct {
+	s *store
+}
+
+func (r *householdRepository) Create(ctx context.Context, userID int, inviteCode string) (*models.Household, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.membership(userID) != nil {
+		return nil, nil
+	}
+	household := &models.Household{ID: r.s.nextID(), InviteCode: inviteCode, CreatedAt: time.Now().UTC()}
+	r.s.households = append(r.s.households, household)
+	r.s.householdMembers = append(r.s.householdMembers, &householdMember{household.ID, userID, household.CreatedAt})
+	return r.loaded(household.ID), nil
+}
+
+func (r *householdRepository) Join(ctx context.Context, userID int, inviteCode string) (*models.Household, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, household := range r.s.households {
+		if household.InviteCode != inviteCode {
+			continue
+		}
+		if r.membership(userID) != nil {
+			return nil, nil
+		}
+		r.s.householdMembers = append(r.s.householdMembers, &householdMember{household.ID, userID, time.Now().UTC()})
+		return r.loaded(household.ID), nil
+	}
+	return nil, nil
+}
+
+func (r *householdRepository) Leave(ctx context.Context, userID int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	left := r.membership(userID)
+	if left == nil {
+		return false, nil
+	}
+
+	var members []*householdMember
+	remaining := 0
+	for _, member := range r.s.householdMembers {
+		if member == left {
+			continue
+		}
+		if member.householdID == left.householdID {
+			remaining++
+		}
+		members = append(members, member)
+	}
+	r.s.householdMembers = members
+
+	if remaining == 0 {
+		for i, household := range r.s.households {
+			if household.ID == left.householdID {
+				r.s.households = append(r.s.households[:i], r.s.households[i+1:]...)
+				break
+			}
+		}
+	}
+	return true, nil
+}
+
+func (r *householdRepository) GetByUser(ctx context.Context, userID int) (*models.Household, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	member := r.membership(userID)
+	if member == nil {
+		return nil, nil
+	}
+	return r.loaded(member.householdID), nil
+}
+
+func (r *householdRepository) GetMemberIDs(ctx context.Context, userID int) ([]int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	own := r.membership(userID)
+	if own == nil {
+		return []int{userID}, nil
+	}
+	var ids []int
+	for _, member := range r.s.householdMembers {
+		if member.householdID == own.householdID {
+			ids = append(ids, member.userID)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// membership returns the row of the household userID belongs to, or nil.
+// Callers hold the lock.
+func (r *householdRepository) membership(userID int) *householdMember {
+	for _, member := range r.s.householdMembers {
+		if member.userID == userID {
+			return member
+		}
+	}
+	return nil
+}
+
+// loaded returns a copy of a household with its members in the order they
+// joined. Callers hold the lock.
+func (r *householdRepository) loaded(householdID int) *models.Household {
+	var found models.Household
+	for _, household := range r.s.households {
+		if household.ID == householdID {
+			found = *household
+		}
+	}
+
+	found.Members = []*models.HouseholdMember{}
+	for _, member := range r.s.householdMembers {
+		if member.householdID != householdID {
+			continue
+		}
+		email := ""
+		for _, user := range r.s.users {
+			if user.ID == member.userID {
+				email = user.Email
+			}
+		}
+		found.Members = append(found.Members, &models.HouseholdMember{UserID: member.userID, Email: email, JoinedAt: member.joinedAt})
+	}
+	sort.SliceStable(found.Members, func(i, j int) bool {
+		return found.Members[i].JoinedAt.Before(found.Members[j].JoinedAt)
+	})
+	return &found
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"time"
+)
+
+type idempotencyRepository struct {
+	s *store
+}
+
+func (r *idempotencyRepository) find(userID int, key string) int {
+	for i, record := range r.s.idempotencyRecords {
+		if record.UserID == userID && record.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *models.IdempotencyRecord) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored := *record
+	stored.Status, stored.ContentType, stored.Body = 0, "", nil
+
+	i := r.find(record.UserID, record.Key)
+	if i < 0 {
+		r.s.idempotencyRecords = append(r.s.idempotencyRecords, &stored)
+		return true, nil
+	}
+	if r.s.idempotencyRecords[i].ExpiresAt.After(record.CreatedAt) {
+		return false, nil
+	}
+	r.s.idempotencyRecords[i] = &stored
+	return true, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if i := r.find(userID, key); i >= 0 {
+		found := *r.s.idempotencyRecords[i]
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *models.IdempotencyRecord) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if i := r.find(record.UserID, record.Key); i >= 0 {
+		stored := r.s.idempotencyRecords[i]
+		stored.Status = record.Status
+		stored.ContentType = record.ContentType
+		stored.Body = append([]byte(nil), record.Body...)
+		stored.ExpiresAt = record.ExpiresAt
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, userID int, key string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if i := r.find(userID, key); i >= 0 && r.s.idempotencyRecords[i].Status == 0 {
+		r.s.idempotencyRecords = append(r.s.idempotencyRecords[:i], r.s.idempotencyRecords[i+1:]...)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	kept := r.s.idempotencyRecords[:0]
+	for _, record := range r.s.idempotencyRecords {
+		if record.ExpiresAt.After(now) {
+			kept = append(kept, record)
+		}
+	}
+	deleted := int64(len(r.s.idempotencyRecords) - len(kept))
+	r.s.idempotencyRecords = kept
+	return deleted, nil
+}
@@ -0,0 +1,86 @@
+// Package memory implements the repositories in memory. It is meant for tests:
+// nothing is persisted and text search is a plain substring match.
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"sync"
+	"time"
+)
+
+// store holds the data shared by the repositories, so that relations across
+// them (revision authors, cascading deletes) behave like in the database
+type store struct {
+	mu sync.Mutex
+
+	users              []*models.User
+	readingPlans       map[int]*models.ReadingPlan
+	userProgress       []*models.UserProgress
+	questions          map[questionKey]*models.CatechismQuestion
+	retiredQuestions   map[questionKey]*models.CatechismQuestion
+	revisions          []*models.CatechismRevision
+	catechismProgress  []*models.CatechismProgress
+	quizzes            []*models.CatechismQuiz
+	sections           map[string][]*models.CatechismSection
+	chapters           []*models.ConfessionChapter
+	links              []*models.CatechismConfessionLink
+	progressEvents     []*models.ProgressEvent
+	notes              []*models.Note
+	syncSeqs           map[int]int64
+	idempotencyRecords []*models.IdempotencyRecord
+	households         []*models.Household
+	householdMembers   []*householdMember
+
+	lastID int
+}
+
+// nextID mimics a SERIAL column shared by every table. Callers hold the lock.
+func (s *store) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+// nextChangeSeq takes the next change sequence number of a user. Callers hold
+// the lock.
+func (s *store) nextChangeSeq(userID int) int64 {
+	s.syncSeqs[userID]++
+	return s.syncSeqs[userID]
+}
+
+// New returns empty in-memory repositories sharing the same data
+func New() *repository.Repositories {
+	s := &store{
+		readingPlans:     make(map[int]*models.ReadingPlan),
+		questions:        make(map[questionKey]*models.CatechismQuestion),
+		retiredQuestions: make(map[questionKey]*models.CatechismQuestion),
+		sections:         make(map[string][]*models.CatechismSection),
+		syncSeqs:         make(map[int]int64),
+	}
+
+	return &repository.Repositories{
+		Users:             &userRepository{s},
+		ReadingPlans:      &readingPlanRepository{s},
+		UserProgress:      &userProgressRepository{s},
+		Catechism:         &catechismRepository{s},
+		CatechismProgress: &catechismProgressRepository{s},
+		CatechismQuizzes:  &catechismQuizRepository{s},
+		CatechismSections: &catechismSectionRepository{s},
+		Confession:        &confessionRepository{s},
+		ProgressEvents:    &progressEventRepository{s},
+		Notes:             &noteRepository{s},
+		Sync:              &syncRepository{s},
+		Idempotency:       &idempotencyRepository{s},
+		Households:        &householdRepository{s},
+	}
+}
+
+// sameDate compares dates the way a DATE column does
+func sameDate(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// truncateDate keeps only the calendar date, as stored in a DATE column
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
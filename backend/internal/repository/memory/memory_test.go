@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/repository/memory"
+	"biblia-am-pm/internal/repository/repositorytest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		return memory.New()
+	})
+}
@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+type noteRepository struct {
+	s *store
+}
+
+func (r *noteRepository) Create(ctx context.Context, note *models.Note) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	note.ID = r.s.nextID()
+	note.CreatedAt = time.Now().UTC()
+	note.UpdatedAt = note.CreatedAt
+	r.s.notes = append(r.s.notes, r.stored(note))
+	return nil
+}
+
+func (r *noteRepository) Update(ctx context.Context, note *models.Note) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, stored := range r.s.notes {
+		if stored.ID == note.ID && stored.UserID == note.UserID {
+			note.CreatedAt = stored.CreatedAt
+			note.UpdatedAt = time.Now().UTC()
+			r.s.notes[i] = r.stored(note)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// stored returns the copy of a note kept in the store, as the columns hold
+// it. Callers hold the lock.
+func (r *noteRepository) stored(note *models.Note) *models.Note {
+	stored := *note
+	stored.Date = truncateDate(note.Date)
+	stored.QuestionNumber = 0
+	stored.Catechism = ""
+	stored.Tags = append([]string(nil), note.Tags...)
+	sort.Strings(stored.Tags)
+	if note.Reference != nil {
+		reference := *note.Reference
+		stored.Reference = &reference
+	}
+	return &stored
+}
+
+// loaded returns a copy of a stored note with its question number, as the
+// queries join it. Callers hold the lock.
+func (r *noteRepository) loaded(note *models.Note) *models.Note {
+	found := r.stored(note)
+	for _, questions := range []map[questionKey]*models.CatechismQuestion{r.s.questions, r.s.retiredQuestions} {
+		for _, question := range questions {
+			if question.ID == note.QuestionID {
+				found.QuestionNumber = question.QuestionNumber
+				found.Catechism = question.Catechism
+			}
+		}
+	}
+	if found.Tags == nil {
+		found.Tags = []string{}
+	}
+	return found
+}
+
+func (r *noteRepository) Delete(ctx context.Context, userID, id int) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, note := range r.s.notes {
+		if note.ID == id && note.UserID == userID {
+			r.s.notes = append(r.s.notes[:i], r.s.notes[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *noteRepository) GetByID(ctx context.Context, userID, id int) (*models.Note, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, note := range r.s.notes {
+		if note.ID == id && note.UserID == userID {
+			return r.loaded(note), nil
+		}
+	}
+	return nil, nil
+}
+
+// sortedNotes returns the notes of a user matching keep, latest day first.
+// Callers hold the lock.
+func (r *noteRepository) sortedNotes(userID int, keep func(*models.Note) bool) []*models.Note {
+	var notes []*models.Note
+	for _, note := range r.s.notes {
+		if note.UserID == userID && keep(note) {
+			notes = append(notes, r.loaded(note))
+		}
+	}
+	sort.Slice(notes, func(i, j int) bool {
+		if !notes[i].Date.Equal(notes[j].Date) {
+			return notes[i].Date.After(notes[j].Date)
+		}
+		return notes[i].ID > notes[j].ID
+	})
+	return notes
+}
+
+func (r *noteRepository) List(ctx context.Context, userID int, filter models.NoteFilter, limit, offset int) ([]*models.Note, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notes := r.sortedNotes(userID, func(note *models.Note) bool {
+		if filter.Date != nil && !sameDate(note.Date, *filter.Date) {
+			return false
+		}
+		if filter.Book != "" {
+			if note.Reference == nil || note.Reference.Book != filter.Book {
+				return false
+			}
+			if filter.Chapter != 0 && !note.Reference.Covers(filter.Book, filter.Chapter) {
+				return false
+			}
+		}
+		if filter.Tag != "" {
+			for _, tag := range note.Tags {
+				if tag == filter.Tag {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	})
+
+	total := len(notes)
+	if offset > total {
+		offset = total
+	}
+	notes = notes[offset:]
+	if len(notes) > limit {
+		notes = notes[:limit]
+	}
+	if notes == nil {
+		notes = []*models.Note{}
+	}
+	return notes, total, nil
+}
+
+func (r *noteRepository) GetAll(ctx context.Context, userID int) ([]*models.Note, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	notes := r.sortedNotes(userID, func(*models.Note) bool { return true })
+	if notes == nil {
+		notes = []*models.Note{}
+	}
+	return notes, nil
+}
+
+func (r *noteRepository) Search(ctx context.Context, userID int, text string, limit int) ([]*models.NoteSearchResult, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	text = strings.ToLower(strings.TrimSpace(text))
+	notes := r.sortedNotes(userID, func(note *models.Note) bool {
+		return strings.Contains(strings.ToLower(note.Body), text)
+	})
+
+	results := []*models.NoteSearchResult{}
+	for _, note := range notes {
+		if len(results) == limit {
+			break
+		}
+		results = append(results, &models.NoteSearchResult{Note: note, Rank: 1, Snippet: note.Body})
+	}
+	return results, nil
+}
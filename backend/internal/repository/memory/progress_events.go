@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+type progressEventRepository struct {
+	s *store
+}
+
+func (r *progressEventRepository) Record(ctx context.Context, userID int, events []*models.ProgressEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, event := range events {
+		if event.Type != models.ProgressTypeReading && event.Type != models.ProgressTypeCatechism {
+			return fmt.Errorf("unknown progress event type %q", event.Type)
+		}
+	}
+
+	seq := r.s.nextChangeSeq(userID)
+	for _, event := range events {
+		event.UserID = userID
+		event.OccurredAt = event.OccurredAt.UTC().Truncate(time.Microsecond)
+
+		history := r.s.progressEventsOf(event)
+		if !r.s.appendProgressEvent(history, event) {
+			continue
+		}
+		history = append(history, event)
+
+		if event.Type == models.ProgressTypeReading {
+			progress := r.s.getUserProgress(userID, event.Date)
+			if progress == nil {
+				progress = &models.UserProgress{UserID: userID, ReadingPlanID: event.ReadingPlanID, Date: event.Date}
+			}
+			if event.Applied = progress.SetPeriod(event.Period, models.CompletionTime(history)); event.Applied {
+				r.s.saveUserProgress(progress, seq)
+			}
+			continue
+		}
+
+		progress := r.s.getCatechismProgress(userID, event.QuestionID, event.Date, event.Step)
+		if progress == nil {
+			progress = &models.CatechismProgress{UserID: userID, QuestionID: event.QuestionID, Date: event.Date, Step: event.Step}
+		}
+		if event.Applied = progress.SetCompletion(models.CompletionTime(history)); event.Applied {
+			r.s.saveCatechismProgress(progress, seq)
+		}
+	}
+	return nil
+}
+
+// progressEventsOf returns the events recorded for the period or step of
+// event. Callers hold the lock.
+func (s *store) progressEventsOf(event *models.ProgressEvent) []*models.ProgressEvent {
+	var history []*models.ProgressEvent
+	for _, recorded := range s.progressEvents {
+		if recorded.UserID == event.UserID && recorded.Type == event.Type && sameDate(recorded.Date, event.Date) &&
+			recorded.Period == event.Period && recorded.QuestionID == event.QuestionID && recorded.Step == event.Step {
+			history = append(history, recorded)
+		}
+	}
+	return history
+}
+
+// appendProgressEvent stores a copy of the event unless history already has
+// it, and reports whether it did. Callers hold the lock.
+func (s *store) appendProgressEvent(history []*models.ProgressEvent, event *models.ProgressEvent) bool {
+	for _, recorded := range history {
+		if recorded.SameAs(event) {
+			return false
+		}
+	}
+
+	event.ID = s.nextID()
+	event.CreatedAt = time.Now().UTC()
+	stored := *event
+	stored.Date = truncateDate(event.Date)
+	s.progressEvents = append(s.progressEvents, &stored)
+	return true
+}
+
+func (r *progressEventRepository) GetByUserAndDate(ctx context.Context, userID int, date time.Time) ([]*models.ProgressEvent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var events []*models.ProgressEvent
+	for _, event := range r.s.progressEvents {
+		if event.UserID == userID && sameDate(event.Date, date) {
+			found := *event
+			events = append(events, &found)
+		}
+	}
+	return events, nil
+}
+
+func (r *progressEventRepository) GetByUser(ctx context.Context, userID int) ([]*models.ProgressEvent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var events []*models.ProgressEvent
+	for _, event := range r.s.progressEvents {
+		if event.UserID == userID {
+			found := *event
+			events = append(events, &found)
+		}
+	}
+	return events, nil
+}
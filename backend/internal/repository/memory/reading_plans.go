@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+)
+
+type readingPlanRepository struct {
+	s *store
+}
+
+func (r *readingPlanRepository) GetByDayOfYear(ctx context.Context, dayOfYear int) (*models.ReadingPlan, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	plan, ok := r.s.readingPlans[dayOfYear]
+	if !ok {
+		return nil, nil
+	}
+	found := *plan
+	return &found, nil
+}
+
+func (r *readingPlanRepository) Create(ctx context.Context, plan *models.ReadingPlan) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if existing, ok := r.s.readingPlans[plan.DayOfYear]; ok {
+		plan.ID = existing.ID
+	} else {
+		plan.ID = r.s.nextID()
+	}
+	stored := *plan
+	r.s.readingPlans[plan.DayOfYear] = &stored
+	return nil
+}
+
+func (r *readingPlanRepository) GetAll(ctx context.Context) ([]*models.ReadingPlan, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var plans []*models.ReadingPlan
+	for _, plan := range r.s.readingPlans {
+		found := *plan
+		plans = append(plans, &found)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].DayOfYear < plans[j].DayOfYear })
+	return plans, nil
+}
+
+func (r *readingPlanRepository) GetTotalCount(ctx context.Context) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return len(r.s.readingPlans), nil
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+)
+
+type syncRepository struct {
+	s *store
+}
+
+func (r *syncRepository) GetChanges(ctx context.Context, userID int, after int64) (*models.SyncChanges, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	changes := &models.SyncChanges{Cursor: r.s.syncSeqs[userID]}
+	for _, progress := range r.s.userProgress {
+		if progress.UserID == userID && progress.ChangeSeq > after {
+			found := *progress
+			changes.Readings = append(changes.Readings, &found)
+		}
+	}
+	for _, progress := range r.s.catechismProgress {
+		if progress.UserID == userID && progress.ChangeSeq > after {
+			found := *progress
+			changes.Catechism = append(changes.Catechism, &found)
+		}
+	}
+
+	sort.SliceStable(changes.Readings, func(i, j int) bool { return changes.Readings[i].ChangeSeq < changes.Readings[j].ChangeSeq })
+	sort.SliceStable(changes.Catechism, func(i, j int) bool { return changes.Catechism[i].ChangeSeq < changes.Catechism[j].ChangeSeq })
+	return changes, nil
+}
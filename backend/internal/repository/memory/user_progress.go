@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"sort"
+	"time"
+)
+
+type userProgressRepository struct {
+	s *store
+}
+
+func (r *userProgressRepository) GetByUserAndDate(ctx context.Context, userID int, date time.Time) (*models.UserProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	return r.s.getUserProgress(userID, date), nil
+}
+
+// getUserProgress returns a copy of the progress of a day, or nil. Callers
+// hold the lock.
+func (s *store) getUserProgress(userID int, date time.Time) *models.UserProgress {
+	for _, progress := range s.userProgress {
+		if progress.UserID == userID && sameDate(progress.Date, date) {
+			found := *progress
+			return &found
+		}
+	}
+	return nil
+}
+
+// saveUserProgress upserts the progress with the given change sequence number.
+// Callers hold the lock.
+func (s *store) saveUserProgress(progress *models.UserProgress, seq int64) {
+	progress.ChangeSeq = seq
+	stored := *progress
+	stored.Date = truncateDate(progress.Date)
+
+	for i, existing := range s.userProgress {
+		if existing.UserID == progress.UserID && existing.ReadingPlanID == progress.ReadingPlanID && sameDate(existing.Date, progress.Date) {
+			stored.ID = existing.ID
+			progress.ID = existing.ID
+			s.userProgress[i] = &stored
+			return
+		}
+	}
+
+	stored.ID = s.nextID()
+	progress.ID = stored.ID
+	s.userProgress = append(s.userProgress, &stored)
+}
+
+func (r *userProgressRepository) GetUserProgress(ctx context.Context, userID int) ([]*models.UserProgress, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var progresses []*models.UserProgress
+	for _, progress := range r.s.userProgress {
+		if progress.UserID == userID {
+			found := *progress
+			progresses = append(progresses, &found)
+		}
+	}
+	sort.SliceStable(progresses, func(i, j int) bool { return progresses[i].Date.After(progresses[j].Date) })
+	return progresses, nil
+}
+
+func (r *userProgressRepository) CountCompletedOnDate(ctx context.Context, date time.Time) (int, int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var morning, evening int
+	for _, progress := range r.s.userProgress {
+		if !sameDate(progress.Date, date) {
+			continue
+		}
+		if progress.MorningCompleted {
+			morning++
+		}
+		if progress.EveningCompleted {
+			evening++
+		}
+	}
+	return morning, evening, nil
+}
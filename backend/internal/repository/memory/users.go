@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+type userRepository struct {
+	s *store
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, email, hashedPassword string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Email == email {
+			return nil, fmt.Errorf("duplicate email %q", email)
+		}
+	}
+
+	user := &models.User{
+		ID:            r.s.nextID(),
+		Email:         email,
+		Password:      hashedPassword,
+		Role:          models.RoleUser,
+		CatechismMode: models.CatechismModeWeekly,
+		Catechism:     models.CatechismShorter,
+		CreatedAt:     time.Now(),
+	}
+	r.s.users = append(r.s.users, user)
+
+	created := *user
+	created.Password = ""
+	return &created, nil
+}
+
+func (r *userRepository) find(match func(*models.User) bool) *models.User {
+	for _, user := range r.s.users {
+		if match(user) {
+			return user
+		}
+	}
+	return nil
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.find(func(u *models.User) bool { return u.Email == email })
+	if user == nil {
+		return nil, nil
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *userRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.find(func(u *models.User) bool { return u.ID == id })
+	if user == nil {
+		return nil, nil
+	}
+	found := *user
+	found.Password = ""
+	return &found, nil
+}
+
+func (r *userRepository) SetRole(ctx context.Context, email, role string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user := r.find(func(u *models.User) bool { return u.Email == email })
+	if user == nil {
+		return false, nil
+	}
+	user.Role = role
+	return true, nil
+}
+
+func (r *userRepository) SetCatechismMode(ctx context.Context, userID int, mode string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user := r.find(func(u *models.User) bool { return u.ID == userID }); user != nil {
+		user.CatechismMode = mode
+	}
+	return nil
+}
+
+func (r *userRepository) SetLocale(ctx context.Context, userID int, locale string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user := r.find(func(u *models.User) bool { return u.ID == userID }); user != nil {
+		user.Locale = locale
+	}
+	return nil
+}
+
+func (r *userRepository) SetCatechism(ctx context.Context, userID int, catechism string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if user := r.find(func(u *models.User) bool { return u.ID == userID }); user != nil {
+		user.Catechism = catechism
+	}
+	return nil
+}
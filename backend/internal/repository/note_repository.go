@@ -0,0 +1,325 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type noteRepository struct {
+	db *DB
+}
+
+func NewNoteRepository(db *DB) NoteRepository {
+	return &noteRepository{db: db}
+}
+
+// NoteColumns are the columns of a note, selected from notes n joined to
+// westminster_catechism q. They are exported for the search queries of the
+// other backends; see QueryNoteSearch.
+const NoteColumns = `n.id, n.user_id, n.date, n.reading_plan_id, n.period, n.reference, n.book, n.chapter,
+	n.verse, n.end_chapter, n.end_verse, n.question_id, COALESCE(q.question_number, 0), COALESCE(q.catechism, ''),
+	n.body,
+	n.created_at, n.updated_at`
+
+const noteTables = `notes n LEFT JOIN westminster_catechism q ON q.id = n.question_id`
+
+func (r *noteRepository) Create(ctx context.Context, note *models.Note) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO notes (user_id, date, reading_plan_id, period, reference, book, chapter, verse,
+	                             end_chapter, end_verse, question_id, body, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	          RETURNING id`
+
+	note.CreatedAt = time.Now().UTC()
+	note.UpdatedAt = note.CreatedAt
+	args := append([]interface{}{note.UserID, note.Date.Format("2006-01-02"), note.ReadingPlanID, note.Period},
+		noteSubjectArgs(note)...)
+	args = append(args, note.Body, note.CreatedAt, note.UpdatedAt)
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&note.ID); err != nil {
+		return err
+	}
+
+	if err := insertNoteTagsTx(ctx, tx, note); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *noteRepository) Update(ctx context.Context, note *models.Note) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE notes SET date = $1, reading_plan_id = $2, period = $3, reference = $4, book = $5,
+	                           chapter = $6, verse = $7, end_chapter = $8, end_verse = $9, question_id = $10,
+	                           body = $11, updated_at = $12
+	          WHERE id = $13 AND user_id = $14
+	          RETURNING created_at`
+
+	note.UpdatedAt = time.Now().UTC()
+	args := append([]interface{}{note.Date.Format("2006-01-02"), note.ReadingPlanID, note.Period}, noteSubjectArgs(note)...)
+	args = append(args, note.Body, note.UpdatedAt, note.ID, note.UserID)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&note.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM note_tags WHERE note_id = $1`, note.ID); err != nil {
+		return false, err
+	}
+	if err := insertNoteTagsTx(ctx, tx, note); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// noteSubjectArgs returns the values of the reference and question columns,
+// NULL for what the note isn't about
+func noteSubjectArgs(note *models.Note) []interface{} {
+	args := make([]interface{}, 0, 7)
+	if ref := note.Reference; ref != nil {
+		args = append(args, ref.Text, ref.Book, ref.Chapter,
+			sql.NullInt64{Int64: int64(ref.Verse), Valid: ref.Verse != 0},
+			ref.EndChapter,
+			sql.NullInt64{Int64: int64(ref.EndVerse), Valid: ref.EndVerse != 0})
+	} else {
+		args = append(args, nil, nil, nil, nil, nil, nil)
+	}
+	return append(args, sql.NullInt64{Int64: int64(note.QuestionID), Valid: note.QuestionID != 0})
+}
+
+func insertNoteTagsTx(ctx context.Context, tx DBTX, note *models.Note) error {
+	for _, tag := range note.Tags {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO note_tags (note_id, tag) VALUES ($1, $2)`, note.ID, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *noteRepository) Delete(ctx context.Context, userID, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM notes WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (r *noteRepository) GetByID(ctx context.Context, userID, id int) (*models.Note, error) {
+	query := `SELECT ` + NoteColumns + ` FROM ` + noteTables + ` WHERE n.id = $1 AND n.user_id = $2`
+
+	note, err := scanNote(r.db.QueryRowContext(ctx, query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadNoteTags(ctx, r.db, []*models.Note{note}); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+func (r *noteRepository) List(ctx context.Context, userID int, filter models.NoteFilter, limit, offset int) ([]*models.Note, int, error) {
+	conditions := []string{"n.user_id = $1"}
+	args := []interface{}{userID}
+	where := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Date != nil {
+		where("n.date = $%d", filter.Date.Format("2006-01-02"))
+	}
+	if filter.Book != "" {
+		where("n.book = $%d", filter.Book)
+		if filter.Chapter != 0 {
+			where("n.chapter <= $%d", filter.Chapter)
+			where("n.end_chapter >= $%d", filter.Chapter)
+		}
+	}
+	if filter.Tag != "" {
+		where("EXISTS (SELECT 1 FROM note_tags t WHERE t.note_id = n.id AND t.tag = $%d)", filter.Tag)
+	}
+	whereClause := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notes n WHERE `+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s
+	                      ORDER BY n.date DESC, n.id DESC
+	                      LIMIT $%d OFFSET $%d`, NoteColumns, noteTables, whereClause, len(args)+1, len(args)+2)
+
+	notes, err := queryNotes(ctx, r.db, query, append(args, limit, offset)...)
+	return notes, total, err
+}
+
+func (r *noteRepository) GetAll(ctx context.Context, userID int) ([]*models.Note, error) {
+	query := `SELECT ` + NoteColumns + ` FROM ` + noteTables + ` WHERE n.user_id = $1
+	          ORDER BY n.date DESC, n.id DESC`
+
+	return queryNotes(ctx, r.db, query, userID)
+}
+
+// queryNotes runs a query selecting NoteColumns and loads the tags of the
+// notes found
+func queryNotes(ctx context.Context, db DBTX, query string, args ...interface{}) ([]*models.Note, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []*models.Note{}
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return notes, loadNoteTags(ctx, db, notes)
+}
+
+// Search finds the notes whose body matches the query, using Portuguese
+// stemming and ignoring accents, with the matches highlighted in <mark> tags
+func (r *noteRepository) Search(ctx context.Context, userID int, text string, limit int) ([]*models.NoteSearchResult, error) {
+	query := `SELECT ` + NoteColumns + `,
+	                 ts_rank(n.search_vector, tsq) AS rank,
+	                 ts_headline('portuguese_unaccent', n.body, tsq,
+	                             'StartSel=<mark>, StopSel=</mark>, MinWords=15, MaxWords=35, MaxFragments=2')
+	          FROM ` + noteTables + `, websearch_to_tsquery('portuguese_unaccent', $1) tsq
+	          WHERE n.user_id = $2 AND n.search_vector @@ tsq
+	          ORDER BY rank DESC, n.date DESC, n.id DESC
+	          LIMIT $3`
+
+	return QueryNoteSearch(ctx, r.db, query, text, userID, limit)
+}
+
+// QueryNoteSearch runs a search query selecting NoteColumns followed by the
+// rank and the snippet, and loads the tags of the notes found
+func QueryNoteSearch(ctx context.Context, db DBTX, query string, args ...interface{}) ([]*models.NoteSearchResult, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*models.NoteSearchResult{}
+	var notes []*models.Note
+	for rows.Next() {
+		result := &models.NoteSearchResult{}
+		result.Note, err = scanNote(rows, &result.Rank, &result.Snippet)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+		notes = append(notes, result.Note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, loadNoteTags(ctx, db, notes)
+}
+
+// scanNote scans the note columns, followed by extra when given
+func scanNote(row rowScanner, extra ...interface{}) (*models.Note, error) {
+	note := &models.Note{}
+	var reference, book sql.NullString
+	var chapter, verse, endChapter, endVerse, questionID sql.NullInt64
+
+	dest := []interface{}{
+		&note.ID,
+		&note.UserID,
+		&note.Date,
+		&note.ReadingPlanID,
+		&note.Period,
+		&reference,
+		&book,
+		&chapter,
+		&verse,
+		&endChapter,
+		&endVerse,
+		&questionID,
+		&note.QuestionNumber,
+		&note.Catechism,
+		&note.Body,
+		&note.CreatedAt,
+		&note.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if reference.Valid {
+		note.Reference = &models.BibleReference{
+			Text:       reference.String,
+			Book:       book.String,
+			Chapter:    int(chapter.Int64),
+			Verse:      int(verse.Int64),
+			EndChapter: int(endChapter.Int64),
+			EndVerse:   int(endVerse.Int64),
+		}
+	}
+	note.QuestionID = int(questionID.Int64)
+	note.Tags = []string{}
+	return note, nil
+}
+
+// loadNoteTags sets the tags of notes, in alphabetical order
+func loadNoteTags(ctx context.Context, db DBTX, notes []*models.Note) error {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Note, len(notes))
+	placeholders := make([]string, 0, len(notes))
+	args := make([]interface{}, 0, len(notes))
+	for _, note := range notes {
+		byID[note.ID] = note
+		args = append(args, note.ID)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT note_id, tag FROM note_tags
+	                                   WHERE note_id IN (`+strings.Join(placeholders, ", ")+`)
+	                                   ORDER BY tag`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noteID int
+		var tag string
+		if err := rows.Scan(&noteID, &tag); err != nil {
+			return err
+		}
+		byID[noteID].Tags = append(byID[noteID].Tags, tag)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type progressEventRepository struct {
+	db *DB
+}
+
+func NewProgressEventRepository(db *DB) ProgressEventRepository {
+	return &progressEventRepository{db: db}
+}
+
+const progressEventColumns = `id, user_id, type, date, reading_plan_id, period, question_id, step,
+	          action, occurred_at, source, created_at`
+
+func (r *progressEventRepository) Record(ctx context.Context, userID int, events []*models.ProgressEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// A single number for the whole batch, taken before reading the log so
+	// that no other change of the user gets in between
+	seq, err := nextChangeSeqTx(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		event.UserID = userID
+		// TIMESTAMP columns keep microseconds, and the log is compared with
+		// the events sent again
+		event.OccurredAt = event.OccurredAt.UTC().Truncate(time.Microsecond)
+
+		switch event.Type {
+		case models.ProgressTypeReading:
+			err = recordReadingEventTx(ctx, tx, event, seq)
+		case models.ProgressTypeCatechism:
+			err = recordCatechismEventTx(ctx, tx, event, seq)
+		default:
+			err = fmt.Errorf("unknown progress event type %q", event.Type)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func recordReadingEventTx(ctx context.Context, tx DBTX, event *models.ProgressEvent, seq int64) error {
+	history, err := queryProgressEvents(ctx, tx, `SELECT `+progressEventColumns+`
+	          FROM progress_events WHERE user_id = $1 AND type = $2 AND date = $3 AND period = $4`,
+		event.UserID, event.Type, event.Date.Format("2006-01-02"), event.Period)
+	if err != nil {
+		return err
+	}
+	if recorded, err := appendProgressEventTx(ctx, tx, history, event); err != nil || !recorded {
+		return err
+	}
+
+	progress, err := getUserProgressTx(ctx, tx, event.UserID, event.Date)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = &models.UserProgress{UserID: event.UserID, ReadingPlanID: event.ReadingPlanID, Date: event.Date}
+	}
+
+	event.Applied = progress.SetPeriod(event.Period, models.CompletionTime(append(history, event)))
+	if !event.Applied {
+		return nil
+	}
+	return saveUserProgressTx(ctx, tx, progress, seq)
+}
+
+func recordCatechismEventTx(ctx context.Context, tx DBTX, event *models.ProgressEvent, seq int64) error {
+	history, err := queryProgressEvents(ctx, tx, `SELECT `+progressEventColumns+`
+	          FROM progress_events WHERE user_id = $1 AND type = $2 AND date = $3 AND question_id = $4 AND step = $5`,
+		event.UserID, event.Type, event.Date.Format("2006-01-02"), event.QuestionID, event.Step)
+	if err != nil {
+		return err
+	}
+	if recorded, err := appendProgressEventTx(ctx, tx, history, event); err != nil || !recorded {
+		return err
+	}
+
+	progress, err := getCatechismProgressTx(ctx, tx, event.UserID, event.QuestionID, event.Date, event.Step)
+	if err != nil {
+		return err
+	}
+	if progress == nil {
+		progress = &models.CatechismProgress{UserID: event.UserID, QuestionID: event.QuestionID, Date: event.Date, Step: event.Step}
+	}
+
+	event.Applied = progress.SetCompletion(models.CompletionTime(append(history, event)))
+	if !event.Applied {
+		return nil
+	}
+	return saveCatechismProgressTx(ctx, tx, progress, seq)
+}
+
+// appendProgressEventTx inserts the event unless history, the events of its
+// period or step, already has it. It reports whether it did.
+func appendProgressEventTx(ctx context.Context, tx DBTX, history []*models.ProgressEvent, event *models.ProgressEvent) (bool, error) {
+	for _, recorded := range history {
+		if recorded.SameAs(event) {
+			return false, nil
+		}
+	}
+
+	query := `INSERT INTO progress_events (user_id, type, date, reading_plan_id, period, question_id, step,
+	                                       action, occurred_at, source, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	          RETURNING id`
+
+	event.CreatedAt = time.Now().UTC()
+	err := tx.QueryRowContext(ctx, query,
+		event.UserID,
+		event.Type,
+		event.Date.Format("2006-01-02"),
+		sql.NullInt64{Int64: int64(event.ReadingPlanID), Valid: event.ReadingPlanID != 0},
+		sql.NullString{String: event.Period, Valid: event.Period != ""},
+		sql.NullInt64{Int64: int64(event.QuestionID), Valid: event.QuestionID != 0},
+		sql.NullString{String: event.Step, Valid: event.Step != ""},
+		event.Action,
+		event.OccurredAt,
+		event.Source,
+		event.CreatedAt,
+	).Scan(&event.ID)
+	return err == nil, err
+}
+
+func (r *progressEventRepository) GetByUserAndDate(ctx context.Context, userID int, date time.Time) ([]*models.ProgressEvent, error) {
+	query := `SELECT ` + progressEventColumns + `
+	          FROM progress_events WHERE user_id = $1 AND date = $2 ORDER BY id`
+
+	return queryProgressEvents(ctx, r.db, query, userID, date.Format("2006-01-02"))
+}
+
+func (r *progressEventRepository) GetByUser(ctx context.Context, userID int) ([]*models.ProgressEvent, error) {
+	query := `SELECT ` + progressEventColumns + `
+	          FROM progress_events WHERE user_id = $1 ORDER BY id`
+
+	return queryProgressEvents(ctx, r.db, query, userID)
+}
+
+func queryProgressEvents(ctx context.Context, tx DBTX, query string, args ...interface{}) ([]*models.ProgressEvent, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*models.ProgressEvent
+	for rows.Next() {
+		event := &models.ProgressEvent{}
+		var readingPlanID, questionID sql.NullInt64
+		var period, step sql.NullString
+
+		err := rows.Scan(
+			&event.ID,
+			&event.UserID,
+			&event.Type,
+			&event.Date,
+			&readingPlanID,
+			&period,
+			&questionID,
+			&step,
+			&event.Action,
+			&event.OccurredAt,
+			&event.Source,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.ReadingPlanID = int(readingPlanID.Int64)
+		event.Period = period.String
+		event.QuestionID = int(questionID.Int64)
+		event.Step = step.String
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
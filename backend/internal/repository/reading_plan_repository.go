@@ -1,23 +1,25 @@
 package repository
 
 import (
-	"biblia-am-pm/internal/database"
 	"biblia-am-pm/internal/models"
+	"context"
 	"database/sql"
 )
 
-type ReadingPlanRepository struct{}
+type readingPlanRepository struct {
+	db *DB
+}
 
-func NewReadingPlanRepository() *ReadingPlanRepository {
-	return &ReadingPlanRepository{}
+func NewReadingPlanRepository(db *DB) ReadingPlanRepository {
+	return &readingPlanRepository{db: db}
 }
 
-func (r *ReadingPlanRepository) GetByDayOfYear(dayOfYear int) (*models.ReadingPlan, error) {
+func (r *readingPlanRepository) GetByDayOfYear(ctx context.Context, dayOfYear int) (*models.ReadingPlan, error) {
 	query := `SELECT id, day_of_year, old_testament_ref, new_testament_ref, psalms_ref, proverbs_ref 
 	          FROM reading_plans WHERE day_of_year = $1`
 	
 	plan := &models.ReadingPlan{}
-	err := database.DB.QueryRow(query, dayOfYear).Scan(
+	err := r.db.QueryRowContext(ctx, query, dayOfYear).Scan(
 		&plan.ID,
 		&plan.DayOfYear,
 		&plan.OldTestamentRef,
@@ -37,7 +39,7 @@ func (r *ReadingPlanRepository) GetByDayOfYear(dayOfYear int) (*models.ReadingPl
 	return plan, nil
 }
 
-func (r *ReadingPlanRepository) Create(plan *models.ReadingPlan) error {
+func (r *readingPlanRepository) Create(ctx context.Context, plan *models.ReadingPlan) error {
 	query := `INSERT INTO reading_plans (day_of_year, old_testament_ref, new_testament_ref, psalms_ref, proverbs_ref) 
 	          VALUES ($1, $2, $3, $4, $5)
 	          ON CONFLICT (day_of_year) 
@@ -48,7 +50,7 @@ func (r *ReadingPlanRepository) Create(plan *models.ReadingPlan) error {
 	            proverbs_ref = EXCLUDED.proverbs_ref
 	          RETURNING id`
 	
-	err := database.DB.QueryRow(query,
+	err := r.db.QueryRowContext(ctx, query,
 		plan.DayOfYear,
 		plan.OldTestamentRef,
 		plan.NewTestamentRef,
@@ -59,11 +61,11 @@ func (r *ReadingPlanRepository) Create(plan *models.ReadingPlan) error {
 	return err
 }
 
-func (r *ReadingPlanRepository) GetAll() ([]*models.ReadingPlan, error) {
+func (r *readingPlanRepository) GetAll(ctx context.Context) ([]*models.ReadingPlan, error) {
 	query := `SELECT id, day_of_year, old_testament_ref, new_testament_ref, psalms_ref, proverbs_ref 
 	          FROM reading_plans ORDER BY day_of_year`
 	
-	rows, err := database.DB.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -89,3 +91,9 @@ func (r *ReadingPlanRepository) GetAll() ([]*models.ReadingPlan, error) {
 	return plans, rows.Err()
 }
 
+func (r *readingPlanRepository) GetTotalCount(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM reading_plans`
+	var count int
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,237 @@
+// Package repository defines the storage interfaces used by the handlers and
+// their PostgreSQL implementations. The SQLite implementations in the sqlite
+// package reuse the queries here that are portable.
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// DBTX is implemented by both *DB and *Tx, so queries can run inside or
+// outside a transaction
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, email, hashedPassword string) (*models.User, error)
+	// GetUserByEmail and GetUserByID return nil when the user doesn't exist.
+	// Only GetUserByEmail loads the password hash.
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id int) (*models.User, error)
+	// SetRole returns false when no user has the given email
+	SetRole(ctx context.Context, email, role string) (bool, error)
+	SetCatechismMode(ctx context.Context, userID int, mode string) error
+	SetLocale(ctx context.Context, userID int, locale string) error
+	SetCatechism(ctx context.Context, userID int, catechism string) error
+}
+
+type ReadingPlanRepository interface {
+	// GetByDayOfYear returns nil when the day has no reading plan
+	GetByDayOfYear(ctx context.Context, dayOfYear int) (*models.ReadingPlan, error)
+	// Create inserts or updates the plan of a day
+	Create(ctx context.Context, plan *models.ReadingPlan) error
+	GetAll(ctx context.Context) ([]*models.ReadingPlan, error)
+	GetTotalCount(ctx context.Context) (int, error)
+}
+
+type UserProgressRepository interface {
+	// GetByUserAndDate returns nil when there is no progress for the date
+	GetByUserAndDate(ctx context.Context, userID int, date time.Time) (*models.UserProgress, error)
+	GetUserProgress(ctx context.Context, userID int) ([]*models.UserProgress, error)
+	// CountCompletedOnDate counts, across users, the morning and evening readings completed on a date
+	CountCompletedOnDate(ctx context.Context, date time.Time) (morning int, evening int, err error)
+}
+
+// CatechismRepository stores the questions of the Shorter and the Larger
+// Catechism, each numbered from 1. Questions are looked up by their catechism
+// and number.
+type CatechismRepository interface {
+	// GetByQuestionNumber returns nil when the question doesn't exist
+	GetByQuestionNumber(ctx context.Context, catechism string, questionNumber int) (*models.CatechismQuestion, error)
+	GetAll(ctx context.Context, catechism string) ([]*models.CatechismQuestion, error)
+	GetByQuestionRange(ctx context.Context, catechism string, start, end int) ([]*models.CatechismQuestion, error)
+	// GetPage returns a page of the questions in [start, end] and how many questions the range has
+	GetPage(ctx context.Context, catechism string, start, end, limit, offset int) ([]*models.CatechismQuestion, int, error)
+	// GetAdjacentNumbers returns the previous and next existing question numbers, or 0
+	GetAdjacentNumbers(ctx context.Context, catechism string, questionNumber int) (int, int, error)
+	Create(ctx context.Context, question *models.CatechismQuestion) error
+	// Save inserts or updates the question with the catechism and number of
+	// question, the Shorter Catechism when it has none, restoring it if retired
+	Save(ctx context.Context, question *models.CatechismQuestion, authorID *int) error
+	// ApplyImport saves upserts into catechism and retires its questions
+	// numbered removeNumbers. Retired questions are left out of every query
+	// but keep their revisions, progress and notes.
+	ApplyImport(ctx context.Context, catechism string, upserts []*models.CatechismQuestion, removeNumbers []int, authorID *int) error
+	Search(ctx context.Context, text string, catechism string, limit int) ([]*models.CatechismSearchResult, error)
+	GetRevisions(ctx context.Context, questionID int) ([]*models.CatechismRevision, error)
+	// GetRevision returns nil when the revision doesn't exist
+	GetRevision(ctx context.Context, questionID int, revisionNumber int) (*models.CatechismRevision, error)
+	CreateBatch(ctx context.Context, questions []*models.CatechismQuestion) error
+	// GetTotalCount counts the questions of every catechism
+	GetTotalCount(ctx context.Context) (int, error)
+	// GetMaxQuestionNumber returns 0 when the catechism is empty
+	GetMaxQuestionNumber(ctx context.Context, catechism string) (int, error)
+}
+
+type CatechismProgressRepository interface {
+	// GetByUserAndDate returns nil when the step wasn't completed on the date
+	GetByUserAndDate(ctx context.Context, userID int, questionID int, date time.Time, step string) (*models.CatechismProgress, error)
+	GetByUserAndQuestionForWeek(ctx context.Context, userID int, questionID int, weekStart time.Time) ([]*models.CatechismProgress, error)
+	GetUserProgress(ctx context.Context, userID int) ([]*models.CatechismProgress, error)
+	// GetQuestionStatuses summarizes the progress of a user by question ID
+	GetQuestionStatuses(ctx context.Context, userID int) (map[int]*models.CatechismQuestionStatus, error)
+	// CountCompletedOnDate counts, across users, the catechism steps completed on a date
+	CountCompletedOnDate(ctx context.Context, date time.Time) (int, error)
+}
+
+type CatechismQuizRepository interface {
+	Create(ctx context.Context, quiz *models.CatechismQuiz) error
+	// GetByIDAndUser returns nil when the quiz doesn't exist or belongs to another user
+	GetByIDAndUser(ctx context.Context, id int, userID int) (*models.CatechismQuiz, error)
+	// SaveResult returns false when the quiz was already submitted
+	SaveResult(ctx context.Context, quiz *models.CatechismQuiz) (bool, error)
+	GetSubmittedByUser(ctx context.Context, userID int) ([]*models.CatechismQuiz, error)
+}
+
+type CatechismSectionRepository interface {
+	GetByCatechism(ctx context.Context, catechism string) ([]*models.CatechismSection, error)
+	ReplaceForCatechism(ctx context.Context, catechism string, sections []*models.CatechismSection) error
+}
+
+type ConfessionRepository interface {
+	GetChapters(ctx context.Context) ([]*models.ConfessionChapter, error)
+	// GetChapter returns nil when the chapter doesn't exist
+	GetChapter(ctx context.Context, chapterNumber int) (*models.ConfessionChapter, error)
+	GetLinkedQuestions(ctx context.Context, chapterNumber int, catechism string) (map[int][]int, error)
+	GetReferencesForQuestion(ctx context.Context, catechism string, questionNumber int) ([]*models.ConfessionReference, error)
+	ReplaceAll(ctx context.Context, chapters []*models.ConfessionChapter) error
+	ReplaceLinks(ctx context.Context, catechism string, links []*models.CatechismConfessionLink) error
+}
+
+// ProgressEventRepository keeps the append-only log of the marks and unmarks
+// of the users' progress. The user_progress and catechism_progress rows are
+// its projection, only written when events are recorded.
+type ProgressEventRepository interface {
+	// Record appends the events of a user and projects them onto the progress
+	// in a single transaction, setting Applied on the events that changed it.
+	// An event the log already has, as when a client sends it again, is
+	// skipped.
+	Record(ctx context.Context, userID int, events []*models.ProgressEvent) error
+	// GetByUserAndDate returns the events of a day in the order they were recorded
+	GetByUserAndDate(ctx context.Context, userID int, date time.Time) ([]*models.ProgressEvent, error)
+	// GetByUser returns every event of a user in the order they were recorded
+	GetByUser(ctx context.Context, userID int) ([]*models.ProgressEvent, error)
+}
+
+// NoteRepository stores the notes of the users. Notes are read and changed
+// through the ID of their user, so no user reaches another's.
+type NoteRepository interface {
+	// Create inserts a note with its tags, setting its ID and times
+	Create(ctx context.Context, note *models.Note) error
+	// Update replaces the content and the tags of a note, setting UpdatedAt.
+	// It returns false when the user has no note with its ID.
+	Update(ctx context.Context, note *models.Note) (bool, error)
+	// Delete returns false when the user has no note with the ID
+	Delete(ctx context.Context, userID, id int) (bool, error)
+	// GetByID returns nil when the user has no note with the ID
+	GetByID(ctx context.Context, userID, id int) (*models.Note, error)
+	// List returns a page of the notes matching filter, latest day first, and
+	// how many notes match
+	List(ctx context.Context, userID int, filter models.NoteFilter, limit, offset int) ([]*models.Note, int, error)
+	// GetAll returns every note of a user, latest day first
+	GetAll(ctx context.Context, userID int) ([]*models.Note, error)
+	// Search finds the notes whose body matches text, best matches first
+	Search(ctx context.Context, userID int, text string, limit int) ([]*models.NoteSearchResult, error)
+}
+
+// SyncRepository tells offline clients what changed since their last sync.
+// Every progress write takes the next change sequence number of its user,
+// which cursors refer to.
+type SyncRepository interface {
+	// GetChanges returns the progress rows of a user changed after the given
+	// sequence number, or all of them when it's negative
+	GetChanges(ctx context.Context, userID int, after int64) (*models.SyncChanges, error)
+}
+
+// IdempotencyRepository stores the responses to requests sent with an
+// idempotency key. Keys belong to a user.
+type IdempotencyRepository interface {
+	// Reserve takes the key for a request that is about to run. It returns
+	// false when another record holds the key and hasn't expired.
+	Reserve(ctx context.Context, record *models.IdempotencyRecord) (bool, error)
+	// Get returns nil when the user has no record with the key
+	Get(ctx context.Context, userID int, key string) (*models.IdempotencyRecord, error)
+	// Complete stores the response and expiry of a reserved key
+	Complete(ctx context.Context, record *models.IdempotencyRecord) error
+	// Release deletes a reservation, so that the request can run again
+	Release(ctx context.Context, userID int, key string) error
+	// DeleteExpired deletes the records expired at now and returns how many there were
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// HouseholdRepository groups users in households, whose members follow each
+// other's progress. A user belongs to at most one household.
+type HouseholdRepository interface {
+	// Create makes a household with the invite code and userID as its only
+	// member. It returns nil when the user already belongs to a household.
+	Create(ctx context.Context, userID int, inviteCode string) (*models.Household, error)
+	// Join adds userID to the household with the invite code. It returns nil
+	// when no household has the code or the user already belongs to one.
+	Join(ctx context.Context, userID int, inviteCode string) (*models.Household, error)
+	// Leave takes userID out of their household, deleting it when nobody is
+	// left. It returns false when the user doesn't belong to a household.
+	Leave(ctx context.Context, userID int) (bool, error)
+	// GetByUser returns the household of userID with its members in the order
+	// they joined, or nil when the user doesn't belong to one
+	GetByUser(ctx context.Context, userID int) (*models.Household, error)
+	// GetMemberIDs returns the IDs of the members of the household of userID,
+	// userID included, or only userID when the user doesn't belong to one
+	GetMemberIDs(ctx context.Context, userID int) ([]int, error)
+}
+
+// Repositories groups every repository of a storage backend
+type Repositories struct {
+	Users             UserRepository
+	ReadingPlans      ReadingPlanRepository
+	UserProgress      UserProgressRepository
+	Catechism         CatechismRepository
+	CatechismProgress CatechismProgressRepository
+	CatechismQuizzes  CatechismQuizRepository
+	CatechismSections CatechismSectionRepository
+	Confession        ConfessionRepository
+	ProgressEvents    ProgressEventRepository
+	Notes             NoteRepository
+	Sync              SyncRepository
+	Idempotency       IdempotencyRepository
+	Households        HouseholdRepository
+}
+
+// New returns the PostgreSQL repositories backed by db
+func New(sqlDB *sql.DB) *Repositories {
+	db := NewDB(sqlDB, "postgresql")
+	return &Repositories{
+		Users:             NewUserRepository(db),
+		ReadingPlans:      NewReadingPlanRepository(db),
+		UserProgress:      NewUserProgressRepository(db),
+		Catechism:         NewCatechismRepository(db),
+		CatechismProgress: NewCatechismProgressRepository(db),
+		CatechismQuizzes:  NewCatechismQuizRepository(db),
+		CatechismSections: NewCatechismSectionRepository(db),
+		Confession:        NewConfessionRepository(db),
+		ProgressEvents:    NewProgressEventRepository(db),
+		Notes:             NewNoteRepository(db),
+		Sync:              NewSyncRepository(db),
+		Idempotency:       NewIdempotencyRepository(db),
+		Households:        NewHouseholdRepository(db),
+	}
+}
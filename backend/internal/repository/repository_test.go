@@ -0,0 +1,46 @@
+package repository_test
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/migrate"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/repository/repositorytest"
+	"biblia-am-pm/migrations"
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+)
+
+// TestConformance runs the suite on PostgreSQL. It needs a disposable database,
+// given as a connection string in TEST_DATABASE_URL, whose tables are emptied
+// before every test.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, config.DriverPostgres, migrations.Files)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		_, err := db.Exec(`TRUNCATE users, reading_plans, westminster_catechism, catechism_sections,
+		                   confession_chapters, catechism_confession_links RESTART IDENTITY CASCADE`)
+		if err != nil {
+			t.Fatalf("empty tables: %v", err)
+		}
+		return repository.New(db)
+	})
+}
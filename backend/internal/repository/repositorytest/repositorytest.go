@@ -0,0 +1,1338 @@
+// Package repositorytest is the conformance suite of the repository
+// interfaces. Every storage backend runs it from its own tests, so they all
+// behave the same way behind the handlers.
+package repositorytest
+
+import (
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+// ctx is the context of every repository call of the suite
+var ctx = context.Background()
+
+// Open returns empty repositories for a single test
+type Open func(t *testing.T) *repository.Repositories
+
+// Run runs every conformance test, each one on repositories returned by open
+func Run(t *testing.T, open Open) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repos *repository.Repositories)
+	}{
+		{"Users", testUsers},
+		{"ReadingPlans", testReadingPlans},
+		{"UserProgress", testUserProgress},
+		{"CatechismRevisions", testCatechismRevisions},
+		{"CatechismBrowse", testCatechismBrowse},
+		{"CatechismImport", testCatechismImport},
+		{"CatechismSearch", testCatechismSearch},
+		{"CatechismBothCatechisms", testCatechismBothCatechisms},
+		{"CatechismProgress", testCatechismProgress},
+		{"CatechismQuizzes", testCatechismQuizzes},
+		{"CatechismSections", testCatechismSections},
+		{"Confession", testConfession},
+		{"ProgressEvents", testProgressEvents},
+		{"Notes", testNotes},
+		{"Sync", testSync},
+		{"Idempotency", testIdempotency},
+		{"Households", testHouseholds},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fn(t, open(t))
+		})
+	}
+}
+
+func day(value string) time.Time {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+func createUser(t *testing.T, repos *repository.Repositories, email string) *models.User {
+	t.Helper()
+	user, err := repos.Users.CreateUser(ctx, email, "hash")
+	if err != nil {
+		t.Fatalf("create user %s: %v", email, err)
+	}
+	return user
+}
+
+func saveQuestions(t *testing.T, repos *repository.Repositories, questions ...*models.CatechismQuestion) {
+	t.Helper()
+	for _, question := range questions {
+		if err := repos.Catechism.Create(ctx, question); err != nil {
+			t.Fatalf("create question %d: %v", question.QuestionNumber, err)
+		}
+	}
+}
+
+// record records the events of a user, failing the test on errors
+func record(t *testing.T, repos *repository.Repositories, userID int, events ...*models.ProgressEvent) {
+	t.Helper()
+	if err := repos.ProgressEvents.Record(ctx, userID, events); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+}
+
+func readingEvent(planID int, date, period, action string, at time.Time) *models.ProgressEvent {
+	return &models.ProgressEvent{
+		Type: models.ProgressTypeReading, Date: day(date), ReadingPlanID: planID, Period: period,
+		Action: action, OccurredAt: at, Source: models.ProgressSourceWeb,
+	}
+}
+
+func catechismEvent(questionID int, date, step, action string, at time.Time) *models.ProgressEvent {
+	return &models.ProgressEvent{
+		Type: models.ProgressTypeCatechism, Date: day(date), QuestionID: questionID, Step: step,
+		Action: action, OccurredAt: at, Source: models.ProgressSourceWeb,
+	}
+}
+
+func applied(events []*models.ProgressEvent) []bool {
+	result := make([]bool, len(events))
+	for i, event := range events {
+		result[i] = event.Applied
+	}
+	return result
+}
+
+func questionNumbers(questions []*models.CatechismQuestion) []int {
+	numbers := make([]int, 0, len(questions))
+	for _, question := range questions {
+		numbers = append(numbers, question.QuestionNumber)
+	}
+	return numbers
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testUsers(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	if user.ID == 0 || user.Role != models.RoleUser || user.CatechismMode != models.CatechismModeWeekly ||
+		user.Catechism != models.CatechismShorter || user.CreatedAt.IsZero() {
+		t.Fatalf("unexpected new user: %+v", user)
+	}
+
+	if _, err := repos.Users.CreateUser(ctx, "ana@example.com", "other"); err == nil {
+		t.Fatal("expected an error for a duplicated email")
+	}
+
+	found, err := repos.Users.GetUserByEmail(ctx, "ana@example.com")
+	if err != nil || found == nil || found.ID != user.ID || found.Password != "hash" {
+		t.Fatalf("GetUserByEmail = %+v, %v", found, err)
+	}
+
+	found, err = repos.Users.GetUserByID(ctx, user.ID)
+	if err != nil || found == nil || found.Email != user.Email || found.Password != "" {
+		t.Fatalf("GetUserByID = %+v, %v", found, err)
+	}
+
+	if found, err := repos.Users.GetUserByEmail(ctx, "nobody@example.com"); err != nil || found != nil {
+		t.Fatalf("GetUserByEmail of a missing user = %+v, %v", found, err)
+	}
+	if found, err := repos.Users.GetUserByID(ctx, user.ID+1000); err != nil || found != nil {
+		t.Fatalf("GetUserByID of a missing user = %+v, %v", found, err)
+	}
+
+	if ok, err := repos.Users.SetRole(ctx, "ana@example.com", models.RoleAdmin); err != nil || !ok {
+		t.Fatalf("SetRole = %v, %v", ok, err)
+	}
+	if ok, err := repos.Users.SetRole(ctx, "nobody@example.com", models.RoleAdmin); err != nil || ok {
+		t.Fatalf("SetRole of a missing user = %v, %v", ok, err)
+	}
+	if err := repos.Users.SetCatechismMode(ctx, user.ID, models.CatechismModeDaily); err != nil {
+		t.Fatalf("SetCatechismMode: %v", err)
+	}
+	if err := repos.Users.SetLocale(ctx, user.ID, "en"); err != nil {
+		t.Fatalf("SetLocale: %v", err)
+	}
+	if err := repos.Users.SetCatechism(ctx, user.ID, models.CatechismLarger); err != nil {
+		t.Fatalf("SetCatechism: %v", err)
+	}
+
+	found, err = repos.Users.GetUserByID(ctx, user.ID)
+	if err != nil || found.Role != models.RoleAdmin || found.CatechismMode != models.CatechismModeDaily ||
+		found.Locale != "en" || found.Catechism != models.CatechismLarger {
+		t.Fatalf("user after updates = %+v, %v", found, err)
+	}
+}
+
+func testReadingPlans(t *testing.T, repos *repository.Repositories) {
+	if plan, err := repos.ReadingPlans.GetByDayOfYear(ctx, 1); err != nil || plan != nil {
+		t.Fatalf("GetByDayOfYear of a missing day = %+v, %v", plan, err)
+	}
+
+	for _, dayOfYear := range []int{2, 1} {
+		plan := &models.ReadingPlan{DayOfYear: dayOfYear, OldTestamentRef: "Gn 1", NewTestamentRef: "Mt 1", PsalmsRef: "Sl 1", ProverbsRef: "Pv 1"}
+		if err := repos.ReadingPlans.Create(ctx, plan); err != nil || plan.ID == 0 {
+			t.Fatalf("Create day %d = %d, %v", dayOfYear, plan.ID, err)
+		}
+	}
+
+	first, err := repos.ReadingPlans.GetByDayOfYear(ctx, 1)
+	if err != nil || first == nil {
+		t.Fatalf("GetByDayOfYear = %+v, %v", first, err)
+	}
+
+	// Creating a day again updates it in place
+	updated := &models.ReadingPlan{DayOfYear: 1, OldTestamentRef: "Gn 1-2", NewTestamentRef: "Mt 1", PsalmsRef: "Sl 1", ProverbsRef: "Pv 1"}
+	if err := repos.ReadingPlans.Create(ctx, updated); err != nil || updated.ID != first.ID {
+		t.Fatalf("Create of an existing day = %d, %v; want ID %d", updated.ID, err, first.ID)
+	}
+
+	plans, err := repos.ReadingPlans.GetAll(ctx)
+	if err != nil || len(plans) != 2 {
+		t.Fatalf("GetAll = %d plans, %v", len(plans), err)
+	}
+	if plans[0].DayOfYear != 1 || plans[0].OldTestamentRef != "Gn 1-2" || plans[1].DayOfYear != 2 {
+		t.Fatalf("GetAll = %+v, %+v", plans[0], plans[1])
+	}
+	if count, err := repos.ReadingPlans.GetTotalCount(ctx); err != nil || count != 2 {
+		t.Fatalf("GetTotalCount = %d, %v", count, err)
+	}
+}
+
+func testUserProgress(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	plan := &models.ReadingPlan{DayOfYear: 1}
+	if err := repos.ReadingPlans.Create(ctx, plan); err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+
+	if progress, err := repos.UserProgress.GetByUserAndDate(ctx, user.ID, day("2025-01-01")); err != nil || progress != nil {
+		t.Fatalf("GetByUserAndDate without progress = %+v, %v", progress, err)
+	}
+
+	now := time.Now()
+	record(t, repos, user.ID, readingEvent(plan.ID, "2025-01-01", models.PeriodMorning, models.ProgressActionMark, now))
+
+	found, err := repos.UserProgress.GetByUserAndDate(ctx, user.ID, day("2025-01-01"))
+	if err != nil || found == nil || !found.MorningCompleted || found.EveningCompleted || found.CompletedAt != nil {
+		t.Fatalf("GetByUserAndDate after morning = %+v, %v", found, err)
+	}
+	if found.Date.Format("2006-01-02") != "2025-01-01" {
+		t.Fatalf("date = %v", found.Date)
+	}
+	firstID := found.ID
+
+	// Completing both periods updates the same row and sets completed_at
+	record(t, repos, user.ID, readingEvent(plan.ID, "2025-01-01", models.PeriodEvening, models.ProgressActionMark, now.Add(time.Hour)))
+	found, err = repos.UserProgress.GetByUserAndDate(ctx, user.ID, day("2025-01-01"))
+	if err != nil || found.ID != firstID || !found.EveningCompleted || found.CompletedAt == nil {
+		t.Fatalf("GetByUserAndDate after evening = %+v, %v; want ID %d", found, err, firstID)
+	}
+
+	// An unmarked period keeps its row, so sync clients see the change
+	record(t, repos, user.ID,
+		readingEvent(plan.ID, "2025-01-02", models.PeriodMorning, models.ProgressActionMark, now),
+		readingEvent(plan.ID, "2025-01-02", models.PeriodMorning, models.ProgressActionUnmark, now.Add(time.Minute)),
+	)
+	all, err := repos.UserProgress.GetUserProgress(ctx, user.ID)
+	if err != nil || len(all) != 2 || all[0].Date.Format("2006-01-02") != "2025-01-02" {
+		t.Fatalf("GetUserProgress = %+v, %v; want newest first", all, err)
+	}
+
+	if morning, evening, err := repos.UserProgress.CountCompletedOnDate(ctx, day("2025-01-01")); err != nil || morning != 1 || evening != 1 {
+		t.Fatalf("CountCompletedOnDate = %d, %d, %v; want 1, 1", morning, evening, err)
+	}
+	if morning, evening, err := repos.UserProgress.CountCompletedOnDate(ctx, day("2025-01-02")); err != nil || morning != 0 || evening != 0 {
+		t.Fatalf("CountCompletedOnDate without completions = %d, %d, %v", morning, evening, err)
+	}
+}
+
+func testCatechismRevisions(t *testing.T, repos *repository.Repositories) {
+	author := createUser(t, repos, "admin@example.com")
+
+	if number, err := repos.Catechism.GetMaxQuestionNumber(ctx, models.CatechismShorter); err != nil || number != 0 {
+		t.Fatalf("GetMaxQuestionNumber of an empty catechism = %d, %v", number, err)
+	}
+
+	question := &models.CatechismQuestion{QuestionNumber: 1, QuestionText: "Qual é o fim principal do homem?", AnswerText: "Glorificar a Deus."}
+	saveQuestions(t, repos, question)
+	if question.ID == 0 || question.Revision != 1 || question.Catechism != models.CatechismShorter {
+		t.Fatalf("new question = %+v", question)
+	}
+	id := question.ID
+
+	// Saving the same text doesn't create a revision
+	same := &models.CatechismQuestion{QuestionNumber: 1, QuestionText: question.QuestionText, AnswerText: question.AnswerText}
+	if err := repos.Catechism.Save(ctx, same, &author.ID); err != nil || same.Revision != 1 {
+		t.Fatalf("Save without changes = revision %d, %v", same.Revision, err)
+	}
+
+	edited := &models.CatechismQuestion{QuestionNumber: 1, QuestionText: question.QuestionText, AnswerText: "Glorificar a Deus e gozá-lo para sempre."}
+	if err := repos.Catechism.Save(ctx, edited, &author.ID); err != nil || edited.ID != id || edited.Revision != 2 {
+		t.Fatalf("Save with changes = %+v, %v; want ID %d revision 2", edited, err, id)
+	}
+
+	found, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 1)
+	if err != nil || found == nil || found.AnswerText != edited.AnswerText || found.Revision != 2 {
+		t.Fatalf("GetByQuestionNumber = %+v, %v", found, err)
+	}
+	if found, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 2); err != nil || found != nil {
+		t.Fatalf("GetByQuestionNumber of a missing question = %+v, %v", found, err)
+	}
+	if found, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismLarger, 1); err != nil || found != nil {
+		t.Fatalf("GetByQuestionNumber in another catechism = %+v, %v", found, err)
+	}
+
+	revisions, err := repos.Catechism.GetRevisions(ctx, id)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("GetRevisions = %d revisions, %v", len(revisions), err)
+	}
+	if revisions[0].Revision != 2 || revisions[0].AuthorID == nil || *revisions[0].AuthorID != author.ID ||
+		revisions[0].AuthorEmail != author.Email || revisions[0].CreatedAt.IsZero() {
+		t.Fatalf("newest revision = %+v", revisions[0])
+	}
+	if revisions[1].Revision != 1 || revisions[1].AuthorID != nil || revisions[1].AuthorEmail != "" || revisions[1].AnswerText != "Glorificar a Deus." {
+		t.Fatalf("first revision = %+v", revisions[1])
+	}
+
+	revision, err := repos.Catechism.GetRevision(ctx, id, 1)
+	if err != nil || revision == nil || revision.AnswerText != "Glorificar a Deus." {
+		t.Fatalf("GetRevision = %+v, %v", revision, err)
+	}
+	if revision, err := repos.Catechism.GetRevision(ctx, id, 3); err != nil || revision != nil {
+		t.Fatalf("GetRevision of a missing revision = %+v, %v", revision, err)
+	}
+}
+
+func testCatechismBrowse(t *testing.T, repos *repository.Repositories) {
+	// Numbering has a gap at 3
+	var questions []*models.CatechismQuestion
+	for _, number := range []int{1, 2, 4, 5, 6} {
+		questions = append(questions, &models.CatechismQuestion{QuestionNumber: number, QuestionText: "Pergunta", AnswerText: "Resposta"})
+	}
+	// The Larger Catechism is numbered apart
+	questions = append(questions,
+		&models.CatechismQuestion{QuestionNumber: 3, QuestionText: "Pergunta", AnswerText: "Resposta", Catechism: models.CatechismLarger},
+		&models.CatechismQuestion{QuestionNumber: 9, QuestionText: "Pergunta", AnswerText: "Resposta", Catechism: models.CatechismLarger},
+	)
+	if err := repos.Catechism.CreateBatch(ctx, questions); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	if count, err := repos.Catechism.GetTotalCount(ctx); err != nil || count != 7 {
+		t.Fatalf("GetTotalCount = %d, %v", count, err)
+	}
+	if number, err := repos.Catechism.GetMaxQuestionNumber(ctx, models.CatechismShorter); err != nil || number != 6 {
+		t.Fatalf("GetMaxQuestionNumber = %d, %v", number, err)
+	}
+	if number, err := repos.Catechism.GetMaxQuestionNumber(ctx, models.CatechismLarger); err != nil || number != 9 {
+		t.Fatalf("GetMaxQuestionNumber of the larger catechism = %d, %v", number, err)
+	}
+
+	all, err := repos.Catechism.GetAll(ctx, models.CatechismShorter)
+	if err != nil || !equalInts(questionNumbers(all), []int{1, 2, 4, 5, 6}) {
+		t.Fatalf("GetAll = %v, %v", questionNumbers(all), err)
+	}
+
+	inRange, err := repos.Catechism.GetByQuestionRange(ctx, models.CatechismShorter, 2, 5)
+	if err != nil || !equalInts(questionNumbers(inRange), []int{2, 4, 5}) {
+		t.Fatalf("GetByQuestionRange = %v, %v", questionNumbers(inRange), err)
+	}
+
+	page, total, err := repos.Catechism.GetPage(ctx, models.CatechismShorter, 1, 6, 2, 2)
+	if err != nil || total != 5 || !equalInts(questionNumbers(page), []int{4, 5}) {
+		t.Fatalf("GetPage = %v, %d, %v", questionNumbers(page), total, err)
+	}
+	page, total, err = repos.Catechism.GetPage(ctx, models.CatechismShorter, 1, 6, 2, 10)
+	if err != nil || total != 5 || page == nil || len(page) != 0 {
+		t.Fatalf("GetPage past the end = %v, %d, %v; want an empty page", page, total, err)
+	}
+
+	for _, test := range []struct{ number, previous, next int }{
+		{1, 0, 2},
+		{2, 1, 4},
+		{4, 2, 5},
+		{6, 5, 0},
+	} {
+		previous, next, err := repos.Catechism.GetAdjacentNumbers(ctx, models.CatechismShorter, test.number)
+		if err != nil || previous != test.previous || next != test.next {
+			t.Errorf("GetAdjacentNumbers(%d) = %d, %d, %v; want %d, %d", test.number, previous, next, err, test.previous, test.next)
+		}
+	}
+}
+
+func testCatechismImport(t *testing.T, repos *repository.Repositories) {
+	author := createUser(t, repos, "admin@example.com")
+	user := createUser(t, repos, "ana@example.com")
+	saveQuestions(t, repos,
+		&models.CatechismQuestion{QuestionNumber: 1, QuestionText: "P1", AnswerText: "R1"},
+		&models.CatechismQuestion{QuestionNumber: 2, QuestionText: "P2", AnswerText: "R2"},
+		&models.CatechismQuestion{QuestionNumber: 2, QuestionText: "P2 maior", AnswerText: "R2 maior", Catechism: models.CatechismLarger},
+	)
+	removed, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 2)
+	if err != nil {
+		t.Fatalf("get question: %v", err)
+	}
+	record(t, repos, user.ID, catechismEvent(removed.ID, "2025-01-01", models.CatechismStepReview, models.ProgressActionMark, time.Now()))
+
+	// The questions take the catechism of the import
+	upserts := []*models.CatechismQuestion{
+		{QuestionNumber: 1, QuestionText: "P1", AnswerText: "R1 editada", Catechism: models.CatechismLarger},
+		{QuestionNumber: 3, QuestionText: "P3", AnswerText: "R3"},
+	}
+	if err := repos.Catechism.ApplyImport(ctx, models.CatechismShorter, upserts, []int{2}, &author.ID); err != nil {
+		t.Fatalf("ApplyImport: %v", err)
+	}
+
+	all, err := repos.Catechism.GetAll(ctx, models.CatechismShorter)
+	if err != nil || !equalInts(questionNumbers(all), []int{1, 3}) {
+		t.Fatalf("questions after import = %v, %v", questionNumbers(all), err)
+	}
+	if all[0].Revision != 2 || all[0].Catechism != models.CatechismShorter || all[1].Revision != 1 || all[1].Catechism != models.CatechismShorter {
+		t.Fatalf("questions after import = %+v, %+v", all[0], all[1])
+	}
+
+	// The other catechism is untouched
+	larger, err := repos.Catechism.GetAll(ctx, models.CatechismLarger)
+	if err != nil || len(larger) != 1 || larger[0].QuestionNumber != 2 || larger[0].AnswerText != "R2 maior" {
+		t.Fatalf("larger catechism after import = %v, %v", questionNumbers(larger), err)
+	}
+
+	// A removed question is retired: it leaves the catechism but keeps its
+	// progress and revisions
+	if question, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 2); err != nil || question != nil {
+		t.Fatalf("removed question = %+v, %v", question, err)
+	}
+	if max, err := repos.Catechism.GetMaxQuestionNumber(ctx, models.CatechismShorter); err != nil || max != 3 {
+		t.Fatalf("GetMaxQuestionNumber after removing a question = %d, %v", max, err)
+	}
+	if previous, next, err := repos.Catechism.GetAdjacentNumbers(ctx, models.CatechismShorter, 1); err != nil || previous != 0 || next != 3 {
+		t.Fatalf("GetAdjacentNumbers(1) after removing a question = %d, %d, %v", previous, next, err)
+	}
+	if results, err := repos.Catechism.Search(ctx, "P2", models.CatechismShorter, 10); err != nil || len(results) != 0 {
+		t.Fatalf("Search(P2) after removing the question = %d results, %v", len(results), err)
+	}
+	if count, err := repos.Catechism.GetTotalCount(ctx); err != nil || count != 3 {
+		t.Fatalf("GetTotalCount after removing a question = %d, %v", count, err)
+	}
+	statuses, err := repos.CatechismProgress.GetQuestionStatuses(ctx, user.ID)
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("statuses after removing the question = %v, %v", statuses, err)
+	}
+	if revisions, err := repos.Catechism.GetRevisions(ctx, removed.ID); err != nil || len(revisions) != 1 {
+		t.Fatalf("revisions after removing the question = %d, %v", len(revisions), err)
+	}
+	if events, err := repos.ProgressEvents.GetByUserAndDate(ctx, user.ID, day("2025-01-01")); err != nil || len(events) != 1 {
+		t.Fatalf("progress events after removing the question = %d, %v", len(events), err)
+	}
+
+	// Importing it again restores it, with a new revision when the text changed
+	restored := &models.CatechismQuestion{QuestionNumber: 2, QuestionText: "P2", AnswerText: "R2 nova"}
+	if err := repos.Catechism.ApplyImport(ctx, models.CatechismShorter, []*models.CatechismQuestion{restored}, nil, &author.ID); err != nil {
+		t.Fatalf("ApplyImport: %v", err)
+	}
+	question, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 2)
+	if err != nil || question == nil || question.ID != removed.ID || question.Revision != 2 || question.AnswerText != "R2 nova" {
+		t.Fatalf("restored question = %+v, %v", question, err)
+	}
+
+	// Restoring it with the same text records no revision
+	if err := repos.Catechism.ApplyImport(ctx, models.CatechismShorter, nil, []int{2}, nil); err != nil {
+		t.Fatalf("ApplyImport: %v", err)
+	}
+	restored = &models.CatechismQuestion{QuestionNumber: 2, QuestionText: "P2", AnswerText: "R2 nova"}
+	if err := repos.Catechism.ApplyImport(ctx, models.CatechismShorter, []*models.CatechismQuestion{restored}, nil, nil); err != nil {
+		t.Fatalf("ApplyImport: %v", err)
+	}
+	if question, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 2); err != nil || question == nil || question.Revision != 2 {
+		t.Fatalf("question restored unchanged = %+v, %v", question, err)
+	}
+}
+
+func testCatechismSearch(t *testing.T, repos *repository.Repositories) {
+	saveQuestions(t, repos,
+		&models.CatechismQuestion{QuestionNumber: 1, QuestionText: "Qual é o fim principal do homem?", AnswerText: "O fim principal do homem é glorificar a Deus e gozá-lo para sempre."},
+		&models.CatechismQuestion{QuestionNumber: 2, QuestionText: "Que regra Deus nos deu?", AnswerText: "A palavra de Deus é a única regra."},
+		&models.CatechismQuestion{QuestionNumber: 3, QuestionText: "O que é a oração?", AnswerText: "Oferecer nossos desejos a Deus.", Catechism: models.CatechismLarger},
+	)
+
+	results, err := repos.Catechism.Search(ctx, "regra", "", 10)
+	if err != nil || len(results) != 1 || results[0].Question.QuestionNumber != 2 {
+		t.Fatalf("Search(regra) = %v, %v", results, err)
+	}
+	if results[0].Question.AnswerText != "A palavra de Deus é a única regra." || results[0].QuestionSnippet == "" || results[0].AnswerSnippet == "" {
+		t.Fatalf("Search(regra) result = %+v", results[0])
+	}
+
+	results, err = repos.Catechism.Search(ctx, "Deus", models.CatechismShorter, 10)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Search(Deus) in the shorter catechism = %d results, %v", len(results), err)
+	}
+	for _, result := range results {
+		if result.Question.Catechism != models.CatechismShorter {
+			t.Fatalf("Search(Deus) returned %+v", result.Question)
+		}
+	}
+
+	results, err = repos.Catechism.Search(ctx, "Deus", models.CatechismLarger, 10)
+	if err != nil || len(results) != 1 || results[0].Question.QuestionNumber != 3 || results[0].Question.Catechism != models.CatechismLarger {
+		t.Fatalf("Search(Deus) in the larger catechism = %v, %v", results, err)
+	}
+
+	if results, err := repos.Catechism.Search(ctx, "deus", "", 2); err != nil || len(results) != 2 {
+		t.Fatalf("Search(deus) with limit 2 = %d results, %v", len(results), err)
+	}
+
+	results, err = repos.Catechism.Search(ctx, "batismo", "", 10)
+	if err != nil || results == nil || len(results) != 0 {
+		t.Fatalf("Search without matches = %v, %v; want an empty list", results, err)
+	}
+}
+
+// Both catechisms are stored side by side, each with its own question 1
+func testCatechismBothCatechisms(t *testing.T, repos *repository.Repositories) {
+	saveQuestions(t, repos,
+		&models.CatechismQuestion{QuestionNumber: 1, QuestionText: "Qual é o fim principal do homem?", AnswerText: "Glorificar a Deus e gozá-lo para sempre."},
+		&models.CatechismQuestion{QuestionNumber: 1, QuestionText: "Qual é o fim supremo e principal do homem?", AnswerText: "Glorificar a Deus e gozá-lo plenamente para sempre.", Catechism: models.CatechismLarger},
+		&models.CatechismQuestion{QuestionNumber: 2, QuestionText: "Como se sabe que há um Deus?", AnswerText: "A própria luz da natureza no homem o declara.", Catechism: models.CatechismLarger},
+	)
+
+	shorter, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 1)
+	if err != nil || shorter == nil || shorter.QuestionText != "Qual é o fim principal do homem?" {
+		t.Fatalf("question 1 of the shorter catechism = %+v, %v", shorter, err)
+	}
+	larger, err := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismLarger, 1)
+	if err != nil || larger == nil || larger.ID == shorter.ID || larger.QuestionText != "Qual é o fim supremo e principal do homem?" {
+		t.Fatalf("question 1 of the larger catechism = %+v, %v", larger, err)
+	}
+
+	for _, test := range []struct {
+		catechism string
+		want      []int
+	}{
+		{models.CatechismShorter, []int{shorter.ID}},
+		{models.CatechismLarger, []int{larger.ID}},
+		{"", []int{shorter.ID, larger.ID}},
+	} {
+		results, err := repos.Catechism.Search(ctx, "glorificar", test.catechism, 10)
+		if err != nil {
+			t.Fatalf("Search(glorificar, %q): %v", test.catechism, err)
+		}
+		var ids []int
+		for _, result := range results {
+			if test.catechism != "" && result.Question.Catechism != test.catechism {
+				t.Errorf("Search(glorificar, %q) returned %+v", test.catechism, result.Question)
+			}
+			ids = append(ids, result.Question.ID)
+		}
+		sort.Ints(ids)
+		if !equalInts(ids, test.want) {
+			t.Errorf("Search(glorificar, %q) = questions %v, want %v", test.catechism, ids, test.want)
+		}
+	}
+}
+
+func testCatechismProgress(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	other := createUser(t, repos, "bia@example.com")
+	saveQuestions(t, repos,
+		&models.CatechismQuestion{QuestionNumber: 1, QuestionText: "P1", AnswerText: "R1"},
+		&models.CatechismQuestion{QuestionNumber: 2, QuestionText: "P2", AnswerText: "R2"},
+	)
+	first, _ := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 1)
+	second, _ := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 2)
+
+	found, err := repos.CatechismProgress.GetByUserAndDate(ctx, user.ID, first.ID, day("2025-01-05"), models.CatechismStepRead)
+	if err != nil || found != nil {
+		t.Fatalf("GetByUserAndDate without progress = %+v, %v", found, err)
+	}
+
+	// Two steps on the same day are separate rows
+	now := time.Now()
+	record(t, repos, user.ID,
+		catechismEvent(first.ID, "2025-01-05", models.CatechismStepRead, models.ProgressActionMark, now),
+		catechismEvent(first.ID, "2025-01-05", models.CatechismStepReciteHalf, models.ProgressActionMark, now),
+		catechismEvent(first.ID, "2025-01-08", models.CatechismStepReciteHalf, models.ProgressActionMark, now),
+		catechismEvent(first.ID, "2025-01-12", models.CatechismStepRead, models.ProgressActionMark, now),
+		catechismEvent(second.ID, "2025-01-06", models.CatechismStepRead, models.ProgressActionMark, now),
+		catechismEvent(second.ID, "2025-01-06", models.CatechismStepRead, models.ProgressActionUnmark, now.Add(time.Minute)),
+	)
+	record(t, repos, other.ID, catechismEvent(first.ID, "2025-01-05", models.CatechismStepRead, models.ProgressActionMark, now))
+
+	found, err = repos.CatechismProgress.GetByUserAndDate(ctx, user.ID, first.ID, day("2025-01-05"), models.CatechismStepReciteHalf)
+	if err != nil || found == nil || !found.Completed || found.CompletedAt == nil || found.Step != models.CatechismStepReciteHalf {
+		t.Fatalf("GetByUserAndDate = %+v, %v", found, err)
+	}
+
+	// Marking the same step again keeps the row and its earlier time
+	again := catechismEvent(first.ID, "2025-01-05", models.CatechismStepReciteHalf, models.ProgressActionMark, now.Add(time.Hour))
+	record(t, repos, user.ID, again)
+	if kept, err := repos.CatechismProgress.GetByUserAndDate(ctx, user.ID, first.ID, day("2025-01-05"), models.CatechismStepReciteHalf); err != nil || again.Applied || kept.ID != found.ID || !kept.CompletedAt.Equal(*found.CompletedAt) {
+		t.Fatalf("progress marked again = %+v, %v; want %+v", kept, err, found)
+	}
+
+	// Counts every user's completed steps, ignoring unfinished ones
+	if count, err := repos.CatechismProgress.CountCompletedOnDate(ctx, day("2025-01-05")); err != nil || count != 3 {
+		t.Fatalf("CountCompletedOnDate = %d, %v; want 3", count, err)
+	}
+	if count, err := repos.CatechismProgress.CountCompletedOnDate(ctx, day("2025-01-06")); err != nil || count != 0 {
+		t.Fatalf("CountCompletedOnDate of unfinished steps = %d, %v; want 0", count, err)
+	}
+
+	week, err := repos.CatechismProgress.GetByUserAndQuestionForWeek(ctx, user.ID, first.ID, day("2025-01-05"))
+	if err != nil || len(week) != 3 {
+		t.Fatalf("GetByUserAndQuestionForWeek = %d rows, %v; want 3", len(week), err)
+	}
+	if week[0].Date.Format("2006-01-02") != "2025-01-05" || week[2].Date.Format("2006-01-02") != "2025-01-08" {
+		t.Fatalf("week rows are not ordered by date: %v, %v", week[0].Date, week[2].Date)
+	}
+
+	all, err := repos.CatechismProgress.GetUserProgress(ctx, user.ID)
+	if err != nil || len(all) != 5 || all[0].Date.Format("2006-01-02") != "2025-01-12" {
+		t.Fatalf("GetUserProgress = %d rows, %v; want 5, newest first", len(all), err)
+	}
+
+	statuses, err := repos.CatechismProgress.GetQuestionStatuses(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetQuestionStatuses: %v", err)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("GetQuestionStatuses = %v; only completed questions count", statuses)
+	}
+	status := statuses[first.ID]
+	if status == nil || status.TimesCompleted != 3 || status.LastCompletedAt == nil {
+		t.Fatalf("status of question 1 = %+v; want 3 distinct days", status)
+	}
+	// TIMESTAMP columns drop the time zone, so only the day is checked
+	if since := time.Since(*status.LastCompletedAt); since < -24*time.Hour || since > 24*time.Hour {
+		t.Fatalf("LastCompletedAt = %v, want today", status.LastCompletedAt)
+	}
+}
+
+func testCatechismQuizzes(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	other := createUser(t, repos, "bia@example.com")
+
+	quiz := &models.CatechismQuiz{
+		UserID:     user.ID,
+		RangeStart: 1,
+		RangeEnd:   10,
+		Exercises: []models.QuizExercise{
+			{Type: models.QuizExerciseMultipleChoice, QuestionNumber: 1, Prompt: "Qual é o fim principal do homem?", Options: []string{"a", "b"}, CorrectOption: 1, Answer: "b"},
+		},
+	}
+	if err := repos.CatechismQuizzes.Create(ctx, quiz); err != nil || quiz.ID == 0 {
+		t.Fatalf("Create = %d, %v", quiz.ID, err)
+	}
+
+	found, err := repos.CatechismQuizzes.GetByIDAndUser(ctx, quiz.ID, user.ID)
+	if err != nil || found == nil || len(found.Exercises) != 1 || found.Exercises[0].Options[1] != "b" || found.SubmittedAt != nil || found.CreatedAt.IsZero() {
+		t.Fatalf("GetByIDAndUser = %+v, %v", found, err)
+	}
+	if found, err := repos.CatechismQuizzes.GetByIDAndUser(ctx, quiz.ID, other.ID); err != nil || found != nil {
+		t.Fatalf("GetByIDAndUser of another user = %+v, %v", found, err)
+	}
+
+	option := 1
+	found.Answers = []models.QuizAnswer{{Exercise: 0, Option: &option}}
+	found.Results = []models.QuizExerciseResult{{Exercise: 0, Correct: true, Score: 1, CorrectAnswer: "b"}}
+	found.Score = 1
+	found.CorrectCount = 1
+	if ok, err := repos.CatechismQuizzes.SaveResult(ctx, found); err != nil || !ok || found.SubmittedAt == nil {
+		t.Fatalf("SaveResult = %v, %v", ok, err)
+	}
+	if ok, err := repos.CatechismQuizzes.SaveResult(ctx, found); err != nil || ok {
+		t.Fatalf("second SaveResult = %v, %v; want false", ok, err)
+	}
+
+	submitted, err := repos.CatechismQuizzes.GetSubmittedByUser(ctx, user.ID)
+	if err != nil || len(submitted) != 1 {
+		t.Fatalf("GetSubmittedByUser = %d quizzes, %v", len(submitted), err)
+	}
+	if submitted[0].Score != 1 || submitted[0].CorrectCount != 1 || len(submitted[0].Answers) != 1 ||
+		*submitted[0].Answers[0].Option != 1 || len(submitted[0].Results) != 1 || !submitted[0].Results[0].Correct {
+		t.Fatalf("submitted quiz = %+v", submitted[0])
+	}
+
+	if submitted, err := repos.CatechismQuizzes.GetSubmittedByUser(ctx, other.ID); err != nil || len(submitted) != 0 {
+		t.Fatalf("GetSubmittedByUser of another user = %d quizzes, %v", len(submitted), err)
+	}
+}
+
+func testCatechismSections(t *testing.T, repos *repository.Repositories) {
+	sections, err := repos.CatechismSections.GetByCatechism(ctx, models.CatechismShorter)
+	if err != nil || sections == nil || len(sections) != 0 {
+		t.Fatalf("GetByCatechism without sections = %v, %v; want an empty list", sections, err)
+	}
+
+	err = repos.CatechismSections.ReplaceForCatechism(ctx, models.CatechismShorter, []*models.CatechismSection{
+		{Slug: "deus", Title: "Deus", StartQuestion: 1, EndQuestion: 10},
+		{Slug: "lei", Title: "A Lei", StartQuestion: 11, EndQuestion: 20},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceForCatechism: %v", err)
+	}
+	err = repos.CatechismSections.ReplaceForCatechism(ctx, models.CatechismLarger, []*models.CatechismSection{
+		{Slug: "deus", Title: "Deus", StartQuestion: 1, EndQuestion: 5},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceForCatechism: %v", err)
+	}
+
+	// Replacing drops the sections that are no longer listed
+	err = repos.CatechismSections.ReplaceForCatechism(ctx, models.CatechismShorter, []*models.CatechismSection{
+		{Slug: "oracao", Title: "Oração", StartQuestion: 98, EndQuestion: 107},
+		{Slug: "deus", Title: "Deus", StartQuestion: 1, EndQuestion: 12},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceForCatechism: %v", err)
+	}
+
+	sections, err = repos.CatechismSections.GetByCatechism(ctx, models.CatechismShorter)
+	if err != nil || len(sections) != 2 {
+		t.Fatalf("GetByCatechism = %d sections, %v", len(sections), err)
+	}
+	if sections[0].Slug != "oracao" || sections[0].Position != 1 || sections[1].Slug != "deus" || sections[1].Position != 2 ||
+		sections[1].EndQuestion != 12 || sections[1].Catechism != models.CatechismShorter || sections[0].ID == 0 {
+		t.Fatalf("sections = %+v, %+v", sections[0], sections[1])
+	}
+
+	if sections, err := repos.CatechismSections.GetByCatechism(ctx, models.CatechismLarger); err != nil || len(sections) != 1 {
+		t.Fatalf("GetByCatechism of the larger catechism = %d sections, %v", len(sections), err)
+	}
+}
+
+func testConfession(t *testing.T, repos *repository.Repositories) {
+	chapters, err := repos.Confession.GetChapters(ctx)
+	if err != nil || chapters == nil || len(chapters) != 0 {
+		t.Fatalf("GetChapters without chapters = %v, %v; want an empty list", chapters, err)
+	}
+
+	err = repos.Confession.ReplaceAll(ctx, []*models.ConfessionChapter{
+		{ChapterNumber: 2, Title: "De Deus e da Santíssima Trindade", Sections: []*models.ConfessionSection{
+			{SectionNumber: 1, Text: "Há um só Deus vivo e verdadeiro.", Proofs: []*models.ConfessionProof{
+				{Letter: "a", References: "Dt 6.4"},
+				{Letter: "b", References: "Jr 10.10"},
+			}},
+		}},
+		{ChapterNumber: 1, Title: "Da Escritura Sagrada", Sections: []*models.ConfessionSection{
+			{SectionNumber: 2, Text: "Sob o nome de Escritura Sagrada..."},
+			{SectionNumber: 1, Text: "Ainda que a luz da natureza...", Proofs: []*models.ConfessionProof{
+				{Letter: "a", References: "Rm 2.14-15"},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceAll: %v", err)
+	}
+
+	chapters, err = repos.Confession.GetChapters(ctx)
+	if err != nil || len(chapters) != 2 || chapters[0].ChapterNumber != 1 || chapters[1].Title != "De Deus e da Santíssima Trindade" {
+		t.Fatalf("GetChapters = %v, %v", chapters, err)
+	}
+	if chapters[0].Sections != nil {
+		t.Fatalf("GetChapters loaded the sections of chapter 1")
+	}
+
+	chapter, err := repos.Confession.GetChapter(ctx, 1)
+	if err != nil || chapter == nil || len(chapter.Sections) != 2 {
+		t.Fatalf("GetChapter(1) = %+v, %v", chapter, err)
+	}
+	if chapter.Sections[0].SectionNumber != 1 || len(chapter.Sections[0].Proofs) != 1 || chapter.Sections[0].Proofs[0].References != "Rm 2.14-15" {
+		t.Fatalf("section 1.1 = %+v", chapter.Sections[0])
+	}
+	if chapter.Sections[1].Proofs == nil || len(chapter.Sections[1].Proofs) != 0 {
+		t.Fatalf("section 1.2 proofs = %v; want an empty list", chapter.Sections[1].Proofs)
+	}
+
+	chapter, err = repos.Confession.GetChapter(ctx, 2)
+	if err != nil || len(chapter.Sections) != 1 || len(chapter.Sections[0].Proofs) != 2 || chapter.Sections[0].Proofs[1].Letter != "b" {
+		t.Fatalf("GetChapter(2) = %+v, %v", chapter, err)
+	}
+	if chapter, err := repos.Confession.GetChapter(ctx, 33); err != nil || chapter != nil {
+		t.Fatalf("GetChapter of a missing chapter = %+v, %v", chapter, err)
+	}
+
+	err = repos.Confession.ReplaceLinks(ctx, models.CatechismShorter, []*models.CatechismConfessionLink{
+		{QuestionNumber: 4, ChapterNumber: 2, SectionNumber: 1},
+		{QuestionNumber: 2, ChapterNumber: 1, SectionNumber: 2},
+		{QuestionNumber: 3, ChapterNumber: 1, SectionNumber: 2},
+		{QuestionNumber: 3, ChapterNumber: 1, SectionNumber: 2},
+		{QuestionNumber: 2, ChapterNumber: 25, SectionNumber: 1},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceLinks: %v", err)
+	}
+	err = repos.Confession.ReplaceLinks(ctx, models.CatechismLarger, []*models.CatechismConfessionLink{
+		{QuestionNumber: 7, ChapterNumber: 2, SectionNumber: 1},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceLinks: %v", err)
+	}
+
+	linked, err := repos.Confession.GetLinkedQuestions(ctx, 1, models.CatechismShorter)
+	if err != nil || len(linked) != 1 || !equalInts(linked[2], []int{2, 3}) {
+		t.Fatalf("GetLinkedQuestions = %v, %v", linked, err)
+	}
+
+	// The link to chapter 25, which wasn't imported, is skipped
+	references, err := repos.Confession.GetReferencesForQuestion(ctx, models.CatechismShorter, 2)
+	if err != nil || len(references) != 1 {
+		t.Fatalf("GetReferencesForQuestion = %v, %v", references, err)
+	}
+	if references[0].ChapterNumber != 1 || references[0].ChapterTitle != "Da Escritura Sagrada" || references[0].SectionNumber != 2 {
+		t.Fatalf("reference = %+v", references[0])
+	}
+	if references, err := repos.Confession.GetReferencesForQuestion(ctx, models.CatechismLarger, 2); err != nil || len(references) != 0 {
+		t.Fatalf("GetReferencesForQuestion of the larger catechism = %v, %v", references, err)
+	}
+}
+
+func testProgressEvents(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	plan := &models.ReadingPlan{DayOfYear: 2}
+	if err := repos.ReadingPlans.Create(ctx, plan); err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	saveQuestions(t, repos, &models.CatechismQuestion{QuestionNumber: 1, QuestionText: "P1", AnswerText: "R1"})
+	question, _ := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 1)
+
+	at := func(value string) time.Time {
+		occurredAt, err := time.Parse(time.RFC3339, "2025-01-02T"+value+":00Z")
+		if err != nil {
+			panic(err)
+		}
+		return occurredAt
+	}
+	morning := func(action, occurredAt string) *models.ProgressEvent {
+		return readingEvent(plan.ID, "2025-01-02", models.PeriodMorning, action, at(occurredAt))
+	}
+	morningAt := func() *time.Time {
+		t.Helper()
+		progress, err := repos.UserProgress.GetByUserAndDate(ctx, user.ID, day("2025-01-02"))
+		if err != nil || progress == nil {
+			t.Fatalf("GetByUserAndDate = %+v, %v", progress, err)
+		}
+		if progress.MorningCompleted != (progress.MorningCompletedAt != nil) {
+			t.Fatalf("morning completed %v at %v", progress.MorningCompleted, progress.MorningCompletedAt)
+		}
+		return progress.MorningCompletedAt
+	}
+
+	// Unmarking with nothing completed is logged but changes nothing
+	unmark := morning(models.ProgressActionUnmark, "06:00")
+	record(t, repos, user.ID, unmark)
+	if unmark.Applied || unmark.ID == 0 {
+		t.Fatalf("unmark without progress = %+v", unmark)
+	}
+	if progress, err := repos.UserProgress.GetByUserAndDate(ctx, user.ID, day("2025-01-02")); err != nil || progress != nil {
+		t.Fatalf("GetByUserAndDate after an unmark = %+v, %v", progress, err)
+	}
+
+	// The period is completed at its earliest mark after its last unmark
+	record(t, repos, user.ID, morning(models.ProgressActionMark, "07:00"), morning(models.ProgressActionMark, "09:00"))
+	if got := morningAt(); got == nil || !got.Equal(at("07:00")) {
+		t.Fatalf("morning completed at %v, want 07:00", got)
+	}
+	batch := []*models.ProgressEvent{morning(models.ProgressActionUnmark, "08:00"), morning(models.ProgressActionMark, "07:30")}
+	record(t, repos, user.ID, batch...)
+	if got := applied(batch); !equalBools(got, []bool{true, false}) {
+		t.Fatalf("Applied = %v", got)
+	}
+	if got := morningAt(); got == nil || !got.Equal(at("09:00")) {
+		t.Fatalf("morning completed at %v, want 09:00 after the unmark at 08:00", got)
+	}
+	undo := morning(models.ProgressActionUnmark, "09:00")
+	record(t, repos, user.ID, undo)
+	if got := morningAt(); !undo.Applied || got != nil {
+		t.Fatalf("morning completed at %v after unmarking its mark", got)
+	}
+
+	// Events sent again are skipped
+	again := morning(models.ProgressActionMark, "07:00")
+	record(t, repos, user.ID, again)
+	if again.Applied || again.ID != 0 || morningAt() != nil {
+		t.Fatalf("event sent again = %+v", again)
+	}
+
+	step := catechismEvent(question.ID, "2025-01-02", models.CatechismStepReview, models.ProgressActionMark, at("07:10"))
+	step.Source = models.ProgressSourceSync
+	record(t, repos, user.ID, step, catechismEvent(question.ID, "2025-01-02", models.CatechismStepReview, models.ProgressActionUnmark, at("07:20")))
+	if progress, err := repos.CatechismProgress.GetByUserAndDate(ctx, user.ID, question.ID, day("2025-01-02"), models.CatechismStepReview); err != nil || progress == nil || progress.Completed || progress.CompletedAt != nil {
+		t.Fatalf("unmarked step = %+v, %v", progress, err)
+	}
+
+	events, err := repos.ProgressEvents.GetByUserAndDate(ctx, user.ID, day("2025-01-02"))
+	if err != nil || len(events) != 8 {
+		t.Fatalf("GetByUserAndDate = %d events, %v; want 8", len(events), err)
+	}
+	first, last := events[0], events[7]
+	if first.ID != unmark.ID || first.Action != models.ProgressActionUnmark || first.Period != models.PeriodMorning || first.ReadingPlanID != plan.ID ||
+		first.QuestionID != 0 || first.Step != "" || !first.OccurredAt.Equal(at("06:00")) || first.Source != models.ProgressSourceWeb || first.CreatedAt.IsZero() {
+		t.Errorf("first event = %+v", first)
+	}
+	if last.Type != models.ProgressTypeCatechism || last.QuestionID != question.ID || last.Step != models.CatechismStepReview || last.Period != "" || last.Action != models.ProgressActionUnmark {
+		t.Errorf("last event = %+v", last)
+	}
+	if events[6].Source != models.ProgressSourceSync {
+		t.Errorf("source = %q, want %q", events[6].Source, models.ProgressSourceSync)
+	}
+
+	if events, err := repos.ProgressEvents.GetByUserAndDate(ctx, user.ID, day("2025-01-03")); err != nil || len(events) != 0 {
+		t.Errorf("GetByUserAndDate of another day = %d events, %v", len(events), err)
+	}
+
+	// GetByUser returns the events of every day, and only the user's
+	other := createUser(t, repos, "bia@example.com")
+	record(t, repos, other.ID, readingEvent(plan.ID, "2025-01-02", models.PeriodMorning, models.ProgressActionMark, at("07:00")))
+	later := readingEvent(plan.ID, "2025-01-03", models.PeriodEvening, models.ProgressActionMark, at("20:00"))
+	record(t, repos, user.ID, later)
+	all, err := repos.ProgressEvents.GetByUser(ctx, user.ID)
+	if err != nil || len(all) != 9 || all[0].ID != unmark.ID || all[8].ID != later.ID || !all[8].Date.Equal(day("2025-01-03")) {
+		t.Errorf("GetByUser = %d events, %v; want the 8 events of 2025-01-02 followed by the one of 2025-01-03", len(all), err)
+	}
+}
+
+func noteIDs(notes []*models.Note) []int {
+	ids := make([]int, 0, len(notes))
+	for _, note := range notes {
+		ids = append(ids, note.ID)
+	}
+	return ids
+}
+
+func testNotes(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	other := createUser(t, repos, "bia@example.com")
+	plans := map[int]*models.ReadingPlan{2: {DayOfYear: 2}, 3: {DayOfYear: 3}}
+	for _, plan := range plans {
+		if err := repos.ReadingPlans.Create(ctx, plan); err != nil {
+			t.Fatalf("create plan: %v", err)
+		}
+	}
+	saveQuestions(t, repos, &models.CatechismQuestion{QuestionNumber: 1, QuestionText: "P1", AnswerText: "R1"})
+	question, _ := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 1)
+
+	john := &models.Note{
+		UserID:        user.ID,
+		Date:          day("2025-01-02"),
+		ReadingPlanID: plans[2].ID,
+		Period:        models.PeriodMorning,
+		Reference:     &models.BibleReference{Text: "Jo 3:16-18", Book: "Jo", Chapter: 3, Verse: 16, EndChapter: 3, EndVerse: 18},
+		Body:          "A **graça** de Deus basta.",
+		Tags:          []string{"graça", "oração"},
+	}
+	genesis := &models.Note{
+		UserID:        user.ID,
+		Date:          day("2025-01-03"),
+		ReadingPlanID: plans[3].ID,
+		Period:        models.PeriodEvening,
+		Reference:     &models.BibleReference{Text: "Gn 9-10", Book: "Gn", Chapter: 9, EndChapter: 10},
+		QuestionID:    question.ID,
+		Body:          "Deus fez uma aliança com Noé.",
+		Tags:          []string{"fé"},
+	}
+	others := &models.Note{
+		UserID:        other.ID,
+		Date:          day("2025-01-02"),
+		ReadingPlanID: plans[2].ID,
+		Period:        models.PeriodMorning,
+		Reference:     &models.BibleReference{Text: "Jo 3", Book: "Jo", Chapter: 3, EndChapter: 3},
+		Body:          "Só pela graça.",
+		Tags:          []string{"graça"},
+	}
+	for _, note := range []*models.Note{john, genesis, others} {
+		if err := repos.Notes.Create(ctx, note); err != nil || note.ID == 0 || note.CreatedAt.IsZero() {
+			t.Fatalf("Create = %+v, %v", note, err)
+		}
+	}
+
+	found, err := repos.Notes.GetByID(ctx, user.ID, john.ID)
+	if err != nil || found == nil {
+		t.Fatalf("GetByID = %v, %v", found, err)
+	}
+	if !found.Date.Equal(john.Date) || found.Period != john.Period || found.Body != john.Body || found.QuestionID != 0 ||
+		found.Reference == nil || *found.Reference != *john.Reference || !equalStrings(found.Tags, john.Tags) {
+		t.Fatalf("GetByID = %+v, want %+v", found, john)
+	}
+	if found, err := repos.Notes.GetByID(ctx, user.ID, genesis.ID); err != nil || found.QuestionID != question.ID || found.QuestionNumber != 1 || found.Reference.Verse != 0 {
+		t.Fatalf("GetByID of a note on a question = %+v, %v", found, err)
+	}
+	if found, err := repos.Notes.GetByID(ctx, other.ID, john.ID); err != nil || found != nil {
+		t.Fatalf("GetByID of another user's note = %v, %v", found, err)
+	}
+
+	date := day("2025-01-02")
+	lists := []struct {
+		name   string
+		filter models.NoteFilter
+		want   []int
+	}{
+		{"all", models.NoteFilter{}, []int{genesis.ID, john.ID}},
+		{"date", models.NoteFilter{Date: &date}, []int{john.ID}},
+		{"book", models.NoteFilter{Book: "Jo"}, []int{john.ID}},
+		{"chapter", models.NoteFilter{Book: "Gn", Chapter: 10}, []int{genesis.ID}},
+		{"chapter outside the passage", models.NoteFilter{Book: "Gn", Chapter: 11}, []int{}},
+		{"tag", models.NoteFilter{Tag: "fé"}, []int{genesis.ID}},
+	}
+	for _, list := range lists {
+		notes, total, err := repos.Notes.List(ctx, user.ID, list.filter, 10, 0)
+		if err != nil || notes == nil || total != len(list.want) || !equalInts(noteIDs(notes), list.want) {
+			t.Fatalf("List by %s = %v (%d), %v; want %v", list.name, noteIDs(notes), total, err, list.want)
+		}
+	}
+	if notes, total, err := repos.Notes.List(ctx, user.ID, models.NoteFilter{}, 1, 1); err != nil || total != 2 || !equalInts(noteIDs(notes), []int{john.ID}) {
+		t.Fatalf("second page = %v (%d), %v", noteIDs(notes), total, err)
+	}
+	notes, err := repos.Notes.GetAll(ctx, user.ID)
+	if err != nil || !equalInts(noteIDs(notes), []int{genesis.ID, john.ID}) || notes[0].QuestionNumber != 1 || !equalStrings(notes[1].Tags, john.Tags) {
+		t.Fatalf("GetAll = %v, %v", noteIDs(notes), err)
+	}
+	if notes, err := repos.Notes.GetAll(ctx, createUser(t, repos, "carla@example.com").ID); err != nil || notes == nil || len(notes) != 0 {
+		t.Fatalf("GetAll of a user without notes = %v, %v; want an empty list", notes, err)
+	}
+
+	results, err := repos.Notes.Search(ctx, user.ID, "aliança", 10)
+	if err != nil || len(results) != 1 || results[0].Note.ID != genesis.ID || results[0].Snippet == "" || !equalStrings(results[0].Note.Tags, []string{"fé"}) {
+		t.Fatalf("Search(aliança) = %v, %v", results, err)
+	}
+
+	// Editing replaces the body, tags and reference, and the search follows it
+	john.Body = "Misericórdia e paz."
+	john.Tags = []string{"paz"}
+	john.Reference = nil
+	if updated, err := repos.Notes.Update(ctx, john); err != nil || !updated || john.UpdatedAt.IsZero() {
+		t.Fatalf("Update = %v, %v", updated, err)
+	}
+	found, err = repos.Notes.GetByID(ctx, user.ID, john.ID)
+	if err != nil || found.Body != john.Body || found.Reference != nil || !equalStrings(found.Tags, []string{"paz"}) || found.CreatedAt.IsZero() {
+		t.Fatalf("note after Update = %+v, %v", found, err)
+	}
+	if results, err := repos.Notes.Search(ctx, user.ID, "graça", 10); err != nil || results == nil || len(results) != 0 {
+		t.Fatalf("Search(graça) after the edit = %v, %v; want an empty list", results, err)
+	}
+	if results, err := repos.Notes.Search(ctx, user.ID, "misericórdia", 10); err != nil || len(results) != 1 {
+		t.Fatalf("Search(misericórdia) = %v, %v", results, err)
+	}
+	others.Body = "Editada por outro"
+	others.UserID = user.ID
+	if updated, err := repos.Notes.Update(ctx, others); err != nil || updated {
+		t.Fatalf("Update of another user's note = %v, %v", updated, err)
+	}
+
+	// Removing a question keeps the notes about it, still linked to it
+	if err := repos.Catechism.ApplyImport(ctx, models.CatechismShorter, nil, []int{1}, nil); err != nil {
+		t.Fatalf("ApplyImport: %v", err)
+	}
+	if found, err := repos.Notes.GetByID(ctx, user.ID, genesis.ID); err != nil || found == nil || found.QuestionID == 0 || found.QuestionNumber != 1 {
+		t.Fatalf("note after removing its question = %+v, %v", found, err)
+	}
+
+	if deleted, err := repos.Notes.Delete(ctx, other.ID, genesis.ID); err != nil || deleted {
+		t.Fatalf("Delete of another user's note = %v, %v", deleted, err)
+	}
+	if deleted, err := repos.Notes.Delete(ctx, user.ID, genesis.ID); err != nil || !deleted {
+		t.Fatalf("Delete = %v, %v", deleted, err)
+	}
+	if found, err := repos.Notes.GetByID(ctx, user.ID, genesis.ID); err != nil || found != nil {
+		t.Fatalf("GetByID after Delete = %v, %v", found, err)
+	}
+}
+
+func testSync(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	other := createUser(t, repos, "bia@example.com")
+	plan := &models.ReadingPlan{DayOfYear: 2}
+	if err := repos.ReadingPlans.Create(ctx, plan); err != nil {
+		t.Fatalf("create plan: %v", err)
+	}
+	saveQuestions(t, repos, &models.CatechismQuestion{QuestionNumber: 1, QuestionText: "P1", AnswerText: "R1"})
+	question, _ := repos.Catechism.GetByQuestionNumber(ctx, models.CatechismShorter, 1)
+
+	changes, err := repos.Sync.GetChanges(ctx, user.ID, -1)
+	if err != nil || changes.Cursor != 0 || len(changes.Readings) != 0 || len(changes.Catechism) != 0 {
+		t.Fatalf("GetChanges without progress = %+v, %v", changes, err)
+	}
+
+	at := func(value string) time.Time {
+		completedAt, err := time.Parse(time.RFC3339, "2025-01-02T"+value+":00Z")
+		if err != nil {
+			panic(err)
+		}
+		return completedAt
+	}
+	reading := func(period, completedAt string) *models.ProgressEvent {
+		return readingEvent(plan.ID, "2025-01-02", period, models.ProgressActionMark, at(completedAt))
+	}
+	catechism := func(completedAt string) *models.ProgressEvent {
+		return catechismEvent(question.ID, "2025-01-02", models.CatechismStepReview, models.ProgressActionMark, at(completedAt))
+	}
+
+	// The earliest completion of each period wins, within a batch too
+	batch := []*models.ProgressEvent{reading(models.PeriodMorning, "07:00"), reading(models.PeriodEvening, "21:00"), catechism("07:10"), reading(models.PeriodMorning, "07:30")}
+	record(t, repos, user.ID, batch...)
+	if got := applied(batch); !equalBools(got, []bool{true, true, true, false}) {
+		t.Fatalf("Applied = %v", got)
+	}
+
+	progress, err := repos.UserProgress.GetByUserAndDate(ctx, user.ID, day("2025-01-02"))
+	if err != nil || progress == nil || !progress.MorningCompleted || !progress.EveningCompleted {
+		t.Fatalf("GetByUserAndDate after sync = %+v, %v", progress, err)
+	}
+	if !progress.MorningCompletedAt.Equal(at("07:00")) || !progress.EveningCompletedAt.Equal(at("21:00")) || !progress.CompletedAt.Equal(at("21:00")) {
+		t.Fatalf("completion times = %v, %v, %v", progress.MorningCompletedAt, progress.EveningCompletedAt, progress.CompletedAt)
+	}
+
+	first, err := repos.Sync.GetChanges(ctx, user.ID, -1)
+	if err != nil || first.Cursor == 0 || len(first.Readings) != 1 || len(first.Catechism) != 1 {
+		t.Fatalf("GetChanges = %+v, %v", first, err)
+	}
+	if !first.Catechism[0].CompletedAt.Equal(at("07:10")) {
+		t.Fatalf("catechism completed at %v", first.Catechism[0].CompletedAt)
+	}
+
+	// Later completions are ignored and earlier ones move the time back
+	batch = []*models.ProgressEvent{reading(models.PeriodMorning, "08:00"), reading(models.PeriodMorning, "06:30"), catechism("07:10")}
+	record(t, repos, user.ID, batch...)
+	if got := applied(batch); !equalBools(got, []bool{false, true, false}) {
+		t.Fatalf("Applied = %v", got)
+	}
+
+	second, err := repos.Sync.GetChanges(ctx, user.ID, first.Cursor)
+	if err != nil || second.Cursor <= first.Cursor || len(second.Readings) != 1 || len(second.Catechism) != 0 {
+		t.Fatalf("GetChanges after %d = %+v, %v", first.Cursor, second, err)
+	}
+	if day := second.Readings[0]; !day.MorningCompletedAt.Equal(at("06:30")) || !day.CompletedAt.Equal(at("21:00")) {
+		t.Fatalf("completion times = %v, %v", day.MorningCompletedAt, day.CompletedAt)
+	}
+
+	// An unmark is a change too, and the day is no longer completed
+	record(t, repos, user.ID, readingEvent(plan.ID, "2025-01-02", models.PeriodEvening, models.ProgressActionUnmark, at("22:00")))
+	third, err := repos.Sync.GetChanges(ctx, user.ID, second.Cursor)
+	if err != nil || len(third.Readings) != 1 || third.Readings[0].EveningCompleted || third.Readings[0].CompletedAt != nil {
+		t.Fatalf("GetChanges after an unmark = %+v, %v", third, err)
+	}
+
+	// Each user has their own changes
+	changes, err = repos.Sync.GetChanges(ctx, other.ID, -1)
+	if err != nil || len(changes.Readings) != 0 || len(changes.Catechism) != 0 {
+		t.Fatalf("GetChanges of another user = %+v, %v", changes, err)
+	}
+}
+
+func testIdempotency(t *testing.T, repos *repository.Repositories) {
+	user := createUser(t, repos, "ana@example.com")
+	other := createUser(t, repos, "bia@example.com")
+	now := time.Date(2025, time.January, 2, 12, 0, 0, 0, time.UTC)
+
+	reserve := func(userID int, key, fingerprint string, at time.Time) bool {
+		t.Helper()
+		reserved, err := repos.Idempotency.Reserve(ctx, &models.IdempotencyRecord{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			CreatedAt:   at,
+			ExpiresAt:   at.Add(time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("Reserve %s: %v", key, err)
+		}
+		return reserved
+	}
+
+	if !reserve(user.ID, "k1", "f1", now) {
+		t.Fatal("Reserve of a new key failed")
+	}
+	if reserve(user.ID, "k1", "f2", now.Add(time.Second)) {
+		t.Error("Reserve of a running key succeeded")
+	}
+	if !reserve(other.ID, "k1", "f1", now) {
+		t.Error("Reserve of another user's key failed")
+	}
+
+	running, err := repos.Idempotency.Get(ctx, user.ID, "k1")
+	if err != nil || running == nil || running.Fingerprint != "f1" || running.Status != 0 {
+		t.Fatalf("Get of a running key = %+v, %v", running, err)
+	}
+	if missing, err := repos.Idempotency.Get(ctx, user.ID, "k2"); err != nil || missing != nil {
+		t.Errorf("Get of a missing key = %+v, %v", missing, err)
+	}
+
+	err = repos.Idempotency.Complete(ctx, &models.IdempotencyRecord{
+		UserID:      user.ID,
+		Key:         "k1",
+		Fingerprint: "f1",
+		Status:      201,
+		ContentType: "application/json",
+		Body:        []byte(`{"id":1}`),
+		ExpiresAt:   now.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	done, err := repos.Idempotency.Get(ctx, user.ID, "k1")
+	if err != nil || done == nil || done.Status != 201 || done.ContentType != "application/json" || string(done.Body) != `{"id":1}` {
+		t.Fatalf("Get of a completed key = %+v, %v", done, err)
+	}
+	if !done.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("completed key expires at %v, want %v", done.ExpiresAt, now.Add(time.Hour))
+	}
+
+	// Completed responses are kept, running ones are released
+	if err := repos.Idempotency.Release(ctx, user.ID, "k1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if kept, _ := repos.Idempotency.Get(ctx, user.ID, "k1"); kept == nil {
+		t.Error("Release deleted a completed key")
+	}
+	if err := repos.Idempotency.Release(ctx, other.ID, "k1"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if released, _ := repos.Idempotency.Get(ctx, other.ID, "k1"); released != nil {
+		t.Errorf("Release kept a running key: %+v", released)
+	}
+
+	// An expired key can be reserved again, by any request
+	if reserve(user.ID, "k1", "f2", now.Add(30*time.Minute)) {
+		t.Error("Reserve of a completed key succeeded before it expired")
+	}
+	if !reserve(user.ID, "k1", "f2", now.Add(time.Hour)) {
+		t.Fatal("Reserve of an expired key failed")
+	}
+	if replaced, _ := repos.Idempotency.Get(ctx, user.ID, "k1"); replaced == nil || replaced.Fingerprint != "f2" || replaced.Status != 0 || len(replaced.Body) != 0 {
+		t.Errorf("expired key was not replaced: %+v", replaced)
+	}
+
+	reserve(other.ID, "k2", "f1", now)
+	deleted, err := repos.Idempotency.DeleteExpired(ctx, now.Add(2*time.Minute))
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteExpired = %d, %v, want 1", deleted, err)
+	}
+	if expired, _ := repos.Idempotency.Get(ctx, other.ID, "k2"); expired != nil {
+		t.Errorf("DeleteExpired kept %+v", expired)
+	}
+	if kept, _ := repos.Idempotency.Get(ctx, user.ID, "k1"); kept == nil {
+		t.Error("DeleteExpired deleted a key that hasn't expired")
+	}
+}
+
+func testHouseholds(t *testing.T, repos *repository.Repositories) {
+	ana := createUser(t, repos, "ana@example.com")
+	bia := createUser(t, repos, "bia@example.com")
+	caio := createUser(t, repos, "caio@example.com")
+
+	memberIDs := func(userID int) []int {
+		t.Helper()
+		ids, err := repos.Households.GetMemberIDs(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetMemberIDs: %v", err)
+		}
+		return ids
+	}
+
+	if household, err := repos.Households.GetByUser(ctx, ana.ID); err != nil || household != nil {
+		t.Fatalf("GetByUser without a household = %+v, %v", household, err)
+	}
+	if ids := memberIDs(ana.ID); !equalInts(ids, []int{ana.ID}) {
+		t.Errorf("GetMemberIDs without a household = %v, want only the user", ids)
+	}
+
+	created, err := repos.Households.Create(ctx, ana.ID, "CODE1")
+	if err != nil || created == nil || created.ID == 0 || created.InviteCode != "CODE1" {
+		t.Fatalf("Create = %+v, %v", created, err)
+	}
+	if len(created.Members) != 1 || created.Members[0].UserID != ana.ID || created.Members[0].Email != "ana@example.com" {
+		t.Errorf("members of a new household = %+v", created.Members)
+	}
+	if again, err := repos.Households.Create(ctx, ana.ID, "CODE2"); err != nil || again != nil {
+		t.Errorf("Create for a member of another household = %+v, %v", again, err)
+	}
+	if unknown, err := repos.Households.Join(ctx, bia.ID, "CODE2"); err != nil || unknown != nil {
+		t.Errorf("Join with an unknown code = %+v, %v", unknown, err)
+	}
+
+	joined, err := repos.Households.Join(ctx, bia.ID, "CODE1")
+	if err != nil || joined == nil || joined.ID != created.ID {
+		t.Fatalf("Join = %+v, %v", joined, err)
+	}
+	if len(joined.Members) != 2 || joined.Members[0].UserID != ana.ID || joined.Members[1].UserID != bia.ID {
+		t.Errorf("members after Join = %+v", joined.Members)
+	}
+	if twice, err := repos.Households.Join(ctx, bia.ID, "CODE1"); err != nil || twice != nil {
+		t.Errorf("Join by a member = %+v, %v", twice, err)
+	}
+
+	other, err := repos.Households.Create(ctx, caio.ID, "CODE3")
+	if err != nil || other == nil {
+		t.Fatalf("Create = %+v, %v", other, err)
+	}
+	if moved, err := repos.Households.Join(ctx, caio.ID, "CODE1"); err != nil || moved != nil {
+		t.Errorf("Join by a member of another household = %+v, %v", moved, err)
+	}
+
+	want := []int{ana.ID, bia.ID}
+	sort.Ints(want)
+	if ids := memberIDs(bia.ID); !equalInts(ids, want) {
+		t.Errorf("GetMemberIDs = %v, want %v", ids, want)
+	}
+	if ids := memberIDs(caio.ID); !equalInts(ids, []int{caio.ID}) {
+		t.Errorf("GetMemberIDs of another household = %v, want %v", ids, []int{caio.ID})
+	}
+
+	// The household is kept until its last member leaves
+	if left, err := repos.Households.Leave(ctx, ana.ID); err != nil || !left {
+		t.Fatalf("Leave = %v, %v", left, err)
+	}
+	if left, err := repos.Households.Leave(ctx, ana.ID); err != nil || left {
+		t.Errorf("Leave without a household = %v, %v", left, err)
+	}
+	if ids := memberIDs(bia.ID); !equalInts(ids, []int{bia.ID}) {
+		t.Errorf("GetMemberIDs after the other member left = %v", ids)
+	}
+	if household, err := repos.Households.GetByUser(ctx, bia.ID); err != nil || household == nil || len(household.Members) != 1 {
+		t.Errorf("GetByUser after the other member left = %+v, %v", household, err)
+	}
+	if _, err := repos.Households.Leave(ctx, bia.ID); err != nil {
+		t.Fatalf("Leave: %v", err)
+	}
+	if gone, err := repos.Households.Join(ctx, ana.ID, "CODE1"); err != nil || gone != nil {
+		t.Errorf("Join of a household everybody left = %+v, %v", gone, err)
+	}
+}
+
+func equalBools(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
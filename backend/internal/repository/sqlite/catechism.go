@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type catechismRepository struct {
+	repository.CatechismRepository
+	db *repository.DB
+}
+
+func NewCatechismRepository(db *repository.DB) repository.CatechismRepository {
+	return &catechismRepository{
+		CatechismRepository: repository.NewCatechismRepository(db),
+		db:                  db,
+	}
+}
+
+// Create inserts or updates a question without an author. See Save.
+func (r *catechismRepository) Create(ctx context.Context, question *models.CatechismQuestion) error {
+	return r.Save(ctx, question, nil)
+}
+
+func (r *catechismRepository) CreateBatch(ctx context.Context, questions []*models.CatechismQuestion) error {
+	for _, question := range questions {
+		if err := r.Create(ctx, question); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save inserts or updates a question by its catechism and number, recording a
+// new revision whenever the text changes
+func (r *catechismRepository) Save(ctx context.Context, question *models.CatechismQuestion, authorID *int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveQuestionTx(ctx, tx, question, authorID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// saveQuestionTx is the SQLite version of the PostgreSQL one. SQLite has no
+// row locks: the single connection of the pool already serializes writers.
+func saveQuestionTx(ctx context.Context, tx repository.DBTX, question *models.CatechismQuestion, authorID *int) error {
+	if question.Catechism == "" {
+		question.Catechism = models.CatechismShorter
+	}
+
+	var questionText, answerText string
+	var retired bool
+	err := tx.QueryRowContext(ctx, `SELECT id, question_text, answer_text, current_revision, retired_at IS NOT NULL
+	                    FROM westminster_catechism WHERE catechism = $1 AND question_number = $2`,
+		question.Catechism,
+		question.QuestionNumber,
+	).Scan(&question.ID, &questionText, &answerText, &question.Revision, &retired)
+
+	switch {
+	case err == sql.ErrNoRows:
+		question.Revision = 1
+		err = tx.QueryRowContext(ctx, `INSERT INTO westminster_catechism (question_number, question_text, answer_text, current_revision, catechism)
+		                   VALUES ($1, $2, $3, $4, $5)
+		                   RETURNING id`,
+			question.QuestionNumber,
+			question.QuestionText,
+			question.AnswerText,
+			question.Revision,
+			question.Catechism,
+		).Scan(&question.ID)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	case questionText == question.QuestionText && answerText == question.AnswerText:
+		if !retired {
+			return nil
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE westminster_catechism SET retired_at = NULL WHERE id = $1`, question.ID)
+		return err
+	default:
+		question.Revision++
+		_, err = tx.ExecContext(ctx, `UPDATE westminster_catechism
+		                  SET question_text = $1, answer_text = $2, current_revision = $3, retired_at = NULL
+		                  WHERE id = $4`,
+			question.QuestionText,
+			question.AnswerText,
+			question.Revision,
+			question.ID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO catechism_revisions (question_id, revision, question_number, question_text, answer_text, author_id, created_at)
+	                  VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		question.ID,
+		question.Revision,
+		question.QuestionNumber,
+		question.QuestionText,
+		question.AnswerText,
+		authorID,
+		time.Now(),
+	)
+	return err
+}
+
+// ApplyImport saves the given questions into a catechism and retires its
+// questions with the given numbers in a single transaction
+func (r *catechismRepository) ApplyImport(ctx context.Context, catechism string, upserts []*models.CatechismQuestion, removeNumbers []int, authorID *int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, question := range upserts {
+		question.Catechism = catechism
+		if err := saveQuestionTx(ctx, tx, question, authorID); err != nil {
+			return err
+		}
+	}
+
+	retiredAt := time.Now()
+	for _, number := range removeNumbers {
+		_, err := tx.ExecContext(ctx, `UPDATE westminster_catechism SET retired_at = $1
+		                  WHERE catechism = $2 AND question_number = $3 AND retired_at IS NULL`,
+			retiredAt, catechism, number)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// searchWord matches the words of a search, ignoring FTS5 operators and punctuation
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchQuery turns free text into an FTS5 query matching every word as a
+// prefix, which makes up for the lack of stemming ("homem" finds "homens")
+func searchQuery(text string) string {
+	words := searchWord.FindAllString(text, -1)
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// Search finds questions whose question or answer text contains every word of
+// the query, ignoring accents. Results are ranked with BM25, the question text
+// weighing more than the answer, and matches are highlighted in <mark> tags.
+// An empty catechism searches all of them.
+func (r *catechismRepository) Search(ctx context.Context, text string, catechism string, limit int) ([]*models.CatechismSearchResult, error) {
+	results := []*models.CatechismSearchResult{}
+
+	match := searchQuery(text)
+	if match == "" {
+		return results, nil
+	}
+
+	query := `SELECT w.id, w.question_number, w.question_text, w.answer_text, w.current_revision, w.catechism,
+	                 -bm25(westminster_catechism_search, 2.0, 1.0) AS rank,
+	                 highlight(westminster_catechism_search, 0, '<mark>', '</mark>'),
+	                 snippet(westminster_catechism_search, 1, '<mark>', '</mark>', '...', 35)
+	          FROM westminster_catechism_search
+	          JOIN westminster_catechism w ON w.id = westminster_catechism_search.rowid
+	          WHERE westminster_catechism_search MATCH $1 AND ($2 = '' OR w.catechism = $2) AND w.retired_at IS NULL
+	          ORDER BY rank DESC, w.question_number
+	          LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, query, match, catechism, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result := &models.CatechismSearchResult{Question: &models.CatechismQuestion{}}
+		err := rows.Scan(
+			&result.Question.ID,
+			&result.Question.QuestionNumber,
+			&result.Question.QuestionText,
+			&result.Question.AnswerText,
+			&result.Question.Revision,
+			&result.Question.Catechism,
+			&result.Rank,
+			&result.QuestionSnippet,
+			&result.AnswerSnippet,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type catechismProgressRepository struct {
+	repository.CatechismProgressRepository
+	db *repository.DB
+}
+
+func NewCatechismProgressRepository(db *repository.DB) repository.CatechismProgressRepository {
+	return &catechismProgressRepository{
+		CatechismProgressRepository: repository.NewCatechismProgressRepository(db),
+		db:                          db,
+	}
+}
+
+// GetQuestionStatuses summarizes the user's completed days for every question
+// they have marked, keyed by question ID. MAX(completed_at) comes back as text,
+// which sorts like the time it holds since every row is written the same way.
+func (r *catechismProgressRepository) GetQuestionStatuses(ctx context.Context, userID int) (map[int]*models.CatechismQuestionStatus, error) {
+	query := `SELECT question_id, COUNT(DISTINCT date), MAX(completed_at)
+	          FROM catechism_progress
+	          WHERE user_id = $1 AND completed = TRUE
+	          GROUP BY question_id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	statuses := map[int]*models.CatechismQuestionStatus{}
+	for rows.Next() {
+		var questionID int
+		var lastCompletedAt sql.NullString
+		status := &models.CatechismQuestionStatus{}
+
+		if err := rows.Scan(&questionID, &status.TimesCompleted, &lastCompletedAt); err != nil {
+			return nil, err
+		}
+
+		if lastCompletedAt.Valid {
+			completedAt, err := time.Parse(timeFormat, lastCompletedAt.String)
+			if err != nil {
+				return nil, err
+			}
+			status.LastCompletedAt = &completedAt
+		}
+
+		statuses[questionID] = status
+	}
+
+	return statuses, rows.Err()
+}
@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"biblia-am-pm/internal/models"
+	"biblia-am-pm/internal/repository"
+	"context"
+)
+
+type noteRepository struct {
+	repository.NoteRepository
+	db *repository.DB
+}
+
+func NewNoteRepository(db *repository.DB) repository.NoteRepository {
+	return &noteRepository{
+		NoteRepository: repository.NewNoteRepository(db),
+		db:             db,
+	}
+}
+
+// Search finds the notes whose body contains every word of the query as a
+// prefix, ignoring accents, ranked with BM25 and with the matches highlighted
+// in <mark> tags
+func (r *noteRepository) Search(ctx context.Context, userID int, text string, limit int) ([]*models.NoteSearchResult, error) {
+	match := searchQuery(text)
+	if match == "" {
+		return []*models.NoteSearchResult{}, nil
+	}
+
+	query := `SELECT ` + repository.NoteColumns + `,
+	                 -bm25(notes_search) AS rank,
+	                 snippet(notes_search, 0, '<mark>', '</mark>', '...', 35)
+	          FROM notes_search
+	          JOIN notes n ON n.id = notes_search.rowid
+	          LEFT JOIN westminster_catechism q ON q.id = n.question_id
+	          WHERE notes_search MATCH $1 AND n.user_id = $2
+	          ORDER BY rank DESC, n.date DESC, n.id DESC
+	          LIMIT $3`
+
+	return repository.QueryNoteSearch(ctx, r.db, query, match, userID, limit)
+}
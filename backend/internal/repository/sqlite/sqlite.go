@@ -0,0 +1,37 @@
+// Package sqlite implements the repositories on SQLite, for self-hosting
+// without a PostgreSQL server. The database must be opened with
+// database.OpenSQLite and migrated with the SQLite migrations.
+//
+// Most queries of the PostgreSQL repositories also run on SQLite, so those
+// repositories are reused as they are. Only what depends on PostgreSQL
+// features (row locks, full-text search, typed aggregates) is rewritten here.
+package sqlite
+
+import (
+	"biblia-am-pm/internal/repository"
+	"database/sql"
+)
+
+// timeFormat is how the driver stores times when opened with _time_format=sqlite.
+// Values read back through an aggregate lose their column type and come as text.
+const timeFormat = "2006-01-02 15:04:05.999999999-07:00"
+
+// New returns the SQLite repositories backed by db
+func New(sqlDB *sql.DB) *repository.Repositories {
+	db := repository.NewDB(sqlDB, "sqlite")
+	return &repository.Repositories{
+		Users:             repository.NewUserRepository(db),
+		ReadingPlans:      repository.NewReadingPlanRepository(db),
+		UserProgress:      repository.NewUserProgressRepository(db),
+		Catechism:         NewCatechismRepository(db),
+		CatechismProgress: NewCatechismProgressRepository(db),
+		CatechismQuizzes:  repository.NewCatechismQuizRepository(db),
+		CatechismSections: repository.NewCatechismSectionRepository(db),
+		Confession:        repository.NewConfessionRepository(db),
+		ProgressEvents:    repository.NewProgressEventRepository(db),
+		Notes:             NewNoteRepository(db),
+		Sync:              repository.NewSyncRepository(db),
+		Idempotency:       repository.NewIdempotencyRepository(db),
+		Households:        repository.NewHouseholdRepository(db),
+	}
+}
@@ -0,0 +1,64 @@
+package sqlite_test
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/database"
+	"biblia-am-pm/internal/migrate"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/repository/repositorytest"
+	"biblia-am-pm/internal/repository/sqlite"
+	"biblia-am-pm/migrations"
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) *repository.Repositories {
+		db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "biblia.db"))
+		if err != nil {
+			t.Fatalf("open database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		migrator, err := migrate.New(db, config.DriverSQLite, migrations.SQLite)
+		if err != nil {
+			t.Fatalf("load migrations: %v", err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+
+		return sqlite.New(db)
+	})
+}
+
+// The down migrations must revert every up migration, leaving only schema_migrations
+func TestMigrationsDown(t *testing.T) {
+	db, err := database.OpenSQLite(filepath.Join(t.TempDir(), "biblia.db"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, config.DriverSQLite, migrations.SQLite)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	if _, err := migrator.Down(ctx, len(applied)); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+
+	var tables int
+	err = db.QueryRow(`SELECT COUNT(*) FROM sqlite_master
+	                   WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')`).Scan(&tables)
+	if err != nil || tables != 0 {
+		t.Fatalf("%d tables left after reverting every migration, %v", tables, err)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"biblia-am-pm/internal/models"
+	"context"
+)
+
+type syncRepository struct {
+	db *DB
+}
+
+func NewSyncRepository(db *DB) SyncRepository {
+	return &syncRepository{db: db}
+}
+
+// GetChanges reads the user's sequence number first and only returns rows up to
+// it. Rows are written in the same transaction that takes their number, so
+// every row up to a committed number is visible, even without a snapshot
+// shared by the queries.
+func (r *syncRepository) GetChanges(ctx context.Context, userID int, after int64) (*models.SyncChanges, error) {
+	changes := &models.SyncChanges{}
+	err := r.db.QueryRowContext(ctx, `SELECT sync_seq FROM users WHERE id = $1`, userID).Scan(&changes.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.Readings, err = queryUserProgress(ctx, r.db, `SELECT `+userProgressColumns+`
+	          FROM user_progress WHERE user_id = $1 AND change_seq > $2 AND change_seq <= $3
+	          ORDER BY change_seq`, userID, after, changes.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	changes.Catechism, err = queryCatechismProgress(ctx, r.db, `SELECT `+catechismProgressColumns+`
+	          FROM catechism_progress WHERE user_id = $1 AND change_seq > $2 AND change_seq <= $3
+	          ORDER BY change_seq`, userID, after, changes.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
@@ -1,83 +1,92 @@
 package repository
 
 import (
-	"biblia-am-pm/internal/database"
 	"biblia-am-pm/internal/models"
+	"context"
 	"database/sql"
 	"time"
 )
 
-type UserProgressRepository struct{}
+type userProgressRepository struct {
+	db *DB
+}
+
+func NewUserProgressRepository(db *DB) UserProgressRepository {
+	return &userProgressRepository{db: db}
+}
+
+const userProgressColumns = `id, user_id, reading_plan_id, date, morning_completed, evening_completed,
+	          morning_completed_at, evening_completed_at, completed_at, change_seq`
 
-func NewUserProgressRepository() *UserProgressRepository {
-	return &UserProgressRepository{}
+func (r *userProgressRepository) GetByUserAndDate(ctx context.Context, userID int, date time.Time) (*models.UserProgress, error) {
+	return getUserProgressTx(ctx, r.db, userID, date)
 }
 
-func (r *UserProgressRepository) GetByUserAndDate(userID int, date time.Time) (*models.UserProgress, error) {
-	query := `SELECT id, user_id, reading_plan_id, date, morning_completed, evening_completed, completed_at 
+func getUserProgressTx(ctx context.Context, tx DBTX, userID int, date time.Time) (*models.UserProgress, error) {
+	query := `SELECT ` + userProgressColumns + `
 	          FROM user_progress WHERE user_id = $1 AND date = $2`
 	
-	progress := &models.UserProgress{}
-	var completedAt sql.NullTime
-	
-	err := database.DB.QueryRow(query, userID, date.Format("2006-01-02")).Scan(
-		&progress.ID,
-		&progress.UserID,
-		&progress.ReadingPlanID,
-		&progress.Date,
-		&progress.MorningCompleted,
-		&progress.EveningCompleted,
-		&completedAt,
-	)
-	
+	progress, err := scanUserProgress(tx.QueryRowContext(ctx, query, userID, date.Format("2006-01-02")))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	
-	if err != nil {
-		return nil, err
-	}
-	
-	if completedAt.Valid {
-		progress.CompletedAt = &completedAt.Time
-	}
-	
-	return progress, nil
+	return progress, err
 }
 
-func (r *UserProgressRepository) CreateOrUpdate(progress *models.UserProgress) error {
-	query := `INSERT INTO user_progress (user_id, reading_plan_id, date, morning_completed, evening_completed, completed_at)
-	          VALUES ($1, $2, $3, $4, $5, $6)
+// saveUserProgressTx upserts the progress with a change sequence number taken
+// in tx from nextChangeSeqTx
+func saveUserProgressTx(ctx context.Context, tx DBTX, progress *models.UserProgress, seq int64) error {
+	query := `INSERT INTO user_progress (user_id, reading_plan_id, date, morning_completed, evening_completed,
+	                                     morning_completed_at, evening_completed_at, completed_at, change_seq)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	          ON CONFLICT (user_id, reading_plan_id, date)
 	          DO UPDATE SET 
 	            morning_completed = EXCLUDED.morning_completed,
 	            evening_completed = EXCLUDED.evening_completed,
-	            completed_at = EXCLUDED.completed_at
+	            morning_completed_at = EXCLUDED.morning_completed_at,
+	            evening_completed_at = EXCLUDED.evening_completed_at,
+	            completed_at = EXCLUDED.completed_at,
+	            change_seq = EXCLUDED.change_seq
 	          RETURNING id`
 	
-	now := time.Now()
-	var completedAt *time.Time
-	if progress.MorningCompleted && progress.EveningCompleted {
-		completedAt = &now
-	}
-	
-	err := database.DB.QueryRow(query,
+	err := tx.QueryRowContext(ctx, query,
 		progress.UserID,
 		progress.ReadingPlanID,
 		progress.Date.Format("2006-01-02"),
 		progress.MorningCompleted,
 		progress.EveningCompleted,
-		completedAt,
+		progress.MorningCompletedAt,
+		progress.EveningCompletedAt,
+		progress.CompletedAt,
+		seq,
 	).Scan(&progress.ID)
-	
-	return err
+	if err != nil {
+		return err
+	}
+
+	progress.ChangeSeq = seq
+	return nil
 }
 
-func (r *UserProgressRepository) GetUserProgress(userID int) ([]*models.UserProgress, error) {
-	query := `SELECT id, user_id, reading_plan_id, date, morning_completed, evening_completed, completed_at 
+// nextChangeSeqTx takes the next change sequence number of a user. It locks the
+// user's row until tx ends, so the changes of a user commit in the order of
+// their numbers and a read in tx isn't overwritten by a concurrent change.
+func nextChangeSeqTx(ctx context.Context, tx DBTX, userID int) (int64, error) {
+	var seq int64
+	err := tx.QueryRowContext(ctx, `UPDATE users SET sync_seq = sync_seq + 1 WHERE id = $1 RETURNING sync_seq`, userID).Scan(&seq)
+	return seq, err
+}
+
+func (r *userProgressRepository) GetUserProgress(ctx context.Context, userID int) ([]*models.UserProgress, error) {
+	query := `SELECT ` + userProgressColumns + `
 	          FROM user_progress WHERE user_id = $1 ORDER BY date DESC`
 	
-	rows, err := database.DB.Query(query, userID)
+	return queryUserProgress(ctx, r.db, query, userID)
+}
+
+func queryUserProgress(ctx context.Context, tx DBTX, query string, args ...interface{}) ([]*models.UserProgress, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -85,29 +94,57 @@ func (r *UserProgressRepository) GetUserProgress(userID int) ([]*models.UserProg
 	
 	var progresses []*models.UserProgress
 	for rows.Next() {
-		progress := &models.UserProgress{}
-		var completedAt sql.NullTime
-		
-		err := rows.Scan(
-			&progress.ID,
-			&progress.UserID,
-			&progress.ReadingPlanID,
-			&progress.Date,
-			&progress.MorningCompleted,
-			&progress.EveningCompleted,
-			&completedAt,
-		)
+		progress, err := scanUserProgress(rows)
 		if err != nil {
 			return nil, err
 		}
 		
-		if completedAt.Valid {
-			progress.CompletedAt = &completedAt.Time
-		}
-		
 		progresses = append(progresses, progress)
 	}
 	
 	return progresses, rows.Err()
 }
 
+func scanUserProgress(row rowScanner) (*models.UserProgress, error) {
+	progress := &models.UserProgress{}
+	var morningCompletedAt, eveningCompletedAt, completedAt sql.NullTime
+
+	err := row.Scan(
+		&progress.ID,
+		&progress.UserID,
+		&progress.ReadingPlanID,
+		&progress.Date,
+		&progress.MorningCompleted,
+		&progress.EveningCompleted,
+		&morningCompletedAt,
+		&eveningCompletedAt,
+		&completedAt,
+		&progress.ChangeSeq,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.MorningCompletedAt = nullTime(morningCompletedAt)
+	progress.EveningCompletedAt = nullTime(eveningCompletedAt)
+	progress.CompletedAt = nullTime(completedAt)
+	return progress, nil
+}
+
+// nullTime returns the time of t, or nil when it's NULL
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func (r *userProgressRepository) CountCompletedOnDate(ctx context.Context, date time.Time) (int, int, error) {
+	query := `SELECT COALESCE(SUM(CASE WHEN morning_completed THEN 1 ELSE 0 END), 0),
+	                 COALESCE(SUM(CASE WHEN evening_completed THEN 1 ELSE 0 END), 0)
+	          FROM user_progress WHERE date = $1`
+
+	var morning, evening int
+	err := r.db.QueryRowContext(ctx, query, date.Format("2006-01-02")).Scan(&morning, &evening)
+	return morning, evening, err
+}
@@ -1,25 +1,31 @@
 package repository
 
 import (
-	"biblia-am-pm/internal/database"
 	"biblia-am-pm/internal/models"
+	"context"
 	"database/sql"
 	"time"
 )
 
-type UserRepository struct{}
+type userRepository struct {
+	db *DB
+}
 
-func NewUserRepository() *UserRepository {
-	return &UserRepository{}
+func NewUserRepository(db *DB) UserRepository {
+	return &userRepository{db: db}
 }
 
-func (r *UserRepository) CreateUser(email, hashedPassword string) (*models.User, error) {
-	query := `INSERT INTO users (email, password, created_at) VALUES ($1, $2, $3) RETURNING id, email, created_at`
+func (r *userRepository) CreateUser(ctx context.Context, email, hashedPassword string) (*models.User, error) {
+	query := `INSERT INTO users (email, password, created_at) VALUES ($1, $2, $3) RETURNING id, email, role, catechism_mode, catechism, locale, created_at`
 	
 	user := &models.User{}
-	err := database.DB.QueryRow(query, email, hashedPassword, time.Now()).Scan(
+	err := r.db.QueryRowContext(ctx, query, email, hashedPassword, time.Now()).Scan(
 		&user.ID,
 		&user.Email,
+		&user.Role,
+		&user.CatechismMode,
+		&user.Catechism,
+		&user.Locale,
 		&user.CreatedAt,
 	)
 	
@@ -30,14 +36,18 @@ func (r *UserRepository) CreateUser(email, hashedPassword string) (*models.User,
 	return user, nil
 }
 
-func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
-	query := `SELECT id, email, password, created_at FROM users WHERE email = $1`
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT id, email, password, role, catechism_mode, catechism, locale, created_at FROM users WHERE email = $1`
 	
 	user := &models.User{}
-	err := database.DB.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Email,
 		&user.Password,
+		&user.Role,
+		&user.CatechismMode,
+		&user.Catechism,
+		&user.Locale,
 		&user.CreatedAt,
 	)
 	
@@ -52,13 +62,17 @@ func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
-	query := `SELECT id, email, created_at FROM users WHERE id = $1`
+func (r *userRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	query := `SELECT id, email, role, catechism_mode, catechism, locale, created_at FROM users WHERE id = $1`
 	
 	user := &models.User{}
-	err := database.DB.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Email,
+		&user.Role,
+		&user.CatechismMode,
+		&user.Catechism,
+		&user.Locale,
 		&user.CreatedAt,
 	)
 	
@@ -73,3 +87,38 @@ func (r *UserRepository) GetUserByID(id int) (*models.User, error) {
 	return user, nil
 }
 
+// SetRole changes the role of the user with the given email. It returns false
+// when no such user exists.
+func (r *userRepository) SetRole(ctx context.Context, email, role string) (bool, error) {
+	query := `UPDATE users SET role = $1 WHERE email = $2`
+
+	res, err := r.db.ExecContext(ctx, query, role, email)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *userRepository) SetCatechismMode(ctx context.Context, userID int, mode string) error {
+	query := `UPDATE users SET catechism_mode = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, mode, userID)
+	return err
+}
+
+func (r *userRepository) SetLocale(ctx context.Context, userID int, locale string) error {
+	query := `UPDATE users SET locale = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, locale, userID)
+	return err
+}
+
+// SetCatechism changes the catechism the user follows
+func (r *userRepository) SetCatechism(ctx context.Context, userID int, catechism string) error {
+	query := `UPDATE users SET catechism = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, catechism, userID)
+	return err
+}
@@ -0,0 +1,45 @@
+// Package schedule holds the calendar rules shared by the API and the CLI
+// commands: which week a date belongs to and which catechism question is
+// studied that week.
+package schedule
+
+import "time"
+
+// WeekStart returns the Sunday of the week containing date
+func WeekStart(date time.Time) time.Time {
+	weekday := int(date.Weekday())
+	// Sunday is 0, so we need to handle it
+	if weekday == 0 {
+		// It's already Sunday
+		return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	}
+	// Calculate days to subtract to get to Sunday
+	daysToSubtract := weekday
+	return date.AddDate(0, 0, -daysToSubtract)
+}
+
+// QuestionNumber calculates which question should be active in the week of now
+// Based on the number of weeks since a reference date (first Sunday of 2024)
+func QuestionNumber(now time.Time, totalQuestions int) int {
+	// Reference date: First Sunday of 2024 (January 7, 2024)
+	referenceDate := time.Date(2024, 1, 7, 0, 0, 0, 0, now.Location())
+
+	// Get the Sunday of the current week
+	currentWeekStart := WeekStart(now)
+
+	// Calculate weeks since reference
+	weeksSinceReference := int(currentWeekStart.Sub(referenceDate).Hours() / 24 / 7)
+
+	// Calculate question number (1-totalQuestions, cycling)
+	questionNumber := (weeksSinceReference % totalQuestions) + 1
+
+	// Ensure it's between 1 and totalQuestions
+	if questionNumber < 1 {
+		questionNumber = totalQuestions
+	}
+	if questionNumber > totalQuestions {
+		questionNumber = 1
+	}
+
+	return questionNumber
+}
@@ -0,0 +1,170 @@
+// Package server runs the HTTP API together with its background workers and
+// shuts both down cleanly on SIGINT or SIGTERM: readiness is turned off, the
+// server stops accepting connections and drains the in-flight requests, and
+// then the workers are cancelled, all within the configured deadline.
+package server
+
+import (
+	"biblia-am-pm/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Worker is a background task. It must return when ctx is cancelled.
+type Worker func(ctx context.Context) error
+
+type Server struct {
+	cfg        config.Server
+	httpServer *http.Server
+	workers    []namedWorker
+	ready      atomic.Bool
+}
+
+type namedWorker struct {
+	name string
+	run  Worker
+}
+
+// New returns a server for handler configured with the timeouts in cfg
+func New(cfg config.Server, handler http.Handler) *Server {
+	return &Server{
+		cfg: cfg,
+		httpServer: &http.Server{
+			Addr:              ":" + cfg.Port,
+			Handler:           handler,
+			ReadTimeout:       time.Duration(cfg.ReadTimeout),
+			ReadHeaderTimeout: time.Duration(cfg.ReadHeaderTimeout),
+			WriteTimeout:      time.Duration(cfg.WriteTimeout),
+			IdleTimeout:       time.Duration(cfg.IdleTimeout),
+		},
+	}
+}
+
+// AddWorker registers a background task started by Run. Workers are
+// cancelled after the HTTP server has drained, so requests that depend on
+// them can still finish.
+func (s *Server) AddWorker(name string, worker Worker) {
+	s.workers = append(s.workers, namedWorker{name: name, run: worker})
+}
+
+// OnShutdown registers f to run when shutdown begins, to end the long-lived
+// requests, like event streams, that would otherwise hold the drain until
+// its deadline
+func (s *Server) OnShutdown(f func()) {
+	s.httpServer.RegisterOnShutdown(f)
+}
+
+// Ready reports whether the server is accepting traffic. It is false before
+// Run starts listening and once shutdown begins.
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// Run serves until ctx is cancelled, a termination signal arrives or a worker
+// fails, and then shuts down gracefully
+func (s *Server) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+
+	// Workers outlive ctx: they are only cancelled once the requests are drained
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+
+	errs := make(chan error, len(s.workers)+1)
+	var workers sync.WaitGroup
+	for _, worker := range s.workers {
+		workers.Add(1)
+		go func(worker namedWorker) {
+			defer workers.Done()
+			if err := worker.run(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+				errs <- fmt.Errorf("worker %s: %w", worker.name, err)
+			}
+		}(worker)
+	}
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("server: %w", err)
+		}
+	}()
+	s.ready.Store(true)
+	slog.Info("server starting", "port", s.cfg.Port)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutdown signal received, draining connections")
+	case runErr = <-errs:
+		slog.Error("shutting down after a failure", "error", runErr)
+	}
+
+	s.ready.Store(false)
+	if delay := time.Duration(s.cfg.ShutdownDelay); delay > 0 && runErr == nil {
+		time.Sleep(delay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(s.cfg.ShutdownTimeout))
+	defer cancel()
+
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("connections not drained before the deadline", "error", err)
+		s.httpServer.Close()
+	}
+
+	cancelWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		slog.Warn("background workers did not stop before the deadline")
+	}
+
+	slog.Info("server stopped")
+	return runErr
+}
+
+// HTTPWorker serves handler on addr as a background worker, for listeners
+// that are kept apart from the API, like the metrics one
+func HTTPWorker(addr string, handler http.Handler) Worker {
+	return func(ctx context.Context) error {
+		httpServer := &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- httpServer.ListenAndServe()
+		}()
+
+		select {
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			// Workers are stopped after the API has drained, so there is no
+			// need to wait for the scrapes in flight
+			httpServer.Close()
+			return ctx.Err()
+		}
+	}
+}
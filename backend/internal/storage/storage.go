@@ -0,0 +1,56 @@
+// Package storage opens the configured database backend together with its
+// repositories and migrations, so callers don't depend on which one is in use.
+package storage
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/database"
+	"biblia-am-pm/internal/migrate"
+	"biblia-am-pm/internal/repository"
+	"biblia-am-pm/internal/repository/sqlite"
+	"biblia-am-pm/migrations"
+	"database/sql"
+	"io/fs"
+)
+
+// Storage is an open database with the repositories of its backend
+type Storage struct {
+	DB     *sql.DB
+	Driver string
+	Repos  *repository.Repositories
+}
+
+// Open connects to the database described by cfg
+func Open(cfg config.Database) (*Storage, error) {
+	db, err := database.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Storage{DB: db, Driver: cfg.Driver, Repos: Repositories(db, cfg.Driver)}, nil
+}
+
+// Repositories returns the repositories of a backend backed by db
+func Repositories(db *sql.DB, driver string) *repository.Repositories {
+	if driver == config.DriverSQLite {
+		return sqlite.New(db)
+	}
+	return repository.New(db)
+}
+
+// Migrations returns the migrations written for a backend
+func Migrations(driver string) fs.FS {
+	if driver == config.DriverSQLite {
+		return migrations.SQLite
+	}
+	return migrations.Files
+}
+
+// Migrator returns a migrator loaded with the migrations of the backend
+func (s *Storage) Migrator() (*migrate.Migrator, error) {
+	return migrate.New(s.DB, s.Driver, Migrations(s.Driver))
+}
+
+func (s *Storage) Close() error {
+	return s.DB.Close()
+}
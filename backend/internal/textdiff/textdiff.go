@@ -0,0 +1,74 @@
+// Package textdiff computes word-level differences between two texts.
+package textdiff
+
+import "strings"
+
+// Operation types
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Op is a run of words that are equal, inserted or deleted
+type Op struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Words diffs two texts word by word using the longest common subsequence.
+// Consecutive words with the same operation are merged into a single Op.
+func Words(from, to string) []Op {
+	a := strings.Fields(from)
+	b := strings.Fields(to)
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := []Op{}
+	add := func(opType, word string) {
+		if n := len(ops); n > 0 && ops[n-1].Type == opType {
+			ops[n-1].Text += " " + word
+			return
+		}
+		ops = append(ops, Op{Type: opType, Text: word})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(OpEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(OpDelete, a[i])
+			i++
+		default:
+			add(OpInsert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(OpDelete, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(OpInsert, b[j])
+	}
+
+	return ops
+}
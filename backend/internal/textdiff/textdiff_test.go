@@ -0,0 +1,48 @@
+package textdiff_test
+
+import (
+	"biblia-am-pm/internal/textdiff"
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	equal := func(text string) textdiff.Op { return textdiff.Op{Type: textdiff.OpEqual, Text: text} }
+	insert := func(text string) textdiff.Op { return textdiff.Op{Type: textdiff.OpInsert, Text: text} }
+	del := func(text string) textdiff.Op { return textdiff.Op{Type: textdiff.OpDelete, Text: text} }
+
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want []textdiff.Op
+	}{
+		{"both empty", "", "", []textdiff.Op{}},
+		{"only whitespace", "  \n", "\t", []textdiff.Op{}},
+		{"from empty", "", "Glorificar a Deus", []textdiff.Op{insert("Glorificar a Deus")}},
+		{"to empty", "Glorificar a Deus", "", []textdiff.Op{del("Glorificar a Deus")}},
+		{"identical", "O fim principal do homem", "O fim principal do homem", []textdiff.Op{equal("O fim principal do homem")}},
+		{"whitespace changes", "Deus  é\nespírito", " Deus é espírito ", []textdiff.Op{equal("Deus é espírito")}},
+		{"insertion at the start", "Deus é espírito", "Que Deus é espírito", []textdiff.Op{insert("Que"), equal("Deus é espírito")}},
+		{"insertion in the middle", "Glorificar a Deus para sempre", "Glorificar a Deus e gozá-lo para sempre",
+			[]textdiff.Op{equal("Glorificar a Deus"), insert("e gozá-lo"), equal("para sempre")}},
+		{"insertion at the end", "Glorificar a Deus", "Glorificar a Deus e gozá-lo para sempre",
+			[]textdiff.Op{equal("Glorificar a Deus"), insert("e gozá-lo para sempre")}},
+		{"deletion at the start", "Que Deus é espírito", "Deus é espírito", []textdiff.Op{del("Que"), equal("Deus é espírito")}},
+		{"deletions in the middle and at the end", "Deus é um espírito infinito", "Deus é espírito",
+			[]textdiff.Op{equal("Deus é"), del("um"), equal("espírito"), del("infinito")}},
+		{"punctuation changed", "Deus é espírito.", "Deus é espírito!", []textdiff.Op{equal("Deus é"), del("espírito."), insert("espírito!")}},
+		{"punctuation added", "a Deus e gozá-lo", "a Deus, e gozá-lo", []textdiff.Op{equal("a"), del("Deus"), insert("Deus,"), equal("e gozá-lo")}},
+		{"case changed", "deus é espírito", "Deus é espírito", []textdiff.Op{del("deus"), insert("Deus"), equal("é espírito")}},
+		{"words replaced", "A palavra de Deus é a única regra", "A Escritura é a única regra",
+			[]textdiff.Op{equal("A"), del("palavra de Deus"), insert("Escritura"), equal("é a única regra")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := textdiff.Words(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Words(%q, %q) = %+v, want %+v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
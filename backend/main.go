@@ -1,171 +1,190 @@
 package main
 
 import (
+	"biblia-am-pm/internal/apierror"
+	"biblia-am-pm/internal/config"
 	"biblia-am-pm/internal/database"
 	"biblia-am-pm/internal/handlers"
+	"biblia-am-pm/internal/health"
+	"biblia-am-pm/internal/logging"
+	"biblia-am-pm/internal/metrics"
 	"biblia-am-pm/internal/middleware"
-	"log"
+	"biblia-am-pm/internal/migrate"
+	"biblia-am-pm/internal/pubsub"
+	"biblia-am-pm/internal/server"
+	"biblia-am-pm/internal/storage"
+	"biblia-am-pm/internal/tracing"
+	"context"
+	"log/slog"
+	"net/http"
 	"os"
-	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// Initialize database
-	if err := database.InitDB(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// "migrate" subcommand: manage the schema without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
 	}
-	defer database.CloseDB()
+
+	// Load configuration: defaults, config file, environment and flags
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("failed to load configuration", err)
+	}
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fatal("failed to print configuration", err)
+		}
+		return
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid configuration", err)
+	}
+
+	// Structured logging; the standard log package goes through it too
+	logger, err := logging.New(os.Stderr, cfg.Log)
+	if err != nil {
+		fatal("failed to configure logging", err)
+	}
+	slog.SetDefault(logger)
+
+	// Tracing of the routes and of the queries they run
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.Env)
+	if err != nil {
+		fatal("failed to configure tracing", err)
+	}
+
+	// Initialize database (PostgreSQL or SQLite)
+	store, err := storage.Open(cfg.Database)
+	if err != nil {
+		fatal("failed to initialize database", err)
+	}
+	defer store.Close()
 
 	// Run migrations
-	if err := runMigrations(); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	migrator, err := store.Migrator()
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+	if err := runMigrations(migrator); err != nil {
+		fatal("failed to run migrations", err)
 	}
 
-	// Initialize handlers
-	authHandler := handlers.NewAuthHandler()
-	readingsHandler := handlers.NewReadingsHandler()
-	catechismHandler := handlers.NewCatechismHandler()
+	// Initialize repositories
+	repos := store.Repos
 
-	// Setup Gin router
-	r := gin.Default()
+	// Setup Gin router. Requests are logged by RequestLogger instead of
+	// gin.Logger, with the request ID and user ID.
+	if cfg.Env == config.EnvProduction {
+		gin.SetMode(gin.ReleaseMode)
+	}
+	r := gin.New()
+	r.Use(middleware.RequestID(), tracing.Middleware(), middleware.RequestLogger(), gin.CustomRecovery(apierror.Recovery), metrics.Middleware())
+	srv := server.New(cfg.Server, r)
 
 	// CORS middleware
-	config := cors.DefaultConfig()
-	allowedOrigins := strings.TrimSpace(os.Getenv("CORS_ALLOWED_ORIGINS"))
-	switch {
-	case allowedOrigins == "":
-		// Defaults: dev hosts + produção
-		config.AllowOrigins = []string{
-			"http://localhost:3001",
-			"http://hiagoserver.local:3001",
-			"http://hiagoserver.local",
-			"https://bibliampm-api.klapowsko.com",
-			"https://bibliampm.klapowsko.com",
-		}
-	case allowedOrigins == "*":
+	corsConfig := cors.DefaultConfig()
+	if len(cfg.Server.CORSAllowedOrigins) == 1 && cfg.Server.CORSAllowedOrigins[0] == "*" {
 		// Permite tudo (sem credentials)
-		config.AllowAllOrigins = true
-	default:
-		origins := []string{}
-		for _, origin := range strings.Split(allowedOrigins, ",") {
-			trimmed := strings.TrimSpace(origin)
-			if trimmed != "" {
-				origins = append(origins, trimmed)
-			}
-		}
-		config.AllowOrigins = origins
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOrigins = cfg.Server.CORSAllowedOrigins
 	}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Content-Type", "Authorization", "X-Requested-With"}
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Content-Type", "Authorization", "X-Requested-With", middleware.RequestIDHeader, middleware.IdempotencyKeyHeader, handlers.ClientHeader}
+	corsConfig.ExposeHeaders = []string{middleware.RequestIDHeader, middleware.DeprecationHeader, middleware.SunsetHeader, middleware.LinkHeader, middleware.IdempotentReplayedHeader}
 	// Credenciais só quando não é wildcard
-	config.AllowCredentials = !config.AllowAllOrigins
-	r.Use(cors.New(config))
-
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.String(200, "OK")
-	})
-
-	// API routes
-	api := r.Group("/api")
-	{
-		// Public routes
-		api.POST("/auth/register", authHandler.Register)
-		api.POST("/auth/login", authHandler.Login)
-	}
-
-	// Protected routes - create separate group with auth middleware
-	protected := r.Group("/api")
-	protected.Use(middleware.AuthMiddleware())
-	{
-		protected.GET("/readings/today", readingsHandler.GetTodayReadings)
-		protected.POST("/readings/mark-completed", readingsHandler.MarkCompleted)
-		protected.GET("/progress", readingsHandler.GetProgress)
-		
-		// Catechism routes
-		protected.GET("/catechism/current", catechismHandler.GetCurrentQuestion)
-		protected.POST("/catechism/mark-completed", catechismHandler.MarkAsCompleted)
-		protected.GET("/catechism/progress", catechismHandler.GetProgress)
-		protected.POST("/catechism/populate", catechismHandler.PopulateCatechism)
-	}
-
-	port := os.Getenv("API_PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	corsConfig.AllowCredentials = !corsConfig.AllowAllOrigins
+	r.Use(cors.New(corsConfig))
+
+	// Probes: /healthz while the process is up, /readyz while it can serve traffic
+	checker := health.New(health.DefaultTimeout)
+	checker.Add("server", health.Serving(srv.Ready))
+	checker.Add("database", health.Database(store.DB))
+	checker.Add("migrations", health.Migrations(migrator))
+	checker.Add("reading_plans", health.Populated(repos.ReadingPlans.GetTotalCount, "cmd/populate"))
+	checker.Add("catechism", health.Populated(repos.Catechism.GetTotalCount, "cmd/populate-catechism"))
+	r.GET("/healthz", health.Liveness)
+	r.GET("/readyz", checker.Readiness)
+
+	// Metrics: on the API port behind a token, or on their own address
+	metrics.RegisterDB(store.DB)
+	metrics.RegisterDailyProgress(repos.UserProgress, repos.CatechismProgress, cfg.Location())
+	switch {
+	case cfg.Metrics.Token != "":
+		r.GET("/metrics", gin.WrapH(metrics.RequireToken(cfg.Metrics.Token, metrics.Handler())))
+	case cfg.Metrics.Addr != "":
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		srv.AddWorker("metrics", server.HTTPWorker(cfg.Metrics.Addr, mux))
+		slog.Info("metrics available", "url", "http://"+cfg.Metrics.Addr+"/metrics")
+	default:
+		slog.Warn("metrics disabled: set METRICS_ADDR or METRICS_TOKEN to enable them")
+	}
+
+	// Live progress updates: through the database when replicas share it,
+	// in process with SQLite, which only runs as a single instance
+	var broker pubsub.Broker = pubsub.NewLocal()
+	if cfg.Database.Driver == config.DriverPostgres {
+		postgresBroker := pubsub.NewPostgres(store.DB, database.PostgresDSN(cfg.Database))
+		srv.AddWorker("progress-notifications", postgresBroker.Listen)
+		broker = postgresBroker
+	}
+	srv.OnShutdown(broker.Close)
+
+	// API routes. Each version is mounted under its own prefix, so a v2 can be
+	// registered next to v1 without touching it. The unversioned /api prefix
+	// is an alias of v1 for clients released before it, answering with
+	// Deprecation and Sunset headers until it is removed.
+	handlers.RegisterV1(r.Group(handlers.V1Prefix), repos, broker, cfg)
+	handlers.RegisterV1(r.Group(legacyAPIPrefix, middleware.Deprecated(legacyAPIDeprecatedAt, legacyAPISunset, legacyAPIPrefix, handlers.V1Prefix)), repos, broker, cfg)
+	r.NoRoute(apierror.NoRoute)
+	srv.AddWorker("idempotency-keys", middleware.ExpireIdempotencyKeys(repos.Idempotency, idempotencyKeysCleanupInterval))
+
+	// Serve until SIGINT/SIGTERM, then drain requests before closing the database
+	if err := srv.Run(context.Background()); err != nil {
+		store.Close()
+		fatal("server error", err)
+	}
+
+	// Flush the spans still buffered
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("failed to flush traces", "error", err)
 	}
 }
 
-func runMigrations() error {
-	migrationSQL := `
-	-- Create users table
-	CREATE TABLE IF NOT EXISTS users (
-		id SERIAL PRIMARY KEY,
-		email VARCHAR(255) UNIQUE NOT NULL,
-		password VARCHAR(255) NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-
-	-- Create reading_plans table
-	CREATE TABLE IF NOT EXISTS reading_plans (
-		id SERIAL PRIMARY KEY,
-		day_of_year INTEGER NOT NULL UNIQUE,
-		old_testament_ref VARCHAR(255),
-		new_testament_ref VARCHAR(255),
-		psalms_ref VARCHAR(255),
-		proverbs_ref VARCHAR(255)
-	);
-
-	-- Create user_progress table
-	CREATE TABLE IF NOT EXISTS user_progress (
-		id SERIAL PRIMARY KEY,
-		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-		reading_plan_id INTEGER NOT NULL REFERENCES reading_plans(id) ON DELETE CASCADE,
-		date DATE NOT NULL,
-		morning_completed BOOLEAN DEFAULT FALSE,
-		evening_completed BOOLEAN DEFAULT FALSE,
-		completed_at TIMESTAMP,
-		UNIQUE(user_id, reading_plan_id, date)
-	);
-
-	-- Create indexes for better performance
-	CREATE INDEX IF NOT EXISTS idx_user_progress_user_id ON user_progress(user_id);
-	CREATE INDEX IF NOT EXISTS idx_user_progress_date ON user_progress(date);
-	CREATE INDEX IF NOT EXISTS idx_reading_plans_day_of_year ON reading_plans(day_of_year);
-
-	-- Create westminster_catechism table
-	CREATE TABLE IF NOT EXISTS westminster_catechism (
-		id SERIAL PRIMARY KEY,
-		question_number INTEGER NOT NULL UNIQUE,
-		question_text TEXT NOT NULL,
-		answer_text TEXT NOT NULL
-	);
-
-	-- Create catechism_progress table
-	CREATE TABLE IF NOT EXISTS catechism_progress (
-		id SERIAL PRIMARY KEY,
-		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-		question_id INTEGER NOT NULL REFERENCES westminster_catechism(id) ON DELETE CASCADE,
-		date DATE NOT NULL,
-		completed BOOLEAN DEFAULT FALSE,
-		completed_at TIMESTAMP,
-		UNIQUE(user_id, question_id, date)
-	);
-
-	-- Create indexes for catechism tables
-	CREATE INDEX IF NOT EXISTS idx_catechism_progress_user_id ON catechism_progress(user_id);
-	CREATE INDEX IF NOT EXISTS idx_catechism_progress_date ON catechism_progress(date);
-	CREATE INDEX IF NOT EXISTS idx_catechism_progress_question_id ON catechism_progress(question_id);
-	CREATE INDEX IF NOT EXISTS idx_westminster_catechism_question_number ON westminster_catechism(question_number);
-	`
-
-	_, err := database.DB.Exec(migrationSQL)
+// tracingShutdownTimeout bounds the export of the last spans on exit
+const tracingShutdownTimeout = 5 * time.Second
+
+// idempotencyKeysCleanupInterval is how often expired idempotency keys are deleted
+const idempotencyKeysCleanupInterval = time.Hour
+
+// legacyAPIPrefix serves v1 without a version in the path
+const legacyAPIPrefix = "/api"
+
+// When the legacy prefix was deprecated and when it will be removed
+var (
+	legacyAPIDeprecatedAt = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	legacyAPISunset       = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// fatal logs err and exits; deferred calls don't run
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
+}
+
+func runMigrations(migrator *migrate.Migrator) error {
+	applied, err := migrator.Up(context.Background())
+	for _, migration := range applied {
+		slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+	}
 	return err
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"biblia-am-pm/internal/config"
+	"biblia-am-pm/internal/migrate"
+	"biblia-am-pm/internal/storage"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const migrateUsage = `Usage: main migrate <command> [flags]
+
+Commands:
+  up                  Apply all pending migrations
+  down [-steps N]     Revert the last N applied migrations (default 1)
+  status              List migrations and when they were applied
+  create [-dir DIR] NAME
+                      Create empty up/down files for a new migration, in DIR
+                      and, for SQLite, in DIR/sqlite
+`
+
+// runMigrateCommand handles "main migrate up|down|status|create"
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+
+	command, args := args[0], args[1:]
+	flags := flag.NewFlagSet("migrate "+command, flag.ExitOnError)
+	steps := flags.Int("steps", 1, "Number of migrations to revert")
+	dir := flags.String("dir", "migrations", "Directory of the migration files")
+	flags.Parse(args)
+
+	// create only writes files, so it doesn't need the database
+	if command == "create" {
+		if flags.NArg() != 1 {
+			log.Fatalf("Usage: main migrate create [-dir DIR] NAME")
+		}
+		// Every migration needs a PostgreSQL and a SQLite version with the same
+		// number, even when one of the directories is behind the other
+		targets := []string{*dir, filepath.Join(*dir, "sqlite")}
+		version, err := migrate.NextVersion(targets...)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		for _, target := range targets {
+			if _, err := os.Stat(target); err != nil {
+				continue
+			}
+			upPath, downPath, err := migrate.Create(target, version, flags.Arg(0))
+			if err != nil {
+				log.Fatalf("Failed to create migration: %v", err)
+			}
+			log.Printf("Created %s", upPath)
+			log.Printf("Created %s", downPath)
+		}
+		return
+	}
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	store, err := storage.Open(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer store.Close()
+
+	migrator, err := store.Migrator()
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			log.Printf("Applied migration %03d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+
+	case "down":
+		if *steps < 1 {
+			log.Fatalf("-steps must be at least 1")
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, migration := range reverted {
+			log.Printf("Reverted migration %03d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Failed to revert migrations: %v", err)
+		}
+		if len(reverted) == 0 {
+			log.Println("No applied migrations to revert")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to get migrations status: %v", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d_%-40s %s\n", status.Migration.Version, status.Migration.Name, appliedAt)
+		}
+
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS catechism_progress;
+DROP TABLE IF EXISTS westminster_catechism;
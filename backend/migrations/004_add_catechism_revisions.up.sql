@@ -0,0 +1,21 @@
+-- Track the current revision of each catechism question
+ALTER TABLE westminster_catechism ADD COLUMN IF NOT EXISTS current_revision INTEGER NOT NULL DEFAULT 1;
+
+-- Create catechism_revisions table
+CREATE TABLE IF NOT EXISTS catechism_revisions (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL REFERENCES westminster_catechism(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    question_number INTEGER NOT NULL,
+    question_text TEXT NOT NULL,
+    answer_text TEXT NOT NULL,
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(question_id, revision)
+);
+
+-- Record the existing text of questions created before revisions existed
+INSERT INTO catechism_revisions (question_id, revision, question_number, question_text, answer_text)
+SELECT w.id, w.current_revision, w.question_number, w.question_text, w.answer_text
+FROM westminster_catechism w
+WHERE NOT EXISTS (SELECT 1 FROM catechism_revisions r WHERE r.question_id = w.id);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_westminster_catechism_search;
+ALTER TABLE westminster_catechism DROP COLUMN IF EXISTS search_vector;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS portuguese_unaccent;
+-- The unaccent extension is kept, as it may be used outside this application
+ALTER TABLE users DROP COLUMN IF EXISTS catechism;
+
+-- Fails while both catechisms are stored
+ALTER TABLE westminster_catechism DROP CONSTRAINT IF EXISTS westminster_catechism_catechism_question_number_key;
+ALTER TABLE westminster_catechism ADD CONSTRAINT westminster_catechism_question_number_key UNIQUE (question_number);
+CREATE INDEX IF NOT EXISTS idx_westminster_catechism_question_number ON westminster_catechism(question_number);
+ALTER TABLE westminster_catechism DROP COLUMN IF EXISTS catechism;
@@ -0,0 +1,51 @@
+-- Identify which catechism each question belongs to. The Shorter Catechism
+-- has 107 questions, so any existing question past that is from the Larger.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'westminster_catechism' AND column_name = 'catechism'
+    ) THEN
+        ALTER TABLE westminster_catechism ADD COLUMN catechism VARCHAR(20) NOT NULL DEFAULT 'shorter';
+        UPDATE westminster_catechism SET catechism = 'larger' WHERE question_number > 107;
+    END IF;
+END
+$$;
+
+-- Number the questions of each catechism separately, so the Shorter and the
+-- Larger Catechism can be stored together
+ALTER TABLE westminster_catechism DROP CONSTRAINT IF EXISTS westminster_catechism_question_number_key;
+DROP INDEX IF EXISTS idx_westminster_catechism_question_number;
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'westminster_catechism_catechism_question_number_key'
+    ) THEN
+        ALTER TABLE westminster_catechism
+            ADD CONSTRAINT westminster_catechism_catechism_question_number_key UNIQUE (catechism, question_number);
+    END IF;
+END
+$$;
+
+-- Catechism the user follows in the weekly schedule
+ALTER TABLE users ADD COLUMN IF NOT EXISTS catechism VARCHAR(20) NOT NULL DEFAULT 'shorter';
+
+-- Full-text search: Portuguese stemming ignoring accents
+CREATE EXTENSION IF NOT EXISTS unaccent;
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'portuguese_unaccent') THEN
+        CREATE TEXT SEARCH CONFIGURATION portuguese_unaccent (COPY = portuguese);
+        ALTER TEXT SEARCH CONFIGURATION portuguese_unaccent
+            ALTER MAPPING FOR hword, hword_part, word WITH unaccent, portuguese_stem;
+    END IF;
+END
+$$;
+
+ALTER TABLE westminster_catechism ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('portuguese_unaccent', question_text), 'A') ||
+        setweight(to_tsvector('portuguese_unaccent', answer_text), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_westminster_catechism_search ON westminster_catechism USING GIN(search_vector);
@@ -0,0 +1,11 @@
+-- Create catechism_sections table
+CREATE TABLE IF NOT EXISTS catechism_sections (
+    id SERIAL PRIMARY KEY,
+    catechism VARCHAR(20) NOT NULL,
+    position INTEGER NOT NULL,
+    slug VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    start_question INTEGER NOT NULL,
+    end_question INTEGER NOT NULL,
+    UNIQUE(catechism, slug)
+);
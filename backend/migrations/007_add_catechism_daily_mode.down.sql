@@ -0,0 +1,11 @@
+-- Keep a single row per day before restoring the original unique constraint
+DELETE FROM catechism_progress p
+USING catechism_progress q
+WHERE p.user_id = q.user_id AND p.question_id = q.question_id AND p.date = q.date AND p.id > q.id;
+
+DROP INDEX IF EXISTS idx_catechism_progress_user_question_date_step;
+ALTER TABLE catechism_progress DROP COLUMN IF EXISTS step;
+ALTER TABLE catechism_progress
+    ADD CONSTRAINT catechism_progress_user_id_question_id_date_key UNIQUE (user_id, question_id, date);
+
+ALTER TABLE users DROP COLUMN IF EXISTS catechism_mode;
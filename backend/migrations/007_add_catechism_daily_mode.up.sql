@@ -0,0 +1,8 @@
+-- Add catechism schedule preference to users ("weekly" or "daily")
+ALTER TABLE users ADD COLUMN IF NOT EXISTS catechism_mode VARCHAR(20) NOT NULL DEFAULT 'weekly';
+
+-- Record which step of the schedule each catechism progress row completes
+ALTER TABLE catechism_progress ADD COLUMN IF NOT EXISTS step VARCHAR(20) NOT NULL DEFAULT 'review';
+ALTER TABLE catechism_progress DROP CONSTRAINT IF EXISTS catechism_progress_user_id_question_id_date_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_catechism_progress_user_question_date_step
+    ON catechism_progress(user_id, question_id, date, step);
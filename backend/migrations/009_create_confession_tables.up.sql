@@ -0,0 +1,38 @@
+-- Create Westminster Confession of Faith tables
+CREATE TABLE IF NOT EXISTS confession_chapters (
+    id SERIAL PRIMARY KEY,
+    chapter_number INTEGER UNIQUE NOT NULL,
+    title TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS confession_sections (
+    id SERIAL PRIMARY KEY,
+    chapter_id INTEGER NOT NULL REFERENCES confession_chapters(id) ON DELETE CASCADE,
+    section_number INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    UNIQUE(chapter_id, section_number)
+);
+
+CREATE TABLE IF NOT EXISTS confession_proofs (
+    id SERIAL PRIMARY KEY,
+    section_id INTEGER NOT NULL REFERENCES confession_sections(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    letter VARCHAR(5) NOT NULL DEFAULT '',
+    reference_text TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_confession_proofs_section_id ON confession_proofs(section_id);
+
+-- Links between catechism questions and Confession sections. They use numbers
+-- instead of foreign keys so either side can be re-imported independently.
+CREATE TABLE IF NOT EXISTS catechism_confession_links (
+    id SERIAL PRIMARY KEY,
+    catechism VARCHAR(20) NOT NULL,
+    question_number INTEGER NOT NULL,
+    chapter_number INTEGER NOT NULL,
+    section_number INTEGER NOT NULL,
+    UNIQUE(catechism, question_number, chapter_number, section_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_catechism_confession_links_chapter ON catechism_confession_links(catechism, chapter_number);
@@ -0,0 +1,2 @@
+-- Language of the API messages chosen by the user; empty follows Accept-Language
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT '';
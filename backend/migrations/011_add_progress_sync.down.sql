@@ -0,0 +1,9 @@
+ALTER TABLE user_progress DROP COLUMN IF EXISTS evening_completed_at;
+ALTER TABLE user_progress DROP COLUMN IF EXISTS morning_completed_at;
+
+DROP INDEX IF EXISTS idx_catechism_progress_user_change_seq;
+DROP INDEX IF EXISTS idx_user_progress_user_change_seq;
+ALTER TABLE catechism_progress DROP COLUMN IF EXISTS change_seq;
+ALTER TABLE user_progress DROP COLUMN IF EXISTS change_seq;
+
+ALTER TABLE users DROP COLUMN IF EXISTS sync_seq;
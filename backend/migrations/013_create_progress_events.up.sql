@@ -0,0 +1,33 @@
+-- Append-only log of every mark and unmark of the users' progress.
+-- user_progress and catechism_progress are its projection: a period or step
+-- is completed at its earliest mark after its last unmark.
+CREATE TABLE IF NOT EXISTS progress_events (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    date DATE NOT NULL,
+    reading_plan_id INTEGER REFERENCES reading_plans(id) ON DELETE CASCADE,
+    period VARCHAR(20),
+    question_id INTEGER REFERENCES westminster_catechism(id) ON DELETE CASCADE,
+    step VARCHAR(20),
+    action VARCHAR(10) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_progress_events_user_date ON progress_events(user_id, date);
+
+-- The completions recorded before the log become its first marks. Days
+-- completed before the per-period times only know when the day was.
+INSERT INTO progress_events (user_id, type, date, reading_plan_id, period, action, occurred_at, source)
+SELECT user_id, 'reading', date, reading_plan_id, 'morning', 'mark', COALESCE(morning_completed_at, completed_at, date::timestamp), 'migration'
+FROM user_progress WHERE morning_completed;
+
+INSERT INTO progress_events (user_id, type, date, reading_plan_id, period, action, occurred_at, source)
+SELECT user_id, 'reading', date, reading_plan_id, 'evening', 'mark', COALESCE(evening_completed_at, completed_at, date::timestamp), 'migration'
+FROM user_progress WHERE evening_completed;
+
+INSERT INTO progress_events (user_id, type, date, question_id, step, action, occurred_at, source)
+SELECT user_id, 'catechism', date, question_id, step, 'mark', COALESCE(completed_at, date::timestamp), 'migration'
+FROM catechism_progress WHERE completed;
@@ -0,0 +1,33 @@
+-- Reflections of the users on the days of the plan, in markdown. A note may
+-- be about a passage, stored parsed so notes can be listed by book and
+-- chapter, and about a catechism question.
+CREATE TABLE IF NOT EXISTS notes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    reading_plan_id INTEGER NOT NULL REFERENCES reading_plans(id),
+    period VARCHAR(20) NOT NULL,
+    reference VARCHAR(50),
+    book VARCHAR(10),
+    chapter INTEGER,
+    verse INTEGER,
+    end_chapter INTEGER,
+    end_verse INTEGER,
+    question_id INTEGER REFERENCES westminster_catechism(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    search_vector tsvector GENERATED ALWAYS AS (to_tsvector('portuguese_unaccent', body)) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_notes_user_date ON notes(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_notes_user_book ON notes(user_id, book, chapter);
+CREATE INDEX IF NOT EXISTS idx_notes_search ON notes USING GIN(search_vector);
+
+CREATE TABLE IF NOT EXISTS note_tags (
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    tag VARCHAR(40) NOT NULL,
+    PRIMARY KEY (note_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_tags_tag ON note_tags(tag);
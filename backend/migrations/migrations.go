@@ -0,0 +1,33 @@
+// Package migrations embeds the numbered SQL migrations of the database.
+// Each migration has an NNN_name.up.sql file and an optional NNN_name.down.sql.
+//
+// Migrations written before the runner existed are idempotent (IF NOT EXISTS),
+// so databases created by the old startup script are adopted without changes.
+//
+// The sqlite directory holds the same migrations written for SQLite. Every new
+// migration needs both versions, with the same number and name.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// Files holds the PostgreSQL migrations
+//
+//go:embed *.sql
+var Files embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// SQLite holds the SQLite migrations
+var SQLite fs.FS = mustSub(sqliteFiles, "sqlite")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
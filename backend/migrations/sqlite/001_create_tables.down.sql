@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_progress;
+DROP TABLE IF EXISTS reading_plans;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,29 @@
+-- Create westminster_catechism table. The id is an alias of the rowid, which
+-- the full-text index of 005 relies on. The unique question number is an
+-- index so 005 can key it by catechism without rebuilding the table.
+CREATE TABLE IF NOT EXISTS westminster_catechism (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    question_number INTEGER NOT NULL,
+    question_text TEXT NOT NULL,
+    answer_text TEXT NOT NULL
+);
+
+-- Create catechism_progress table. The unique key is an index rather than a
+-- table constraint so 007 can drop it without rebuilding the table.
+CREATE TABLE IF NOT EXISTS catechism_progress (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id INTEGER NOT NULL REFERENCES westminster_catechism(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    completed BOOLEAN DEFAULT FALSE,
+    completed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_catechism_progress_user_question_date
+    ON catechism_progress(user_id, question_id, date);
+
+-- Create indexes for catechism tables
+CREATE INDEX IF NOT EXISTS idx_catechism_progress_user_id ON catechism_progress(user_id);
+CREATE INDEX IF NOT EXISTS idx_catechism_progress_date ON catechism_progress(date);
+CREATE INDEX IF NOT EXISTS idx_catechism_progress_question_id ON catechism_progress(question_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_westminster_catechism_question_number ON westminster_catechism(question_number);
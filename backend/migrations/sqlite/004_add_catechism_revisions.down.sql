@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS catechism_revisions;
+ALTER TABLE westminster_catechism DROP COLUMN current_revision;
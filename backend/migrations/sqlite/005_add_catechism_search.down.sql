@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS westminster_catechism_search_update;
+DROP TRIGGER IF EXISTS westminster_catechism_search_delete;
+DROP TRIGGER IF EXISTS westminster_catechism_search_insert;
+DROP TABLE IF EXISTS westminster_catechism_search;
+ALTER TABLE users DROP COLUMN catechism;
+
+-- Fails while both catechisms are stored
+DROP INDEX IF EXISTS idx_westminster_catechism_catechism_question_number;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_westminster_catechism_question_number ON westminster_catechism(question_number);
+ALTER TABLE westminster_catechism DROP COLUMN catechism;
@@ -0,0 +1,43 @@
+-- Identify which catechism each question belongs to. The Shorter Catechism
+-- has 107 questions, so any existing question past that is from the Larger.
+ALTER TABLE westminster_catechism ADD COLUMN catechism VARCHAR(20) NOT NULL DEFAULT 'shorter';
+UPDATE westminster_catechism SET catechism = 'larger' WHERE question_number > 107;
+
+-- Number the questions of each catechism separately, so the Shorter and the
+-- Larger Catechism can be stored together
+DROP INDEX IF EXISTS idx_westminster_catechism_question_number;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_westminster_catechism_catechism_question_number
+    ON westminster_catechism(catechism, question_number);
+
+-- Catechism the user follows in the weekly schedule
+ALTER TABLE users ADD COLUMN catechism VARCHAR(20) NOT NULL DEFAULT 'shorter';
+
+-- Full-text search ignoring accents. SQLite has no Portuguese stemmer, so
+-- the repository searches by word prefix instead.
+CREATE VIRTUAL TABLE IF NOT EXISTS westminster_catechism_search USING fts5(
+    question_text,
+    answer_text,
+    content = 'westminster_catechism',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO westminster_catechism_search (westminster_catechism_search) VALUES ('rebuild');
+
+-- Keep the external content index in sync with the questions
+CREATE TRIGGER IF NOT EXISTS westminster_catechism_search_insert AFTER INSERT ON westminster_catechism BEGIN
+    INSERT INTO westminster_catechism_search (rowid, question_text, answer_text)
+    VALUES (new.id, new.question_text, new.answer_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS westminster_catechism_search_delete AFTER DELETE ON westminster_catechism BEGIN
+    INSERT INTO westminster_catechism_search (westminster_catechism_search, rowid, question_text, answer_text)
+    VALUES ('delete', old.id, old.question_text, old.answer_text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS westminster_catechism_search_update AFTER UPDATE OF question_text, answer_text ON westminster_catechism BEGIN
+    INSERT INTO westminster_catechism_search (westminster_catechism_search, rowid, question_text, answer_text)
+    VALUES ('delete', old.id, old.question_text, old.answer_text);
+    INSERT INTO westminster_catechism_search (rowid, question_text, answer_text)
+    VALUES (new.id, new.question_text, new.answer_text);
+END;
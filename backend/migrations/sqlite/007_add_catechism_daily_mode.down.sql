@@ -0,0 +1,14 @@
+-- Keep a single row per day before restoring the original unique index
+DELETE FROM catechism_progress
+WHERE EXISTS (
+    SELECT 1 FROM catechism_progress q
+    WHERE q.user_id = catechism_progress.user_id AND q.question_id = catechism_progress.question_id
+      AND q.date = catechism_progress.date AND q.id < catechism_progress.id
+);
+
+DROP INDEX IF EXISTS idx_catechism_progress_user_question_date_step;
+ALTER TABLE catechism_progress DROP COLUMN step;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_catechism_progress_user_question_date
+    ON catechism_progress(user_id, question_id, date);
+
+ALTER TABLE users DROP COLUMN catechism_mode;
@@ -0,0 +1,16 @@
+-- Create catechism_quizzes table. Exercises, answers and results are JSON.
+CREATE TABLE IF NOT EXISTS catechism_quizzes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    range_start INTEGER NOT NULL,
+    range_end INTEGER NOT NULL,
+    exercises TEXT NOT NULL,
+    answers TEXT,
+    results TEXT,
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    correct_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    submitted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_catechism_quizzes_user_id ON catechism_quizzes(user_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS catechism_confession_links;
+DROP TABLE IF EXISTS confession_proofs;
+DROP TABLE IF EXISTS confession_sections;
+DROP TABLE IF EXISTS confession_chapters;
@@ -0,0 +1,9 @@
+ALTER TABLE user_progress DROP COLUMN evening_completed_at;
+ALTER TABLE user_progress DROP COLUMN morning_completed_at;
+
+DROP INDEX IF EXISTS idx_catechism_progress_user_change_seq;
+DROP INDEX IF EXISTS idx_user_progress_user_change_seq;
+ALTER TABLE catechism_progress DROP COLUMN change_seq;
+ALTER TABLE user_progress DROP COLUMN change_seq;
+
+ALTER TABLE users DROP COLUMN sync_seq;
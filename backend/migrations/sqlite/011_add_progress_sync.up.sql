@@ -0,0 +1,17 @@
+-- Counter of the progress changes of each user. Every write of a progress row
+-- takes the next value, so sync clients can ask for what changed after the
+-- last value they saw.
+ALTER TABLE users ADD COLUMN sync_seq INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE user_progress ADD COLUMN change_seq INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE catechism_progress ADD COLUMN change_seq INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_user_progress_user_change_seq ON user_progress(user_id, change_seq);
+CREATE INDEX IF NOT EXISTS idx_catechism_progress_user_change_seq ON catechism_progress(user_id, change_seq);
+
+-- When each reading period was completed, so completions merged out of order
+-- keep the earliest time. Days completed before this only know when both were.
+ALTER TABLE user_progress ADD COLUMN morning_completed_at TIMESTAMP;
+ALTER TABLE user_progress ADD COLUMN evening_completed_at TIMESTAMP;
+UPDATE user_progress
+SET morning_completed_at = completed_at, evening_completed_at = completed_at
+WHERE completed_at IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS notes_search_update;
+DROP TRIGGER IF EXISTS notes_search_delete;
+DROP TRIGGER IF EXISTS notes_search_insert;
+DROP TABLE IF EXISTS notes_search;
+DROP TABLE IF EXISTS note_tags;
+DROP TABLE IF EXISTS notes;
@@ -0,0 +1,52 @@
+-- Reflections of the users on the days of the plan, in markdown. A note may
+-- be about a passage, stored parsed so notes can be listed by book and
+-- chapter, and about a catechism question.
+CREATE TABLE IF NOT EXISTS notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    reading_plan_id INTEGER NOT NULL REFERENCES reading_plans(id),
+    period VARCHAR(20) NOT NULL,
+    reference VARCHAR(50),
+    book VARCHAR(10),
+    chapter INTEGER,
+    verse INTEGER,
+    end_chapter INTEGER,
+    end_verse INTEGER,
+    question_id INTEGER REFERENCES westminster_catechism(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notes_user_date ON notes(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_notes_user_book ON notes(user_id, book, chapter);
+
+CREATE TABLE IF NOT EXISTS note_tags (
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    tag VARCHAR(40) NOT NULL,
+    PRIMARY KEY (note_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_note_tags_tag ON note_tags(tag);
+
+-- Full-text search ignoring accents, by word prefix as for the catechism
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_search USING fts5(
+    body,
+    content = 'notes',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS notes_search_insert AFTER INSERT ON notes BEGIN
+    INSERT INTO notes_search (rowid, body) VALUES (new.id, new.body);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_search_delete AFTER DELETE ON notes BEGIN
+    INSERT INTO notes_search (notes_search, rowid, body) VALUES ('delete', old.id, old.body);
+END;
+
+CREATE TRIGGER IF NOT EXISTS notes_search_update AFTER UPDATE OF body ON notes BEGIN
+    INSERT INTO notes_search (notes_search, rowid, body) VALUES ('delete', old.id, old.body);
+    INSERT INTO notes_search (rowid, body) VALUES (new.id, new.body);
+END;
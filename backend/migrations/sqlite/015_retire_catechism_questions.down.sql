@@ -0,0 +1,3 @@
+-- Without the column retired questions would come back, so delete them
+DELETE FROM westminster_catechism WHERE retired_at IS NOT NULL;
+ALTER TABLE westminster_catechism DROP COLUMN retired_at;
@@ -0,0 +1,3 @@
+-- Questions pruned by an import are retired instead of deleted, so their
+-- revisions, progress and notes are kept. Importing them again restores them.
+ALTER TABLE westminster_catechism ADD COLUMN retired_at TIMESTAMP;
@@ -0,0 +1,15 @@
+-- Accounts that follow each other's progress, like a family reading
+-- together. A user belongs to at most one household, joined with its invite
+-- code; a household is deleted when its last member leaves.
+CREATE TABLE IF NOT EXISTS households (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    invite_code VARCHAR(16) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS household_members (
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (household_id, user_id)
+);
@@ -4,7 +4,7 @@ import axios from 'axios';
 import AuthContext from '../context/AuthContext';
 import './Dashboard.css';
 
-const API_URL = process.env.REACT_APP_API_URL || '/api';
+const API_URL = process.env.REACT_APP_API_URL || '/api/v1';
 
 const Dashboard = () => {
   const [readings, setReadings] = useState(null);
@@ -77,18 +77,76 @@ const Dashboard = () => {
     fetchCatechism();
   }, [token, navigate, fetchTodayReadings, fetchCatechism]);
 
+  // Live updates from the other devices. EventSource can't send the token,
+  // so the stream is read with fetch; after a drop it reconnects and reloads.
+  useEffect(() => {
+    if (!token) {
+      return undefined;
+    }
+
+    const controller = new AbortController();
+    let retry;
+
+    const connect = async (reload) => {
+      try {
+        const response = await fetch(`${API_URL}/events`, {
+          headers: { Authorization: `Bearer ${token}` },
+          signal: controller.signal,
+        });
+        if (!response.ok) {
+          throw new Error(`status ${response.status}`);
+        }
+        if (reload) {
+          fetchTodayReadings();
+          fetchCatechism();
+        }
+
+        const reader = response.body.pipeThrough(new TextDecoderStream()).getReader();
+        let buffer = '';
+        for (;;) {
+          const { value, done } = await reader.read();
+          if (done) {
+            break;
+          }
+          buffer += value;
+          const messages = buffer.split('\n\n');
+          buffer = messages.pop();
+          messages.forEach((message) => {
+            const name = message.match(/^event: (.*)$/m)?.[1];
+            if (name === 'reading') {
+              fetchTodayReadings();
+            } else if (name === 'catechism') {
+              fetchCatechism();
+            }
+          });
+        }
+      } catch (err) {
+        if (controller.signal.aborted) {
+          return;
+        }
+      }
+      retry = setTimeout(() => connect(true), 5000);
+    };
+
+    connect(false);
+    return () => {
+      controller.abort();
+      clearTimeout(retry);
+    };
+  }, [token, fetchTodayReadings, fetchCatechism]);
+
+  // Marks the period, or undoes an accidental mark when it is already completed
   const markAsCompleted = async (period) => {
+    const completed = readings?.progress?.[`${period}_completed`];
     try {
       setMarking(true);
-      const response = await axios.post(
-        `${API_URL}/readings/mark-completed`,
-        { period },
-        {
-          headers: {
-            Authorization: `Bearer ${token}`,
-          },
-        }
-      );
+      const headers = { Authorization: `Bearer ${token}` };
+      const response = completed
+        ? await axios.delete(`${API_URL}/readings/completions`, {
+            params: { period },
+            headers,
+          })
+        : await axios.post(`${API_URL}/readings/mark-completed`, { period }, { headers });
 
       // Update local state
       setReadings((prev) => ({
@@ -96,29 +154,27 @@ const Dashboard = () => {
         progress: response.data,
       }));
     } catch (err) {
-      setError('Erro ao marcar leitura como concluída');
+      setError(completed ? 'Erro ao desfazer a leitura' : 'Erro ao marcar leitura como concluída');
     } finally {
       setMarking(false);
     }
   };
 
   const markCatechismAsCompleted = async () => {
+    const completed = isTodayCompleted();
     try {
       setMarkingCatechism(true);
-      await axios.post(
-        `${API_URL}/catechism/mark-completed`,
-        {},
-        {
-          headers: {
-            Authorization: `Bearer ${token}`,
-          },
-        }
-      );
+      const headers = { Authorization: `Bearer ${token}` };
+      if (completed) {
+        await axios.delete(`${API_URL}/catechism/completions`, { headers });
+      } else {
+        await axios.post(`${API_URL}/catechism/mark-completed`, {}, { headers });
+      }
 
       // Refresh catechism data
       await fetchCatechism();
     } catch (err) {
-      setCatechismError('Erro ao marcar catecismo como concluído');
+      setCatechismError(completed ? 'Erro ao desfazer o catecismo' : 'Erro ao marcar catecismo como concluído');
     } finally {
       setMarkingCatechism(false);
     }
@@ -229,7 +285,8 @@ const Dashboard = () => {
                   progress?.morning_completed ? 'btn-success' : 'btn-primary'
                 }`}
                 onClick={() => markAsCompleted('morning')}
-                disabled={marking || progress?.morning_completed}
+                disabled={marking}
+                title={progress?.morning_completed ? 'Clique para desfazer' : undefined}
               >
                 {progress?.morning_completed
                   ? '✓ Concluído'
@@ -248,7 +305,8 @@ const Dashboard = () => {
                   progress?.morning_completed ? 'btn-success' : 'btn-primary'
                 }`}
                 onClick={() => markAsCompleted('morning')}
-                disabled={marking || progress?.morning_completed}
+                disabled={marking}
+                title={progress?.morning_completed ? 'Clique para desfazer' : undefined}
               >
                 {progress?.morning_completed
                   ? '✓ Concluído'
@@ -267,7 +325,8 @@ const Dashboard = () => {
                   progress?.evening_completed ? 'btn-success' : 'btn-primary'
                 }`}
                 onClick={() => markAsCompleted('evening')}
-                disabled={marking || progress?.evening_completed}
+                disabled={marking}
+                title={progress?.evening_completed ? 'Clique para desfazer' : undefined}
               >
                 {progress?.evening_completed
                   ? '✓ Concluído'
@@ -286,7 +345,8 @@ const Dashboard = () => {
                   progress?.evening_completed ? 'btn-success' : 'btn-primary'
                 }`}
                 onClick={() => markAsCompleted('evening')}
-                disabled={marking || progress?.evening_completed}
+                disabled={marking}
+                title={progress?.evening_completed ? 'Clique para desfazer' : undefined}
               >
                 {progress?.evening_completed
                   ? '✓ Concluído'
@@ -355,7 +415,8 @@ const Dashboard = () => {
               <button
                 className={`btn ${isTodayCompleted() ? 'btn-success' : 'btn-primary'}`}
                 onClick={markCatechismAsCompleted}
-                disabled={markingCatechism || isTodayCompleted()}
+                disabled={markingCatechism}
+                title={isTodayCompleted() ? 'Clique para desfazer' : undefined}
               >
                 {isTodayCompleted()
                   ? '✓ Lido hoje'